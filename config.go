@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"music-info/config"
+)
+
+// runConfig выполняет команду config: print выводит итоговые значения настроек с их источниками.
+// Пароли и учетные данные в адресах скрываются.
+func runConfig(conf config.Config, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Использование: music-info [флаги] config print")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || flags.Arg(0) != "print" {
+		flags.Usage()
+		return errors.New("ожидается действие print")
+	}
+	return conf.Print(stdout)
+}
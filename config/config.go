@@ -1,42 +1,379 @@
 package config
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"music-info/models"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Port string
-	DSN  string
+	Port string `yaml:"port"`
+
+	// Таймауты HTTP-сервера, 0 отключает таймаут. Запись ограничивает и потоковую выгрузку песен.
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	// Время на завершение начатых запросов и фоновых задач после сигнала остановки.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+
+	// Уровень журнала запросов к базе данных: debug, info, warn или error.
+	LogLevel string `yaml:"logLevel"`
+
+	// Источники, которым разрешены запросы из браузера. Если список пуст, CORS отключен.
+	CORSOrigins []string `yaml:"corsOrigins"`
+
+	// Адрес внешнего сервиса информации о песнях. Если пуст, обогащение отключено.
+	InfoServiceURL     string        `yaml:"infoServiceUrl"`
+	InfoServiceTimeout time.Duration `yaml:"infoServiceTimeout"`
+
+	// Формат вывода даты выпуска: ru (DD.MM.YYYY) или iso (YYYY-MM-DD).
+	ReleaseDateFormat string `yaml:"releaseDateFormat"`
+
+	// Срок хранения удаленных песен в корзине. Если равен 0, корзина не очищается.
+	TrashRetention time.Duration `yaml:"trashRetention"`
+	// Период очистки корзины.
+	TrashPurgeInterval time.Duration `yaml:"trashPurgeInterval"`
+
+	Database Database `yaml:"database"`
+
+	// sources источник значения каждой настройки по ключу, см. Print.
+	sources map[string]string
+}
+
+// Database параметры подключения к базе данных и пула соединений.
+type Database struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	// Применять миграции схемы при запуске сервера. Если выключено, схема обновляется командой migrate.
+	AutoMigrate bool `yaml:"autoMigrate"`
+
+	// Параметры пула соединений. Нулевые значения оставляют значения database/sql по умолчанию.
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+}
+
+// Источники значений настроек в выводе Print.
+const (
+	sourceDefault = "по умолчанию"
+	sourceFile    = "файл"
+	sourceEnv     = "окружение"
+	sourceFlag    = "флаг"
+)
+
+// logLevels допустимые уровни журнала.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// sslModes допустимые режимы SSL PostgreSQL.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Default возвращает конфигурацию по умолчанию.
+func Default() Config {
+	return Config{
+		Port:               "8080",
+		ReadHeaderTimeout:  5 * time.Second,
+		ReadTimeout:        30 * time.Second,
+		WriteTimeout:       5 * time.Minute,
+		IdleTimeout:        2 * time.Minute,
+		ShutdownTimeout:    30 * time.Second,
+		LogLevel:           "info",
+		InfoServiceTimeout: 5 * time.Second,
+		ReleaseDateFormat:  string(models.DateFormatRU),
+		TrashPurgeInterval: time.Hour,
+		Database: Database{
+			Port:        "5432",
+			SSLMode:     "prefer",
+			AutoMigrate: true,
+		},
+	}
 }
 
-// Загрузка конфигураций
-func LoadConfig() Config {
+// Load собирает конфигурацию из значений по умолчанию, файла YAML или TOML, переменных окружения и флагов args.
+// Каждый следующий источник переопределяет предыдущий. Файл задается флагом -config или переменной CONFIG_FILE,
+// переменные окружения дополняются файлом .env, если он есть. Возвращает аргументы после флагов.
+//
+// Ошибки всех настроек собираются в одну. Конфигурация возвращается и при ошибке, чтобы ее можно было вывести.
+func Load(args []string) (Config, []string, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil, fmt.Errorf("ошибка загрузки .env файла: %w", err)
+	}
+	return load(args, os.LookupEnv)
+}
+
+// load реализует Load с заданным источником переменных окружения.
+func load(args []string, lookupEnv func(string) (string, bool)) (Config, []string, error) {
+	conf := Default()
+	conf.sources = make(map[string]string, len(settings))
+	for _, s := range settings {
+		conf.sources[s.key] = sourceDefault
+	}
+
+	flags := flag.NewFlagSet("music-info", flag.ContinueOnError)
+	configFile := flags.String("config", "", "файл конфигурации YAML или TOML (CONFIG_FILE)")
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		key := s.key
+		usage := s.usage + " (" + s.env + ")"
+		record := func(value string) error {
+			flagValues[key] = value
+			return nil
+		}
+		if _, ok := s.field(&conf).(boolValue); ok {
+			flags.BoolFunc(s.flag, usage, record)
+		} else {
+			flags.Func(s.flag, usage, record)
+		}
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Использование: music-info [флаги] [import | migrate | config print] ...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return conf, nil, err
+	}
+
+	var errs []error
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := conf.loadFile(*configFile); err != nil {
+			errs = append(errs, fmt.Errorf("файл конфигурации %s: %w", *configFile, err))
+		}
+	}
+
+	for _, s := range settings {
+		value, ok := lookupEnv(s.env)
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		if err := s.field(&conf).Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			continue
+		}
+		conf.sources[s.key] = sourceEnv + " " + s.env
+	}
+
+	for _, s := range settings {
+		value, ok := flagValues[s.key]
+		if !ok {
+			continue
+		}
+		if err := s.field(&conf).Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("флаг -%s: %w", s.flag, err))
+			continue
+		}
+		conf.sources[s.key] = sourceFlag + " -" + s.flag
+	}
+
+	errs = append(errs, conf.Validate())
+	return conf, flags.Args(), errors.Join(errs...)
+}
 
-	err := godotenv.Load()
+// loadFile читает настройки из файла YAML или TOML, формат определяется по расширению. Неизвестные ключи считаются ошибкой.
+func (c *Config) loadFile(name string) error {
+	data, err := os.ReadFile(name)
 	if err != nil {
-		log.Fatalf("Ошибка загрузки .env файла: %v", err)
+		return err
 	}
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".yaml", ".yml":
+	case ".toml":
+		return c.loadTOML(data, sourceFile+" "+name)
+	default:
+		return fmt.Errorf("неподдерживаемый формат '%s': ожидается .yaml, .yml или .toml", ext)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	source := sourceFile + " " + name
+	for _, key := range fileKeys("", values) {
+		if _, ok := c.sources[key]; ok {
+			c.sources[key] = source
+		}
+	}
+	return nil
+}
 
-	var conf Config
+// fileKeys возвращает ключи файла конфигурации, вложенные ключи соединяются точкой.
+func fileKeys(prefix string, values map[string]any) []string {
+	var keys []string
+	for key, value := range values {
+		if nested, ok := value.(map[string]any); ok {
+			keys = append(keys, fileKeys(prefix+key+".", nested)...)
+			continue
+		}
+		keys = append(keys, prefix+key)
+	}
+	return keys
+}
 
-	conf.Port = os.Getenv("PORT")
-	if conf.Port == "" {
-		conf.Port = "8080"
+// Validate проверяет все настройки и возвращает ошибки сразу по всем неверным значениям.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if !validPort(c.Port) {
+		invalid("port", "неверный порт '%s'", c.Port)
+	}
+	for _, timeout := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"readHeaderTimeout", c.ReadHeaderTimeout},
+		{"readTimeout", c.ReadTimeout},
+		{"writeTimeout", c.WriteTimeout},
+		{"idleTimeout", c.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			invalid(timeout.key, "не может быть отрицательным")
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		invalid("shutdownTimeout", "должен быть больше 0")
+	}
+	if !slices.Contains(logLevels, c.LogLevel) {
+		invalid("logLevel", "неизвестный уровень '%s': ожидается %s", c.LogLevel, strings.Join(logLevels, ", "))
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin != "*" && !validOrigin(origin) {
+			invalid("corsOrigins", "неверный источник '%s': ожидается * или схема://хост[:порт]", origin)
+		}
+	}
+	if c.InfoServiceURL != "" {
+		if u, err := url.Parse(c.InfoServiceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("infoServiceUrl", "ожидается абсолютный адрес http или https")
+		}
+	}
+	if c.InfoServiceTimeout <= 0 {
+		invalid("infoServiceTimeout", "должен быть больше 0")
+	}
+	if format := models.DateFormat(c.ReleaseDateFormat); format != models.DateFormatRU && format != models.DateFormatISO {
+		invalid("releaseDateFormat", "неизвестный формат '%s': ожидается %s или %s", c.ReleaseDateFormat, models.DateFormatRU, models.DateFormatISO)
+	}
+	if c.TrashRetention < 0 {
+		invalid("trashRetention", "не может быть отрицательным")
+	}
+	if c.TrashPurgeInterval <= 0 {
+		invalid("trashPurgeInterval", "должен быть больше 0")
 	}
 
-	if os.Getenv("DB_HOST") == "" || os.Getenv("DB_USER") == "" || os.Getenv("DB_NAME") == "" || os.Getenv("DB_PASSWORD") == "" || os.Getenv("DB_PORT") == "" || os.Getenv("DB_SSLMODE") == "" {
-		log.Fatalf("не все переменные окружения базы данных установлены")
+	db := c.Database
+	for _, required := range []struct{ key, value string }{
+		{"database.host", db.Host},
+		{"database.user", db.User},
+		{"database.password", db.Password},
+		{"database.name", db.Name},
+	} {
+		if required.value == "" {
+			invalid(required.key, "не задан")
+		}
+	}
+	if !validPort(db.Port) {
+		invalid("database.port", "неверный порт '%s'", db.Port)
+	}
+	if !slices.Contains(sslModes, db.SSLMode) {
+		invalid("database.sslmode", "неизвестный режим '%s': ожидается %s", db.SSLMode, strings.Join(sslModes, ", "))
+	}
+	if db.MaxOpenConns < 0 {
+		invalid("database.maxOpenConns", "не может быть отрицательным")
+	}
+	if db.MaxIdleConns < 0 {
+		invalid("database.maxIdleConns", "не может быть отрицательным")
+	} else if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		invalid("database.maxIdleConns", "не может превышать database.maxOpenConns")
 	}
-	conf.DSN = "host=" + os.Getenv("DB_HOST") +
-		" user=" + os.Getenv("DB_USER") +
-		" dbname=" + os.Getenv("DB_NAME") +
-		" password=" + os.Getenv("DB_PASSWORD") +
-		" port=" + os.Getenv("DB_PORT") +
-		" sslmode=" + os.Getenv("DB_SSLMODE") +
+	if db.ConnMaxLifetime < 0 {
+		invalid("database.connMaxLifetime", "не может быть отрицательным")
+	}
+	if db.ConnMaxIdleTime < 0 {
+		invalid("database.connMaxIdleTime", "не может быть отрицательным")
+	}
+
+	return errors.Join(errs...)
+}
+
+// DSN строка подключения к базе данных.
+func (c Config) DSN() string {
+	db := c.Database
+	return "host=" + dsnValue(db.Host) +
+		" user=" + dsnValue(db.User) +
+		" dbname=" + dsnValue(db.Name) +
+		" password=" + dsnValue(db.Password) +
+		" port=" + dsnValue(db.Port) +
+		" sslmode=" + dsnValue(db.SSLMode) +
 		" TimeZone=UTC"
+}
+
+// Print выводит итоговые значения настроек с источником каждого значения. Секреты скрываются.
+func (c Config) Print(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "КЛЮЧ\tЗНАЧЕНИЕ\tИСТОЧНИК")
+	for _, s := range settings {
+		value := s.field(&c).String()
+		if s.redact != nil {
+			value = s.redact(value)
+		}
+		source := c.sources[s.key]
+		if source == "" {
+			source = sourceDefault
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", s.key, strconv.Quote(value), source)
+	}
+	return writer.Flush()
+}
+
+// dsnValue экранирует значение строки подключения, если в нем есть пробелы или кавычки.
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// validPort сообщает, что значение является номером порта TCP.
+func validPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
 
-	return conf
+// validOrigin сообщает, что значение является источником вида схема://хост[:порт] без пути.
+func validOrigin(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
 }
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting настройка, которую можно задать в файле конфигурации, переменной окружения и флагом.
+type setting struct {
+	// key ключ в файле конфигурации, вложенные ключи разделены точкой.
+	key string
+	// env имя переменной окружения.
+	env string
+	// flag имя флага командной строки.
+	flag  string
+	usage string
+	// field возвращает значение настройки, связанное с полем конфигурации.
+	field func(c *Config) flag.Value
+	// redact скрывает секрет в выводе config print.
+	redact func(value string) string
+}
+
+// settings все настройки приложения в порядке вывода.
+var settings = []setting{
+	{key: "port", env: "PORT", flag: "port", usage: "порт HTTP-сервера",
+		field: func(c *Config) flag.Value { return stringValue{&c.Port} }},
+	{key: "readHeaderTimeout", env: "HTTP_READ_HEADER_TIMEOUT", flag: "read-header-timeout", usage: "таймаут чтения заголовков запроса",
+		field: func(c *Config) flag.Value { return durationValue{&c.ReadHeaderTimeout} }},
+	{key: "readTimeout", env: "HTTP_READ_TIMEOUT", flag: "read-timeout", usage: "таймаут чтения запроса вместе с телом, 0 отключает",
+		field: func(c *Config) flag.Value { return durationValue{&c.ReadTimeout} }},
+	{key: "writeTimeout", env: "HTTP_WRITE_TIMEOUT", flag: "write-timeout", usage: "таймаут записи ответа, 0 отключает",
+		field: func(c *Config) flag.Value { return durationValue{&c.WriteTimeout} }},
+	{key: "idleTimeout", env: "HTTP_IDLE_TIMEOUT", flag: "idle-timeout", usage: "время простоя соединения keep-alive",
+		field: func(c *Config) flag.Value { return durationValue{&c.IdleTimeout} }},
+	{key: "shutdownTimeout", env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "время на завершение запросов и фоновых задач при остановке",
+		field: func(c *Config) flag.Value { return durationValue{&c.ShutdownTimeout} }},
+	{key: "logLevel", env: "LOG_LEVEL", flag: "log-level", usage: "уровень журнала запросов к базе данных: debug, info, warn или error",
+		field: func(c *Config) flag.Value { return stringValue{&c.LogLevel} }},
+	{key: "corsOrigins", env: "CORS_ORIGINS", flag: "cors-origins", usage: "источники, которым разрешены запросы из браузера, через запятую; * разрешает любой",
+		field: func(c *Config) flag.Value { return listValue{&c.CORSOrigins} }},
+	{key: "releaseDateFormat", env: "RELEASE_DATE_FORMAT", flag: "release-date-format", usage: "формат вывода даты выпуска: ru или iso",
+		field: func(c *Config) flag.Value { return stringValue{&c.ReleaseDateFormat} }},
+	{key: "infoServiceUrl", env: "INFO_SERVICE_URL", flag: "info-service-url", usage: "адрес внешнего сервиса информации о песнях, пустой отключает обогащение",
+		field:  func(c *Config) flag.Value { return stringValue{&c.InfoServiceURL} },
+		redact: redactURL},
+	{key: "infoServiceTimeout", env: "INFO_SERVICE_TIMEOUT", flag: "info-service-timeout", usage: "таймаут запроса к сервису информации о песнях",
+		field: func(c *Config) flag.Value { return durationValue{&c.InfoServiceTimeout} }},
+	{key: "trashRetention", env: "TRASH_RETENTION", flag: "trash-retention", usage: "срок хранения удаленных песен в корзине, 0 (по умолчанию) отключает очистку",
+		field: func(c *Config) flag.Value { return durationValue{&c.TrashRetention} }},
+	{key: "trashPurgeInterval", env: "TRASH_PURGE_INTERVAL", flag: "trash-purge-interval", usage: "период очистки корзины",
+		field: func(c *Config) flag.Value { return durationValue{&c.TrashPurgeInterval} }},
+	{key: "database.host", env: "DB_HOST", flag: "db-host", usage: "хост базы данных",
+		field: func(c *Config) flag.Value { return stringValue{&c.Database.Host} }},
+	{key: "database.port", env: "DB_PORT", flag: "db-port", usage: "порт базы данных",
+		field: func(c *Config) flag.Value { return stringValue{&c.Database.Port} }},
+	{key: "database.user", env: "DB_USER", flag: "db-user", usage: "пользователь базы данных",
+		field: func(c *Config) flag.Value { return stringValue{&c.Database.User} }},
+	{key: "database.password", env: "DB_PASSWORD", flag: "db-password", usage: "пароль базы данных",
+		field:  func(c *Config) flag.Value { return stringValue{&c.Database.Password} },
+		redact: redactSecret},
+	{key: "database.name", env: "DB_NAME", flag: "db-name", usage: "имя базы данных",
+		field: func(c *Config) flag.Value { return stringValue{&c.Database.Name} }},
+	{key: "database.sslmode", env: "DB_SSLMODE", flag: "db-sslmode", usage: "режим SSL: disable, allow, prefer, require, verify-ca или verify-full",
+		field: func(c *Config) flag.Value { return stringValue{&c.Database.SSLMode} }},
+	{key: "database.autoMigrate", env: "DB_AUTO_MIGRATE", flag: "db-auto-migrate", usage: "применять миграции схемы при запуске сервера",
+		field: func(c *Config) flag.Value { return boolValue{&c.Database.AutoMigrate} }},
+	{key: "database.maxOpenConns", env: "DB_MAX_OPEN_CONNS", flag: "db-max-open-conns", usage: "максимальное число открытых соединений, 0 не ограничивает",
+		field: func(c *Config) flag.Value { return intValue{&c.Database.MaxOpenConns} }},
+	{key: "database.maxIdleConns", env: "DB_MAX_IDLE_CONNS", flag: "db-max-idle-conns", usage: "максимальное число простаивающих соединений",
+		field: func(c *Config) flag.Value { return intValue{&c.Database.MaxIdleConns} }},
+	{key: "database.connMaxLifetime", env: "DB_CONN_MAX_LIFETIME", flag: "db-conn-max-lifetime", usage: "время жизни соединения, 0 не ограничивает",
+		field: func(c *Config) flag.Value { return durationValue{&c.Database.ConnMaxLifetime} }},
+	{key: "database.connMaxIdleTime", env: "DB_CONN_MAX_IDLE_TIME", flag: "db-conn-max-idle-time", usage: "время простоя соединения до закрытия, 0 не ограничивает",
+		field: func(c *Config) flag.Value { return durationValue{&c.Database.ConnMaxIdleTime} }},
+}
+
+// stringValue строковая настройка. Пробелы по краям отбрасываются.
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(s string) error {
+	*v.p = strings.TrimSpace(s)
+	return nil
+}
+
+func (v stringValue) String() string { return *v.p }
+
+// intValue целочисленная настройка.
+type intValue struct{ p *int }
+
+func (v intValue) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("неверное целое число '%s'", s)
+	}
+	*v.p = n
+	return nil
+}
+
+func (v intValue) String() string { return strconv.Itoa(*v.p) }
+
+// boolValue логическая настройка. Флаг без значения означает true.
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("неверное логическое значение '%s': ожидается true или false", s)
+	}
+	*v.p = b
+	return nil
+}
+
+func (v boolValue) String() string { return strconv.FormatBool(*v.p) }
+
+func (v boolValue) IsBoolFlag() bool { return true }
+
+// durationValue настройка длительности в формате time.ParseDuration, например 5s или 720h.
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("неверная длительность '%s': ожидается, например, 5s или 1h", s)
+	}
+	*v.p = d
+	return nil
+}
+
+func (v durationValue) String() string { return v.p.String() }
+
+// listValue список значений через запятую. Пустые элементы отбрасываются.
+type listValue struct{ p *[]string }
+
+func (v listValue) Set(s string) error {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*v.p = items
+	return nil
+}
+
+func (v listValue) String() string { return strings.Join(*v.p, ",") }
+
+// redactSecret скрывает непустое значение целиком.
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "******"
+}
+
+// redactURL скрывает пароль в адресе.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return redactSecret(value)
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// loadTOML читает настройки из файла TOML. Поддерживается подмножество формата, которого достаточно для настроек:
+// таблицы, ключи через точку, однострочные строки, числа, логические значения и однострочные массивы строк.
+// Значение передается настройке так же, как из переменной окружения. Неизвестные и повторные ключи считаются ошибкой.
+func (c *Config) loadTOML(data []byte, source string) error {
+	known := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		known[s.key] = s
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	table := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if isComment(line) {
+			continue
+		}
+		fail := func(err error) {
+			errs = append(errs, fmt.Errorf("строка %d: %w", i+1, err))
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || strings.HasPrefix(name, "[") || !isComment(rest) {
+				fail(errors.New("неверное имя таблицы, массивы таблиц не поддерживаются"))
+				continue
+			}
+			table = tomlKey(name) + "."
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			fail(errors.New("ожидается ключ = значение"))
+			continue
+		}
+		key := table + tomlKey(name)
+		s, ok := known[key]
+		if !ok {
+			fail(fmt.Errorf("неизвестный ключ '%s'", key))
+			continue
+		}
+		if seen[key] {
+			fail(fmt.Errorf("ключ '%s' задан повторно", key))
+			continue
+		}
+		seen[key] = true
+
+		text, err := tomlValue(value)
+		if err == nil {
+			err = s.field(c).Set(text)
+		}
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		c.sources[key] = source
+	}
+
+	return errors.Join(errs...)
+}
+
+// tomlKey нормализует ключ или имя таблицы: убирает пробелы вокруг частей, разделенных точкой.
+func tomlKey(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlValue возвращает значение в текстовом виде настройки. Элементы массива соединяются запятой, как в listValue.
+func tomlValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return "", errors.New("многострочные строки не поддерживаются")
+	case strings.HasPrefix(s, "["):
+		var items []string
+		rest := strings.TrimSpace(s[1:])
+		for !strings.HasPrefix(rest, "]") {
+			item, tail, err := tomlString(rest)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+			rest = strings.TrimSpace(tail)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return "", errors.New("ожидается , или ] в однострочном массиве")
+			}
+		}
+		if !isComment(rest[1:]) {
+			return "", errors.New("лишние символы после массива")
+		}
+		return strings.Join(items, ","), nil
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'"):
+		value, rest, err := tomlString(s)
+		if err != nil {
+			return "", err
+		}
+		if !isComment(rest) {
+			return "", errors.New("лишние символы после строки")
+		}
+		return value, nil
+	}
+
+	value, _, _ := strings.Cut(s, "#")
+	value = strings.TrimSpace(value)
+	if value == "true" || value == "false" {
+		return value, nil
+	}
+	number := strings.ReplaceAll(value, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return "", fmt.Errorf("неверное значение '%s': строки заключаются в кавычки", value)
+	}
+	return number, nil
+}
+
+// tomlString разбирает строку в двойных кавычках с экранированием или в одинарных без него.
+// Возвращает значение и остаток после закрывающей кавычки.
+func tomlString(s string) (string, string, error) {
+	if strings.HasPrefix(s, "'") {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("незакрытая строка")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", errors.New("ожидается строка в кавычках")
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("неверная строка %s", s[:i+1])
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("незакрытая строка")
+}
+
+// isComment проверяет, что строка пуста или содержит только комментарий.
+func isComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
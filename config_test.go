@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"music-info/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConfig(t *testing.T) {
+
+	// Создаем тестовые данные
+	conf := config.Default()
+	conf.Database.Password = "secret"
+
+	// Проверяем метод
+	var out bytes.Buffer
+	assert.NoError(t, runConfig(conf, []string{"print"}, &out))
+	assert.Contains(t, out.String(), "database.password")
+	assert.Contains(t, out.String(), `"8080"`)
+	assert.NotContains(t, out.String(), "secret")
+
+	assert.Error(t, runConfig(conf, nil, &out))
+	assert.Error(t, runConfig(conf, []string{"show"}, &out))
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AlbumRepository хранилище релизов.
+type AlbumRepository interface {
+	// CreateAlbum сохраняет новый релиз вместе с треками и заполняет его идентификатор.
+	// Если исполнитель или одна из песен не найдены, возвращает ошибку ErrValidation.
+	CreateAlbum(album *models.Album) error
+	// AlbumByID возвращает релиз с треками в порядке дисков и номеров и песнями треков.
+	// Треки удаленных песен не возвращаются.
+	AlbumByID(id uint) (*models.Album, error)
+	// ReplaceTracks заменяет список треков релиза. Если одна из песен не найдена, возвращает ошибку ErrValidation.
+	ReplaceTracks(id uint, tracks []models.AlbumTrack) error
+}
+
+// albumColumns поля релиза, возвращаемые клиенту.
+var albumColumns = []string{"id", "created_at", "updated_at", "title", "artist_id", "release_date", "release_precision", "type", "cover_url"}
+
+// CreateAlbum создание нового релиза с треками.
+func (r *GormRepository) CreateAlbum(album *models.Album) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Исполнитель блокируется на чтение, как в linkArtist: одновременный DeleteArtist дождется
+		// сохранения релиза и увидит его при подсчете.
+		result := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").Take(&models.Artist{}, album.ArtistID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return NewValidationError(fmt.Errorf("исполнитель id=%d не найден", album.ArtistID))
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := checkTrackSongs(tx, album.Tracks); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(album).Error; err != nil {
+			return err
+		}
+
+		return createTracks(tx, album.ID, album.Tracks)
+	})
+}
+
+// AlbumByID возвращение релиза с треками по идентификатору.
+func (r *GormRepository) AlbumByID(id uint) (*models.Album, error) {
+
+	var album models.Album
+
+	result := r.db.Select(albumColumns).
+		Preload("Tracks", func(db *gorm.DB) *gorm.DB { return db.Order("disc, track") }).
+		Preload("Tracks.Song", func(db *gorm.DB) *gorm.DB { return db.Select(songColumns) }).
+		First(&album, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, notFoundAlbum(id)
+		}
+		return nil, result.Error
+	}
+
+	// Удаленные песни не загружаются, их треки пропускаются
+	tracks := []models.AlbumTrack{}
+	for _, track := range album.Tracks {
+		if track.Song != nil {
+			tracks = append(tracks, track)
+		}
+	}
+	album.Tracks = tracks
+
+	return &album, nil
+}
+
+// ReplaceTracks замена списка треков релиза.
+func (r *GormRepository) ReplaceTracks(id uint, tracks []models.AlbumTrack) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&models.Album{}, id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return notFoundAlbum(id)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := checkTrackSongs(tx, tracks); err != nil {
+			return err
+		}
+
+		// Позиции треков входят в первичный ключ, поэтому при перестановке старые треки удаляются целиком
+		if err := tx.Where("album_id = ?", id).Delete(&models.AlbumTrack{}).Error; err != nil {
+			return err
+		}
+		if err := createTracks(tx, id, tracks); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Album{}).Where("id = ?", id).Update("updated_at", time.Now()).Error
+	})
+}
+
+// checkTrackSongs проверяет, что песни всех треков существуют и не удалены.
+func checkTrackSongs(tx *gorm.DB, tracks []models.AlbumTrack) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.SongID)
+	}
+
+	var found []uint
+	if err := tx.Model(&models.MusicInfo{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return err
+	}
+
+	return missingSongsError(ids, found)
+}
+
+// createTracks сохраняет треки релиза.
+func createTracks(tx *gorm.DB, albumID uint, tracks []models.AlbumTrack) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	rows := make([]models.AlbumTrack, 0, len(tracks))
+	for _, track := range tracks {
+		rows = append(rows, models.AlbumTrack{AlbumID: albumID, Disc: track.Disc, Track: track.Track, SongID: track.SongID})
+	}
+
+	return tx.Omit(clause.Associations).Create(&rows).Error
+}
+
+// missingSongsError возвращает ошибку валидации со списком идентификаторов из ids, которых нет среди found.
+func missingSongsError(ids, found []uint) error {
+	exists := make(map[uint]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+
+	var missing []uint
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return NewValidationError(fmt.Errorf("песни не найдены: %v", missing))
+	}
+
+	return nil
+}
+
+// CreateAlbum сохраняет новый релиз с треками.
+func (r *MemoryRepository) CreateAlbum(album *models.Album) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.artists[album.ArtistID]; !ok {
+		return NewValidationError(fmt.Errorf("исполнитель id=%d не найден", album.ArtistID))
+	}
+	if err := r.checkTrackSongs(album.Tracks); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	album.ID = r.nextAlbumID
+	album.CreatedAt = now
+	album.UpdatedAt = now
+	r.nextAlbumID++
+
+	r.albums[album.ID] = cloneAlbum(album)
+
+	return nil
+}
+
+// AlbumByID возвращает релиз с треками и песнями треков.
+func (r *MemoryRepository) AlbumByID(id uint) (*models.Album, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	album, ok := r.albums[id]
+	if !ok {
+		return nil, notFoundAlbum(id)
+	}
+
+	result := cloneAlbum(album)
+	tracks := []models.AlbumTrack{}
+	for _, track := range result.Tracks {
+		if songInfo, ok := r.songs[track.SongID]; ok {
+			song := *songInfo
+			track.Song = &song
+			tracks = append(tracks, track)
+		}
+	}
+	models.SortTracks(tracks)
+	result.Tracks = tracks
+
+	return result, nil
+}
+
+// ReplaceTracks заменяет список треков релиза.
+func (r *MemoryRepository) ReplaceTracks(id uint, tracks []models.AlbumTrack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	album, ok := r.albums[id]
+	if !ok {
+		return notFoundAlbum(id)
+	}
+	if err := r.checkTrackSongs(tracks); err != nil {
+		return err
+	}
+
+	album.Tracks = cloneTracks(tracks)
+	album.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// checkTrackSongs проверяет, что песни всех треков существуют. Вызывается под блокировкой.
+func (r *MemoryRepository) checkTrackSongs(tracks []models.AlbumTrack) error {
+	ids := make([]uint, 0, len(tracks))
+	found := make([]uint, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.SongID)
+		if _, ok := r.songs[track.SongID]; ok {
+			found = append(found, track.SongID)
+		}
+	}
+
+	return missingSongsError(ids, found)
+}
+
+// cloneAlbum возвращает копию релиза, не разделяющую с ним треки.
+func cloneAlbum(album *models.Album) *models.Album {
+	result := *album
+	result.Tracks = cloneTracks(album.Tracks)
+	return &result
+}
+
+// cloneTracks возвращает копию треков без песен.
+func cloneTracks(tracks []models.AlbumTrack) []models.AlbumTrack {
+	result := make([]models.AlbumTrack, 0, len(tracks))
+	for _, track := range tracks {
+		track.Song = nil
+		result = append(result, track)
+	}
+	return result
+}
@@ -0,0 +1,429 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArtistRepository хранилище исполнителей.
+type ArtistRepository interface {
+	// CreateArtist сохраняет нового исполнителя и заполняет его идентификатор.
+	// Если исполнитель с таким именем уже существует, возвращает *DuplicateArtistError.
+	CreateArtist(artist *models.Artist) error
+	// ArtistByID возвращает исполнителя по идентификатору.
+	ArtistByID(id uint) (*models.Artist, error)
+	// ListArtists возвращает страницу исполнителей, в имени которых содержится name, в порядке имени.
+	ListArtists(name string, page, limit int) ([]models.Artist, error)
+	// ReplaceArtist заменяет все поля исполнителя. При смене имени поле Group его песен тоже меняется,
+	// изменение песен записывается в историю правок с автором из поля ChangedBy.
+	ReplaceArtist(id uint, artist *models.Artist) error
+	// DeleteArtist удаляет исполнителя. Если у исполнителя есть песни или релизы, возвращает ошибку ErrConflict.
+	DeleteArtist(id uint) error
+}
+
+// artistColumns поля исполнителя, возвращаемые клиенту.
+var artistColumns = []string{"id", "created_at", "updated_at", "name", "aliases", "country", "formed_year", "members"}
+
+// artistEditableColumns поля исполнителя, которые может изменять клиент.
+var artistEditableColumns = []string{"name", "aliases", "country", "formed_year", "members"}
+
+// artistNameCondition условие поиска исполнителя по имени, совпадающее с уникальным индексом.
+const artistNameCondition = "lower(btrim(name)) = lower(btrim(?))"
+
+// artistAliasCondition условие поиска исполнителя по одному из псевдонимов.
+const artistAliasCondition = "jsonb_typeof(aliases) = 'array' AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(aliases) alias WHERE lower(btrim(alias)) = lower(btrim(?)))"
+
+// CreateArtist создание нового исполнителя.
+func (r *GormRepository) CreateArtist(artist *models.Artist) error {
+
+	result := r.db.Create(artist)
+
+	return r.artistConflictError(result.Error, artist.Name)
+}
+
+// ArtistByID возвращение исполнителя по идентификатору.
+func (r *GormRepository) ArtistByID(id uint) (*models.Artist, error) {
+
+	var artist models.Artist
+
+	result := r.db.Select(artistColumns).First(&artist, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, notFoundArtist(id)
+		}
+		return nil, result.Error
+	}
+
+	return &artist, nil
+}
+
+// ListArtists возвращение списка исполнителей.
+func (r *GormRepository) ListArtists(name string, page, limit int) ([]models.Artist, error) {
+	var artists []models.Artist
+
+	query := r.db.Select(artistColumns)
+	if name != "" {
+		query = query.Where("name ILIKE ? ESCAPE '\\'", containsPattern(name))
+	}
+	result := query.Order("name, id").Offset((page - 1) * limit).Limit(limit).Find(&artists)
+
+	return artists, result.Error
+}
+
+// ReplaceArtist замена всех редактируемых полей исполнителя и переименование группы в его песнях.
+func (r *GormRepository) ReplaceArtist(id uint, artist *models.Artist) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Artist
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "name").First(&current, id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return notFoundArtist(id)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Model(&models.Artist{}).Where("id = ?", id).Select(artistEditableColumns).Updates(artist).Error; err != nil {
+			return r.artistConflictError(err, artist.Name)
+		}
+
+		if current.Name == artist.Name {
+			return nil
+		}
+
+		var songIDs []uint
+		if err := tx.Model(&models.MusicInfo{}).Where("artist_id = ?", id).Pluck("id", &songIDs).Error; err != nil {
+			return err
+		}
+		for _, songID := range songIDs {
+			if err := recordBaseline(tx, songID); err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&models.MusicInfo{}).Where("artist_id = ?", id).Update("group", artist.Name).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("%w: после переименования исполнителя у двух его песен совпадут группа и название", ErrConflict)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, songID := range songIDs {
+			if err := recordRevision(tx, songID, artist.ChangedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return err
+}
+
+// DeleteArtist удаление исполнителя без песен и релизов. Строка исполнителя блокируется до конца транзакции,
+// поэтому песни и релизы не могут сослаться на него между проверкой и удалением. Песни в корзине тоже учитываются:
+// после восстановления они ссылались бы на удаленного исполнителя.
+func (r *GormRepository) DeleteArtist(id uint) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var artist models.Artist
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&artist, id)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return notFoundArtist(id)
+			}
+			return result.Error
+		}
+
+		var songs int64
+		if err := tx.Unscoped().Model(&models.MusicInfo{}).Where("artist_id = ?", id).Count(&songs).Error; err != nil {
+			return err
+		}
+		if songs > 0 {
+			return fmt.Errorf("%w: у исполнителя id=%d есть песни с учетом корзины: %d", ErrConflict, id, songs)
+		}
+
+		var albums int64
+		if err := tx.Model(&models.Album{}).Where("artist_id = ?", id).Count(&albums).Error; err != nil {
+			return err
+		}
+		if albums > 0 {
+			return fmt.Errorf("%w: у исполнителя id=%d есть релизы: %d", ErrConflict, id, albums)
+		}
+
+		return tx.Delete(&artist).Error
+	})
+
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return fmt.Errorf("%w: на исполнителя id=%d ссылаются другие записи", ErrConflict, id)
+	}
+
+	return err
+}
+
+// artistConflictError преобразует нарушение уникального индекса имени исполнителя в *DuplicateArtistError.
+func (r *GormRepository) artistConflictError(err error, name string) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	var existing models.Artist
+	if result := r.db.Select("id", "name").Where(artistNameCondition, name).Take(&existing); result.Error != nil {
+		return &DuplicateArtistError{Name: name}
+	}
+
+	return &DuplicateArtistError{ID: existing.ID, Name: existing.Name}
+}
+
+// linkArtist заполняет ArtistID песни по полю Group, создавая исполнителя при необходимости.
+// Переданный клиентом ArtistID не используется: если Group не заполнено, ArtistID очищается и не обновляется.
+func linkArtist(tx *gorm.DB, songInfo *models.MusicInfo) error {
+	songInfo.ArtistID = nil
+	if strings.TrimSpace(songInfo.Group) == "" {
+		return nil
+	}
+
+	// Найденный исполнитель блокируется на чтение до конца транзакции, чтобы одновременный DeleteArtist
+	// дождался сохранения песни и увидел ее при подсчете.
+	var artist models.Artist
+	for _, condition := range []string{artistNameCondition, artistAliasCondition} {
+		result := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").Where(condition, songInfo.Group).Order("id").Take(&artist)
+		if result.Error == nil {
+			songInfo.ArtistID = &artist.ID
+			return nil
+		}
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return result.Error
+		}
+	}
+
+	// Одновременное добавление песен новой группы может создать исполнителя раньше,
+	// поэтому конфликт по имени не считается ошибкой.
+	artist = models.Artist{Name: strings.TrimSpace(songInfo.Group), Aliases: []string{}, Members: []string{}}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "lower(btrim(name))", Raw: true}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&artist).Error
+	if err != nil {
+		return err
+	}
+	if artist.ID == 0 {
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").Where(artistNameCondition, songInfo.Group).Take(&artist).Error; err != nil {
+			return err
+		}
+	}
+
+	songInfo.ArtistID = &artist.ID
+	return nil
+}
+
+// CreateArtist сохраняет нового исполнителя.
+func (r *MemoryRepository) CreateArtist(artist *models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkArtistUnique(0, artist.Name); err != nil {
+		return err
+	}
+
+	r.createArtist(artist)
+
+	return nil
+}
+
+// ArtistByID возвращает исполнителя по идентификатору.
+func (r *MemoryRepository) ArtistByID(id uint) (*models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	artist, ok := r.artists[id]
+	if !ok {
+		return nil, notFoundArtist(id)
+	}
+
+	return cloneArtist(artist), nil
+}
+
+// ListArtists возвращает страницу исполнителей, в имени которых содержится name.
+func (r *MemoryRepository) ListArtists(name string, page, limit int) ([]models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	artists := []models.Artist{}
+	for _, artist := range r.artists {
+		if containsFold(artist.Name, name) {
+			artists = append(artists, *cloneArtist(artist))
+		}
+	}
+
+	sort.Slice(artists, func(i, j int) bool {
+		if artists[i].Name != artists[j].Name {
+			return artists[i].Name < artists[j].Name
+		}
+		return artists[i].ID < artists[j].ID
+	})
+
+	offset := (page - 1) * limit
+	if offset >= len(artists) {
+		return []models.Artist{}, nil
+	}
+	end := offset + limit
+	if end > len(artists) {
+		end = len(artists)
+	}
+
+	return artists[offset:end], nil
+}
+
+// ReplaceArtist заменяет все редактируемые поля исполнителя и переименовывает группу в его песнях.
+func (r *MemoryRepository) ReplaceArtist(id uint, replaceArtist *models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	artist, ok := r.artists[id]
+	if !ok {
+		return notFoundArtist(id)
+	}
+
+	if err := r.checkArtistUnique(id, replaceArtist.Name); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if artist.Name != replaceArtist.Name {
+		for _, songInfo := range r.songs {
+			if songInfo.ArtistID != nil && *songInfo.ArtistID == id {
+				if err := r.checkUnique(songInfo.ID, replaceArtist.Name, songInfo.Song); err != nil {
+					return fmt.Errorf("%w: после переименования исполнителя у двух его песен совпадут группа и название", ErrConflict)
+				}
+			}
+		}
+		for _, songInfo := range r.songs {
+			if songInfo.ArtistID != nil && *songInfo.ArtistID == id {
+				songInfo.Group = replaceArtist.Name
+				songInfo.UpdatedAt = now
+				r.recordRevision(songInfo, replaceArtist.ChangedBy)
+			}
+		}
+	}
+
+	replaced := cloneArtist(replaceArtist)
+	replaced.Model = artist.Model
+	replaced.ChangedBy = ""
+	replaced.UpdatedAt = now
+	r.artists[id] = replaced
+
+	return nil
+}
+
+// DeleteArtist удаляет исполнителя без песен и релизов.
+func (r *MemoryRepository) DeleteArtist(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.artists[id]; !ok {
+		return notFoundArtist(id)
+	}
+
+	songs := 0
+	for _, stored := range []map[uint]*models.MusicInfo{r.songs, r.trash} {
+		for _, songInfo := range stored {
+			if songInfo.ArtistID != nil && *songInfo.ArtistID == id {
+				songs++
+			}
+		}
+	}
+	if songs > 0 {
+		return fmt.Errorf("%w: у исполнителя id=%d есть песни с учетом корзины: %d", ErrConflict, id, songs)
+	}
+
+	albums := 0
+	for _, album := range r.albums {
+		if album.ArtistID == id {
+			albums++
+		}
+	}
+	if albums > 0 {
+		return fmt.Errorf("%w: у исполнителя id=%d есть релизы: %d", ErrConflict, id, albums)
+	}
+
+	delete(r.artists, id)
+
+	return nil
+}
+
+// createArtist сохраняет нового исполнителя, присваивая ему идентификатор. Вызывается под блокировкой.
+func (r *MemoryRepository) createArtist(artist *models.Artist) {
+	now := time.Now()
+	artist.ID = r.nextArtistID
+	artist.CreatedAt = now
+	artist.UpdatedAt = now
+	r.nextArtistID++
+
+	r.artists[artist.ID] = cloneArtist(artist)
+}
+
+// checkArtistUnique проверяет, что имя не занято другим исполнителем. Вызывается под блокировкой.
+func (r *MemoryRepository) checkArtistUnique(id uint, name string) error {
+	for _, existing := range r.artists {
+		if existing.ID != id && models.NormalizeKey(existing.Name) == models.NormalizeKey(name) {
+			return &DuplicateArtistError{ID: existing.ID, Name: existing.Name}
+		}
+	}
+	return nil
+}
+
+// linkArtist заполняет ArtistID песни по полю Group так же, как linkArtist для базы данных. Вызывается под блокировкой.
+func (r *MemoryRepository) linkArtist(songInfo *models.MusicInfo) {
+	songInfo.ArtistID = nil
+	if strings.TrimSpace(songInfo.Group) == "" {
+		return
+	}
+
+	var byAlias *models.Artist
+	for _, id := range r.sortedArtistIDs() {
+		artist := r.artists[id]
+		if models.NormalizeKey(artist.Name) == models.NormalizeKey(songInfo.Group) {
+			songInfo.ArtistID = &artist.ID
+			return
+		}
+		if byAlias == nil && artist.HasName(songInfo.Group) {
+			byAlias = artist
+		}
+	}
+	if byAlias != nil {
+		songInfo.ArtistID = &byAlias.ID
+		return
+	}
+
+	artist := models.Artist{Name: strings.TrimSpace(songInfo.Group), Aliases: []string{}, Members: []string{}}
+	r.createArtist(&artist)
+	songInfo.ArtistID = &artist.ID
+}
+
+// sortedArtistIDs возвращает идентификаторы исполнителей в порядке добавления.
+func (r *MemoryRepository) sortedArtistIDs() []uint {
+	ids := make([]uint, 0, len(r.artists))
+	for id := range r.artists {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// cloneArtist возвращает копию исполнителя, не разделяющую с ним списки.
+func cloneArtist(artist *models.Artist) *models.Artist {
+	result := *artist
+	result.Aliases = append([]string{}, artist.Aliases...)
+	result.Members = append([]string{}, artist.Members...)
+	return &result
+}
@@ -1,94 +1,128 @@
 package database
 
 import (
-	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"music-info/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-// Инициализация базы данных
-func InitDB(DNS string) {
+// OpenDB открывает соединение с базой данных без применения миграций.
+func OpenDB(DNS string) {
 
 	var err error
-	DB, err = gorm.Open(postgres.Open(DNS), &gorm.Config{})
+	// TranslateError нужен для распознавания нарушений уникального индекса пары группы и названия
+	DB, err = gorm.Open(postgres.Open(DNS), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Ошибка при открытии базы данных: %v", err)
 	}
+	log.Println(DB.Name())
+}
+
+// Инициализация базы данных: открытие соединения и применение непримененных миграций
+func InitDB(DNS string) {
+
+	OpenDB(DNS)
 
-	err = DB.AutoMigrate(&models.MusicInfo{})
+	migrator, err := NewMigrator(DB)
 	if err != nil {
-		log.Fatalf("Ошибка при создании таблицы: %v", err)
+		log.Fatalf("Ошибка загрузки миграций: %v", err)
+	}
+	// Песни-дубликаты, совпадающие по группе и названию, миграция переносит в корзину до создания уникального индекса
+	if _, err := migrator.Up(); err != nil {
+		log.Fatalf("Ошибка применения миграций: %v", err)
 	}
-	log.Println(DB.Name())
 	log.Println("База данных инициализирована")
 
 }
 
-// Создание новой запись в базе данных.
-func DBSongCreate(songInfo *models.MusicInfo) error {
+// Close закрывает пул соединений с базой данных. Ничего не делает, если база данных не открыта.
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
 
-	result := DB.Create(songInfo)
+// PoolConfig параметры пула соединений. Нулевые значения оставляют значения database/sql по умолчанию.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
 
-	return result.Error
+// ConfigurePool задает параметры пула соединений базы данных.
+func ConfigurePool(db *gorm.DB, pool PoolConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+	return nil
 }
 
-// Обновление информации о песне по полям Group и Song.
-func DBSongUpdate(group, song string, updateSong *models.MusicInfo) error {
+// logLevels уровни журнала GORM для уровней журнала приложения. На уровне debug выводятся все запросы.
+var logLevels = map[string]logger.LogLevel{
+	"debug": logger.Info,
+	"info":  logger.Warn,
+	"warn":  logger.Warn,
+	"error": logger.Error,
+}
+
+// SetLogLevel задает уровень журнала запросов к базе данных: debug, info, warn или error.
+func SetLogLevel(db *gorm.DB, level string) error {
+	gormLevel, ok := logLevels[level]
+	if !ok {
+		return fmt.Errorf("неизвестный уровень журнала: %s", level)
+	}
+	db.Logger = db.Logger.LogMode(gormLevel)
+	return nil
+}
 
-	result := DB.Model(&models.MusicInfo{}).Where("\"group\" = ? AND \"song\" = ?", group, song).Updates(updateSong)
+// Создание новой запись в базе данных.
+func DBSongCreate(songInfo *models.MusicInfo) error {
+	return NewGormRepository(DB).Create(songInfo)
+}
 
-	return result.Error
+// Обновление информации о песне по полям Group и Song.
+func DBSongUpdate(group, song string, updateSong *models.MusicInfo) error {
+	return NewGormRepository(DB).Update(group, song, updateSong)
 }
 
 // Удаление информации о песне по полям Group и Song.
 func DBSongDelete(group, song string) error {
-
-	result := DB.Where("\"group\" = ? AND \"song\" = ?", group, song).Delete(&models.MusicInfo{})
-
-	if result.Error != nil {
-		return fmt.Errorf("ошибка при удалении записи: %v", result.Error)
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("запись не найдена: group=%s, song=%s", group, song)
-	}
-
-	return result.Error
+	return NewGormRepository(DB).Delete(group, song)
 }
 
 // Возвращение информации о песне по полям Group и Song.
 func DBSongDetail(group, song string) (*models.MusicInfo, error) {
-
-	var songInfo models.MusicInfo
-
-	if DB == nil {
-		return nil, errors.New("база данных не инициализирована")
-	}
-
-	result := DB.Select("group", "song", "release_date", "text", "link").Where("\"group\" = ? AND \"song\" = ?", group, song).First(&songInfo)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("запись не найдена: group=%s, song=%s", group, song)
-		}
-		return nil, result.Error
-	}
-
-	return &songInfo, nil
+	return NewGormRepository(DB).Detail(group, song)
 }
 
 // Возвращение списка песен
-func DBGetSongs(group string, page, limit int) ([]models.MusicInfo, error) {
-	var songs []models.MusicInfo
-
-	offset := (page - 1) * limit
-	query := DB.Select("group", "song", "release_date", "text", "link").Where("\"group\" LIKE ?", "%"+group+"%").Offset(offset).Limit(limit).Order("\"group\", song, release_date, text, link").Find(&songs)
-
-	return songs, query.Error
+func DBGetSongs(filter SongFilter, order SongSort, page, limit int) ([]models.MusicInfo, error) {
+	return NewGormRepository(DB).List(filter, order, page, limit)
 }
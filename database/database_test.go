@@ -2,14 +2,42 @@ package database
 
 import (
 	"log"
+	"sync"
 	"testing"
+	"time"
 
 	"music-info/models"
 
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+func TestSetLogLevel(t *testing.T) {
+
+	// Создаем тестовые данные
+	db := &gorm.DB{Config: &gorm.Config{Logger: logger.Default}}
+
+	// Проверяем метод
+	assert.NoError(t, SetLogLevel(db, "debug"))
+	assert.Equal(t, logger.Default.LogMode(logger.Info), db.Logger)
+	assert.NoError(t, SetLogLevel(db, "error"))
+	assert.Equal(t, logger.Default.LogMode(logger.Error), db.Logger)
+	assert.Error(t, SetLogLevel(db, "trace"))
+}
+
+func TestConfigurePool(t *testing.T) {
+
+	// Создаем тестовые данные
+	initTestDB(t)
+
+	// Проверяем метод
+	assert.NoError(t, ConfigurePool(DB, PoolConfig{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetime: time.Minute}))
+	sqlDB, err := DB.DB()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+}
+
 func TestDBSongCreate(t *testing.T) {
 
 	// Создаем тестовые данные
@@ -19,7 +47,7 @@ func TestDBSongCreate(t *testing.T) {
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -39,7 +67,7 @@ func TestDBSongUpdate(t *testing.T) {
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -74,7 +102,7 @@ func TestDBDeleteSong(t *testing.T) {
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -112,7 +140,7 @@ func TestDBSongDetail(t *testing.T) {
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -128,9 +156,13 @@ func TestDBSongDetail(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, songInfo.Group, result.Group)
 	assert.Equal(t, songInfo.Song, result.Song)
-	assert.Equal(t, songInfo.ReleaseDate, result.ReleaseDate)
+	assert.Equal(t, songInfo.ReleaseDate.String(), result.ReleaseDate.String())
 	assert.Equal(t, songInfo.Text, result.Text)
 	assert.Equal(t, songInfo.Link, result.Link)
+
+	result, err = DBSongDetail(" muse", "SUPERMASSIVE black hole ")
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.Song, result.Song)
 }
 
 func TestDBGetSongs(t *testing.T) {
@@ -143,13 +175,13 @@ func TestDBGetSongs(t *testing.T) {
 		{
 			Group:       "Muse",
 			Song:        "Supermassive Black Hole",
-			ReleaseDate: "16.07.2006",
+			ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 			Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 			Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 		}, {
 			Group:       "Queen",
 			Song:        "Bohemian Rhapsody",
-			ReleaseDate: "31.10.1975",
+			ReleaseDate: models.MustParseReleaseDate("31.10.1975"),
 			Text:        "Is this the real life? Is this just fantasy?\nCaught in a landslide, no escape from reality\nOpen your eyes, look up to the skies and see\nI'm just a poor boy, I need no sympathy\nBecause I'm easy come, easy go\nLittle high, little low\nAny way the wind blows doesn't really matter to me, to me\n\nMama, just killed a man\nPut a gun against his head, pulled my trigger, now he's dead\nMama, life had just begun\nBut now I've gone and thrown it all away\nMama, ooh, didn't mean to make you cry\nIf I'm not back again this time tomorrow\nCarry on, carry on as if nothing really matters",
 			Link:        "https://www.youtube.com/watch?v=vbvyNnw8Qjg",
 		},
@@ -160,7 +192,7 @@ func TestDBGetSongs(t *testing.T) {
 	}
 
 	// Проверяем метод
-	result, err := DBGetSongs("Muse", 1, 1)
+	result, err := DBGetSongs(SongFilter{Group: "Muse"}, DefaultSongSort, 1, 1)
 	if err != nil {
 		t.Fatalf("Ошибка при вызове метода DBGetSongs: %v", err)
 	}
@@ -169,3 +201,565 @@ func TestDBGetSongs(t *testing.T) {
 	assert.Equal(t, 1, len(result))
 	assert.Equal(t, "Muse", result[0].Group)
 }
+
+func TestGormRepositoryByID(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+
+	// Проверяем методы
+	result, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, result.ID)
+	assert.False(t, result.CreatedAt.IsZero())
+
+	err = repo.ReplaceByID(songInfo.ID, &models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh"})
+	assert.NoError(t, err)
+
+	result, err = repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", result.Link)
+
+	// Нераспознанная дата выпуска выводится и очищается записью новой даты
+	assert.NoError(t, DB.Exec("UPDATE music_infos SET release_date_legacy = 'лето 2006' WHERE id = ?", songInfo.ID).Error)
+	result, err = repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.LegacyReleaseDate("лето 2006"), result.ReleaseDateLegacy)
+
+	err = repo.ReplaceByID(songInfo.ID, &models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: models.MustParseReleaseDate("2006"), Text: "Ooh"})
+	assert.NoError(t, err)
+	result, err = repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, result.ReleaseDateLegacy)
+
+	err = repo.DeleteByID(songInfo.ID)
+	assert.NoError(t, err)
+
+	_, err = repo.DetailByID(songInfo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = repo.Update("Muse", "Supermassive Black Hole", &models.MusicInfo{Text: "Ooh"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGormRepositoryPatchByID(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"}
+	assert.NoError(t, repo.Create(&songInfo))
+	fields := []func(current *models.MusicInfo){
+		func(current *models.MusicInfo) { current.Link = "https://example.com/muse" },
+		func(current *models.MusicInfo) { current.ReleaseDate = models.MustParseReleaseDate("2006") },
+		func(current *models.MusicInfo) { current.Text = "Ooh" },
+	}
+
+	// Проверяем, что одновременные изменения разных полей не теряются
+	var wg sync.WaitGroup
+	for _, set := range fields {
+		wg.Add(1)
+		go func(set func(current *models.MusicInfo)) {
+			defer wg.Done()
+			assert.NoError(t, repo.PatchByID(songInfo.ID, func(current *models.MusicInfo) error {
+				set(current)
+				return nil
+			}))
+		}(set)
+	}
+	wg.Wait()
+
+	result, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/muse", result.Link)
+	assert.Equal(t, "2006", result.ReleaseDate.String())
+	assert.Equal(t, "Ooh", result.Text)
+
+	err = repo.PatchByID(songInfo.ID, func(current *models.MusicInfo) error { return ErrValidation })
+	assert.ErrorIs(t, err, ErrValidation)
+	err = repo.PatchByID(songInfo.ID+1000, func(current *models.MusicInfo) error { return nil })
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGormRepositoryUnique(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"}
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+
+	// Проверяем создание дубликата
+	var duplicate *DuplicateSongError
+	err = repo.Create(&models.MusicInfo{Group: "muse ", Song: "SUPERMASSIVE BLACK HOLE", Text: "Ooh"})
+	assert.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, songInfo.ID, duplicate.ID)
+
+	// Проверяем режим upsert
+	update := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
+	created, err := repo.Upsert(&update)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, songInfo.ID, update.ID)
+	assert.Equal(t, "Ooh baby", update.Text)
+}
+
+func TestGormRepositorySearch(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight"},
+		{Group: "Кино", Song: "Группа крови", Text: "Теплое место, но улицы ждут\nОтпечатков наших ног\n\nГруппа крови на рукаве"},
+		{Group: "Muse", Song: "Uprising", Text: "<script>alert(1)</script> paranoia"},
+	} {
+		assert.NoError(t, repo.Create(&songInfo))
+	}
+
+	// Проверяем метод
+	results, err := repo.Search("souls", "en", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, 2, results[0].Verse)
+	assert.Contains(t, results[0].Snippet, "<b>soul</b>")
+
+	results, err = repo.Search("paranoia", "en", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; <b>paranoia</b>", results[0].Snippet)
+
+	results, err = repo.Search("рукава", "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "Кино", results[0].Group)
+	assert.Equal(t, 2, results[0].Verse)
+
+	_, err = repo.Search("soul", "de", 1, 10)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestGormRepositoryListAfter(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	for _, song := range []string{"Uprising", "Hysteria", "Madness"} {
+		assert.NoError(t, repo.Create(&models.MusicInfo{Group: "Muse", Song: song, Text: "Ooh"}))
+	}
+
+	// Проверяем метод
+	page, err := repo.ListAfter(SongFilter{Group: "Muse"}, DefaultSongSort, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Songs))
+	assert.Equal(t, "Hysteria", page.Songs[0].Song)
+	assert.Nil(t, page.Prev)
+
+	page, err = repo.ListAfter(SongFilter{Group: "Muse"}, DefaultSongSort, page.Next, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Songs))
+	assert.Equal(t, "Uprising", page.Songs[0].Song)
+	assert.Nil(t, page.Next)
+
+	page, err = repo.ListAfter(SongFilter{Group: "Muse"}, DefaultSongSort, page.Prev, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Songs))
+	assert.Equal(t, "Hysteria", page.Songs[0].Song)
+
+	total, err := repo.Count(SongFilter{Group: "Muse"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestGormRepositoryListAfterSort(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Uprising", ReleaseDate: models.MustParseReleaseDate("2009"), Text: "Ooh"},
+		{Group: "Muse", Song: "Hysteria", Text: "Ooh"},
+		{Group: "Muse", Song: "Madness", ReleaseDate: models.MustParseReleaseDate("2012"), Text: "Ooh"},
+		{Group: "Muse", Song: "Bliss", Text: "Ooh"},
+	} {
+		assert.NoError(t, repo.Create(&songInfo))
+	}
+
+	order, err := ParseSongSort("-release_date,song")
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	var songs []string
+	var cursor *Cursor
+	for {
+		page, err := repo.ListAfter(SongFilter{}, order, cursor, 1)
+		assert.NoError(t, err)
+		for _, songInfo := range page.Songs {
+			songs = append(songs, songInfo.Song)
+		}
+		if page.Next == nil {
+			break
+		}
+		cursor = page.Next
+	}
+	assert.Equal(t, []string{"Bliss", "Hysteria", "Madness", "Uprising"}, songs)
+}
+
+func TestGormRepositoryArtists(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	muse := models.Artist{Name: "Muse", Aliases: []string{"Muse UK"}, Members: []string{"Matthew Bellamy"}}
+	assert.NoError(t, repo.CreateArtist(&muse))
+
+	uprising := models.MusicInfo{Group: "muse uk", Song: "Uprising", Text: "Paranoia is in bloom"}
+	assert.NoError(t, repo.Create(&uprising))
+	queen := models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	assert.NoError(t, repo.Create(&queen))
+
+	// Проверяем методы
+	assert.Equal(t, muse.ID, *uprising.ArtistID)
+	assert.NotEqual(t, muse.ID, *queen.ArtistID)
+
+	err := repo.CreateArtist(&models.Artist{Name: "MUSE", Aliases: []string{}, Members: []string{}})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	assert.NoError(t, repo.ReplaceArtist(muse.ID, &models.Artist{Name: "Muse (band)", Aliases: []string{}, Members: []string{}}))
+	result, err := repo.DetailByID(uprising.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse (band)", result.Group)
+
+	assert.ErrorIs(t, repo.DeleteArtist(muse.ID), ErrConflict)
+
+	// Песня в корзине тоже не дает удалить исполнителя
+	assert.NoError(t, repo.DeleteByID(queen.ID))
+	assert.ErrorIs(t, repo.DeleteArtist(*queen.ArtistID), ErrConflict)
+	assert.NoError(t, repo.PurgeSong(queen.ID))
+	assert.NoError(t, repo.DeleteArtist(*queen.ArtistID))
+	assert.ErrorIs(t, repo.DeleteArtist(*queen.ArtistID), ErrNotFound)
+}
+
+func TestGormRepositoryAlbums(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "albums")
+	defer DropTableDB(t, tx, "album_tracks")
+
+	repo := NewGormRepository(DB)
+	first := models.MusicInfo{Group: "Muse", Song: "Take a Bow", Text: "Corrupt, you corrupt"}
+	assert.NoError(t, repo.Create(&first))
+	second := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&second))
+
+	album := models.Album{
+		Title:       "Black Holes and Revelations",
+		ArtistID:    *first.ArtistID,
+		ReleaseDate: models.MustParseReleaseDate("03.07.2006"),
+		Type:        models.AlbumTypeLP,
+		Tracks:      []models.AlbumTrack{{SongID: second.ID, Disc: 1, Track: 2}, {SongID: first.ID, Disc: 1, Track: 1}},
+	}
+	assert.NoError(t, repo.CreateAlbum(&album))
+
+	// Проверяем методы
+	result, err := repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Tracks))
+	assert.Equal(t, "Take a Bow", result.Tracks[0].Song.Song)
+	assert.True(t, album.ReleaseDate.Equal(result.ReleaseDate))
+
+	assert.NoError(t, repo.ReplaceTracks(album.ID, []models.AlbumTrack{{SongID: second.ID, Disc: 1, Track: 1}, {SongID: first.ID, Disc: 1, Track: 2}}))
+	result, err = repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Starlight", result.Tracks[0].Song.Song)
+
+	assert.NoError(t, repo.DeleteByID(second.ID))
+	result, err = repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Tracks))
+
+	assert.ErrorIs(t, repo.ReplaceTracks(album.ID, []models.AlbumTrack{{SongID: 999, Disc: 1, Track: 1}}), ErrValidation)
+	_, err = repo.AlbumByID(999)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGormRepositoryTags(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "tags")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "song_tags")
+
+	repo := NewGormRepository(DB)
+	uprising := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"}
+	assert.NoError(t, repo.Create(&uprising))
+	queen := models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	assert.NoError(t, repo.Create(&queen))
+
+	assert.NoError(t, repo.AddSongTags(uprising.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}, {Kind: models.TagKindMood, Name: "Angry"}}))
+	assert.NoError(t, repo.AddSongTags(uprising.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "rock "}}))
+	assert.NoError(t, repo.AddSongTags(queen.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "ROCK"}}))
+
+	// Проверяем методы
+	tags, err := repo.SongTags(uprising.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tags))
+	assert.Equal(t, "Rock", tags[0].Name)
+
+	songs, err := repo.List(SongFilter{Tags: []models.TagRef{{Kind: models.TagKindGenre, Name: "rock"}, {Name: "angry"}}}, DefaultSongSort, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(songs))
+
+	facets, err := repo.TagFacets(SongFilter{}, models.TagKindGenre)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TagFacet{{Tag: tags[0], Count: 2}}, facets)
+
+	assert.NoError(t, repo.RemoveSongTag(uprising.ID, tags[1].ID))
+	assert.ErrorIs(t, repo.RemoveSongTag(uprising.ID, tags[1].ID), ErrNotFound)
+}
+
+func TestGormRepositoryPlaylists(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "playlists")
+	defer DropTableDB(t, tx, "playlist_entries")
+
+	repo := NewGormRepository(DB)
+	var ids []uint
+	for _, song := range []string{"Uprising", "Hysteria", "Starlight"} {
+		songInfo := models.MusicInfo{Group: "Muse", Song: song, Text: song}
+		assert.NoError(t, repo.Create(&songInfo))
+		ids = append(ids, songInfo.ID)
+	}
+
+	playlist := models.Playlist{Name: "Muse", SongIDs: []uint{ids[0], ids[1]}}
+	assert.NoError(t, repo.CreatePlaylist(&playlist))
+
+	// Проверяем методы
+	entry, err := repo.InsertPlaylistEntry(playlist.ID, ids[2], 1)
+	assert.NoError(t, err)
+
+	_, err = repo.InsertPlaylistEntry(playlist.ID, ids[0], 0)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.MovePlaylistEntry(playlist.ID, entry.ID, 4))
+
+	result, err := repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(result.Entries))
+	assert.Equal(t, "Starlight", result.Entries[3].SongName)
+	assert.Equal(t, 4, result.Entries[3].Position)
+
+	assert.NoError(t, repo.RemovePlaylistEntry(playlist.ID, result.Entries[0].ID))
+	assert.NoError(t, repo.DeleteByID(ids[2]))
+
+	result, err = repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(result.Entries))
+	assert.Equal(t, 1, result.Entries[0].Position)
+	assert.False(t, result.Entries[2].Available)
+
+	_, err = repo.InsertPlaylistEntry(playlist.ID, ids[2], 0)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestGormRepositoryImportSongs(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+
+	repo := NewGormRepository(DB)
+	existing := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	assert.NoError(t, repo.Create(&existing))
+	batch := func() []models.MusicInfo {
+		return []models.MusicInfo{
+			{Group: "Muse", Song: "Starlight", Text: "Far away"},
+			{Group: " muse", Song: "UPRISING", Text: "Paranoia"},
+			{Group: "Muse", Song: "starlight ", Text: "Far away"},
+		}
+	}
+
+	// Проверяем метод
+	songs := batch()
+	results, err := repo.ImportSongs(songs, true)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0])
+	assert.Equal(t, &DuplicateSongError{ID: existing.ID, Group: "Muse", Song: "Uprising"}, results[1])
+	assert.Equal(t, &DuplicateSongError{Group: "Muse", Song: "Starlight"}, results[2])
+	assert.Zero(t, songs[0].ID)
+	count, err := repo.Count(SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	songs = batch()
+	results, err = repo.ImportSongs(songs, false)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0])
+	assert.Equal(t, existing.ID+1, songs[0].ID, "пробный импорт не должен расходовать идентификаторы")
+	assert.ErrorIs(t, results[1], ErrConflict)
+	assert.Equal(t, &DuplicateSongError{ID: songs[0].ID, Group: "Muse", Song: "Starlight"}, results[2])
+	count, err = repo.Count(SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestGormRepositoryLyrics(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "synced_lyrics")
+
+	repo := NewGormRepository(DB)
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем метод
+	_, err := repo.SongLyrics(songInfo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: songInfo.ID, LRC: "[00:01.00]Paranoia\n"}))
+	assert.NoError(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: songInfo.ID, LRC: "[00:02.00]Paranoia\n"}))
+	lyrics, err := repo.SongLyrics(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:02.00]Paranoia\n", lyrics.LRC)
+
+	assert.ErrorIs(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: 999, LRC: "[00:01.00]x\n"}), ErrNotFound)
+
+	assert.NoError(t, repo.DeleteSongLyrics(songInfo.ID))
+	assert.ErrorIs(t, repo.DeleteSongLyrics(songInfo.ID), ErrNotFound)
+}
+
+func TestGormRepositoryRevisions(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "song_revisions")
+
+	repo := NewGormRepository(DB)
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in blom", ChangedBy: "author"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем метод
+	assert.NoError(t, repo.Update("Muse", "Uprising", &models.MusicInfo{Text: "Paranoia is in bloom", ChangedBy: "editor"}))
+	assert.NoError(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{Text: "Paranoia is in bloom"}))
+	assert.NoError(t, repo.ReplaceArtist(*songInfo.ArtistID, &models.Artist{Name: "MUSE", ChangedBy: "admin"}))
+
+	revisions, err := repo.SongRevisions(songInfo.ID, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, 3, revisions[0].Version)
+	assert.Equal(t, "admin", revisions[0].Author)
+	assert.Equal(t, []string{"group"}, revisions[0].Fields)
+	assert.Equal(t, []string{"text"}, revisions[1].Fields)
+	assert.Equal(t, "editor", revisions[1].Author)
+
+	revision, err := repo.SongRevision(songInfo.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Paranoia is in blom", revision.Text)
+
+	_, err = repo.SongRevision(songInfo.ID, 4)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Изменение в обход хранилища при существующей истории не порождает версию без автора
+	assert.NoError(t, DB.Model(&models.MusicInfo{}).Where("id = ?", songInfo.ID).Update("link", "https://example.com").Error)
+	assert.NoError(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{Text: "Paranoia", ChangedBy: "editor"}))
+	revisions, err = repo.SongRevisions(songInfo.ID, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(revisions))
+	assert.Equal(t, "editor", revisions[0].Author)
+	assert.Equal(t, []string{"text", "link"}, revisions[0].Fields)
+
+	// Песня без истории получает исходную версию без автора перед первым изменением
+	assert.NoError(t, DB.Where("song_id = ?", songInfo.ID).Delete(&models.SongRevision{}).Error)
+	assert.NoError(t, repo.ReplaceByID(songInfo.ID, &models.MusicInfo{Group: "MUSE", Song: "Uprising", Text: "Paranoia is in bloom", ChangedBy: "editor"}))
+	revisions, err = repo.SongRevisions(songInfo.ID, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, "editor", revisions[0].Author)
+	assert.Equal(t, []string{"text", "link"}, revisions[0].Fields)
+	assert.Equal(t, "", revisions[1].Author)
+}
+
+func TestGormRepositoryTrash(t *testing.T) {
+
+	// Создаем тестовые данные
+	tx := SetupTestDB(t)
+	defer DropTableDB(t, tx, "artists")
+	defer DropTableDB(t, tx, "music_infos")
+	defer DropTableDB(t, tx, "song_revisions")
+
+	repo := NewGormRepository(DB)
+	first := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	second := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&first))
+	assert.NoError(t, repo.Create(&second))
+
+	// Проверяем метод
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	assert.NoError(t, repo.DeleteByID(second.ID))
+	songs, err := repo.DeletedSongs(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(songs))
+	assert.Equal(t, second.ID, songs[0].ID)
+	assert.True(t, songs[0].DeletedAt.Valid)
+
+	assert.NoError(t, repo.RestoreSong(first.ID))
+	_, err = repo.DetailByID(first.ID)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, repo.RestoreSong(first.ID), ErrNotFound)
+
+	duplicate := models.MusicInfo{Group: "muse", Song: "starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&duplicate))
+	assert.ErrorIs(t, repo.RestoreSong(second.ID), ErrConflict)
+
+	assert.ErrorIs(t, repo.PurgeSong(first.ID), ErrNotFound)
+	assert.NoError(t, repo.PurgeSong(second.ID))
+	assert.ErrorIs(t, repo.PurgeSong(second.ID), ErrNotFound)
+
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	purged, err := repo.PurgeDeletedSongs(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = repo.PurgeDeletedSongs(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
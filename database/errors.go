@@ -0,0 +1,102 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ошибки предметной области. Конкретные ошибки хранилища оборачивают одну из них,
+// поэтому их вид проверяется через errors.Is.
+var (
+	// ErrNotFound запись не найдена.
+	ErrNotFound = errors.New("запись не найдена")
+	// ErrConflict запись конфликтует с уже существующей.
+	ErrConflict = errors.New("запись уже существует")
+	// ErrValidation данные не прошли проверку.
+	ErrValidation = errors.New("ошибка валидации")
+)
+
+// DuplicateSongError возвращается при попытке сохранить песню, пара группы и названия которой уже занята.
+type DuplicateSongError struct {
+	ID    uint
+	Group string
+	Song  string
+}
+
+func (e *DuplicateSongError) Error() string {
+	return fmt.Sprintf("песня уже существует: id=%d, group=%s, song=%s", e.ID, e.Group, e.Song)
+}
+
+// Is относит ошибку к ErrConflict.
+func (e *DuplicateSongError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// DuplicateArtistError возвращается при попытке сохранить исполнителя, имя которого уже занято.
+type DuplicateArtistError struct {
+	ID   uint
+	Name string
+}
+
+func (e *DuplicateArtistError) Error() string {
+	return fmt.Sprintf("исполнитель уже существует: id=%d, name=%s", e.ID, e.Name)
+}
+
+// Is относит ошибку к ErrConflict.
+func (e *DuplicateArtistError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ValidationError ошибка проверки входных данных. Текст ошибки передается клиенту.
+type ValidationError struct {
+	Err error
+}
+
+// NewValidationError относит ошибку проверки данных к ErrValidation.
+func NewValidationError(err error) error {
+	return &ValidationError{Err: err}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap возвращает исходную ошибку проверки.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is относит ошибку к ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// notFoundByID возвращает ошибку отсутствия песни с указанным идентификатором.
+func notFoundByID(id uint) error {
+	return fmt.Errorf("%w: id=%d", ErrNotFound, id)
+}
+
+// notFoundByKey возвращает ошибку отсутствия песни с указанными группой и названием.
+func notFoundByKey(group, song string) error {
+	return fmt.Errorf("%w: group=%s, song=%s", ErrNotFound, group, song)
+}
+
+// notFoundArtist возвращает ошибку отсутствия исполнителя с указанным идентификатором.
+func notFoundArtist(id uint) error {
+	return fmt.Errorf("%w: artist id=%d", ErrNotFound, id)
+}
+
+// notFoundAlbum возвращает ошибку отсутствия релиза с указанным идентификатором.
+func notFoundAlbum(id uint) error {
+	return fmt.Errorf("%w: album id=%d", ErrNotFound, id)
+}
+
+// notFoundPlaylist возвращает ошибку отсутствия плейлиста с указанным идентификатором.
+func notFoundPlaylist(id uint) error {
+	return fmt.Errorf("%w: playlist id=%d", ErrNotFound, id)
+}
+
+// notFoundPlaylistEntry возвращает ошибку отсутствия записи в плейлисте.
+func notFoundPlaylistEntry(playlistID, entryID uint) error {
+	return fmt.Errorf("%w: playlist id=%d, entry id=%d", ErrNotFound, playlistID, entryID)
+}
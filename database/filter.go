@@ -0,0 +1,155 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+)
+
+// SongFilter условия отбора песен для списка. Незаполненные поля не ограничивают выборку.
+// Подстроки сравниваются без учета регистра, точные значения группы и названия — так же, как в уникальном индексе.
+type SongFilter struct {
+	// Group подстрока названия группы.
+	Group string
+	// GroupEq точное название группы.
+	GroupEq string
+	// Song подстрока названия песни.
+	Song string
+	// SongEq точное название песни.
+	SongEq string
+	// Text подстрока текста песни.
+	Text string
+	// HasLink наличие ссылки: true — только со ссылкой, false — только без ссылки.
+	HasLink *bool
+	// ArtistID идентификатор исполнителя.
+	ArtistID *uint
+	// Tags теги, каждый из которых должен быть у песни.
+	Tags []models.TagRef
+
+	// ReleasedFrom и ReleasedTo границы даты выпуска включительно.
+	ReleasedFrom *time.Time
+	ReleasedTo   *time.Time
+	// CreatedFrom и CreatedTo границы времени создания включительно.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// UpdatedFrom и UpdatedTo границы времени изменения включительно.
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+}
+
+// likeEscaper экранирует служебные символы шаблона LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern возвращает шаблон ILIKE для поиска подстроки.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// songTagCondition условие наличия у песни тега с указанным именем и видом. Пустой вид соответствует любому.
+const songTagCondition = `EXISTS (SELECT 1 FROM song_tags st JOIN tags t ON t.id = st.tag_id
+	WHERE st.music_info_id = music_infos.id AND lower(btrim(t.name)) = lower(btrim(?)) AND (? = '' OR t.kind = ?))`
+
+// Scope возвращает условия фильтра для запроса gorm. Значения передаются только через параметры запроса.
+func (f SongFilter) Scope(db *gorm.DB) *gorm.DB {
+	if f.Group != "" {
+		db = db.Where("\"group\" ILIKE ? ESCAPE '\\'", containsPattern(f.Group))
+	}
+	if f.GroupEq != "" {
+		db = db.Where("lower(btrim(\"group\")) = lower(btrim(?))", f.GroupEq)
+	}
+	if f.Song != "" {
+		db = db.Where("song ILIKE ? ESCAPE '\\'", containsPattern(f.Song))
+	}
+	if f.SongEq != "" {
+		db = db.Where("lower(btrim(song)) = lower(btrim(?))", f.SongEq)
+	}
+	if f.Text != "" {
+		db = db.Where("text ILIKE ? ESCAPE '\\'", containsPattern(f.Text))
+	}
+	if f.HasLink != nil {
+		if *f.HasLink {
+			db = db.Where("link <> ''")
+		} else {
+			db = db.Where("coalesce(link, '') = ''")
+		}
+	}
+	if f.ArtistID != nil {
+		db = db.Where("artist_id = ?", *f.ArtistID)
+	}
+	for _, ref := range f.Tags {
+		db = db.Where(songTagCondition, ref.Name, string(ref.Kind), string(ref.Kind))
+	}
+	if f.ReleasedFrom != nil {
+		db = db.Where("release_date >= ?", *f.ReleasedFrom)
+	}
+	if f.ReleasedTo != nil {
+		db = db.Where("release_date <= ?", *f.ReleasedTo)
+	}
+	if f.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		db = db.Where("updated_at <= ?", *f.UpdatedTo)
+	}
+	return db
+}
+
+// Match проверяет, что песня удовлетворяет фильтру. Повторяет условия Scope для хранилища в памяти.
+// Теги песни хранятся отдельно от неё, поэтому условие Tags хранилище проверяет само.
+func (f SongFilter) Match(songInfo *models.MusicInfo) bool {
+	if f.Group != "" && !containsFold(songInfo.Group, f.Group) {
+		return false
+	}
+	if f.GroupEq != "" && models.NormalizeKey(songInfo.Group) != models.NormalizeKey(f.GroupEq) {
+		return false
+	}
+	if f.Song != "" && !containsFold(songInfo.Song, f.Song) {
+		return false
+	}
+	if f.SongEq != "" && models.NormalizeKey(songInfo.Song) != models.NormalizeKey(f.SongEq) {
+		return false
+	}
+	if f.Text != "" && !containsFold(songInfo.Text, f.Text) {
+		return false
+	}
+	if f.HasLink != nil && *f.HasLink != (songInfo.Link != "") {
+		return false
+	}
+	if f.ArtistID != nil && (songInfo.ArtistID == nil || *songInfo.ArtistID != *f.ArtistID) {
+		return false
+	}
+	if f.ReleasedFrom != nil || f.ReleasedTo != nil {
+		if songInfo.ReleaseDate.IsZero() || !inRange(*songInfo.ReleaseDate.Date, f.ReleasedFrom, f.ReleasedTo) {
+			return false
+		}
+	}
+	if !inRange(songInfo.CreatedAt, f.CreatedFrom, f.CreatedTo) {
+		return false
+	}
+	return inRange(songInfo.UpdatedAt, f.UpdatedFrom, f.UpdatedTo)
+}
+
+// containsFold проверяет вхождение подстроки без учета регистра.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// inRange проверяет, что время попадает в границы включительно. Пустая граница не ограничивает.
+func inRange(t time.Time, from, to *time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && t.After(*to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"music-info/models"
+
+	"gorm.io/gorm"
+)
+
+// importSavePoint точка сохранения, в которой импортируется одна песня.
+const importSavePoint = "import_song"
+
+// ImportSongs сохраняет пакет песен одной транзакцией. Ошибка песни откатывает только ее точку сохранения,
+// после успешной вставки точка сохранения освобождается, чтобы не накапливать вложенные транзакции.
+// При dryRun песни только проверяются чтением, см. checkImport.
+func (r *GormRepository) ImportSongs(songs []models.MusicInfo, dryRun bool) ([]error, error) {
+	if dryRun {
+		return r.checkImport(songs)
+	}
+
+	results := make([]error, len(songs))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		batch := &GormRepository{db: tx}
+		for i := range songs {
+			if err := tx.SavePoint(importSavePoint).Error; err != nil {
+				return err
+			}
+
+			err := linkArtist(tx, &songs[i])
+			if err == nil {
+				err = tx.Create(&songs[i]).Error
+			}
+			if err == nil {
+				err = recordRevision(tx, songs[i].ID, songs[i].ChangedBy)
+			}
+			if err == nil {
+				if err := tx.Exec("RELEASE SAVEPOINT " + importSavePoint).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.RollbackTo(importSavePoint).Error; err != nil {
+				return err
+			}
+			songs[i].ID = 0
+			results[i] = batch.conflictError(err, songs[i].Group, songs[i].Song)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// checkImport проверяет пакет без записи: песня не должна совпадать с сохраненной песней или с предыдущей песней пакета.
+// Проверка только читает данные, поэтому не расходует значения последовательностей и не создает исполнителей.
+func (r *GormRepository) checkImport(songs []models.MusicInfo) ([]error, error) {
+	results := make([]error, len(songs))
+	var checked []*models.MusicInfo
+	for i := range songs {
+		songInfo := &songs[i]
+
+		var existing models.MusicInfo
+		result := r.db.Select(songColumns).Where(keyCondition, songInfo.Group, songInfo.Song).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			results[i] = &DuplicateSongError{ID: existing.ID, Group: existing.Group, Song: existing.Song}
+			continue
+		}
+
+		for _, previous := range checked {
+			if previous.SameKey(songInfo.Group, songInfo.Song) {
+				results[i] = &DuplicateSongError{Group: previous.Group, Song: previous.Song}
+				break
+			}
+		}
+		if results[i] == nil {
+			checked = append(checked, songInfo)
+		}
+	}
+
+	return results, nil
+}
+
+// ImportSongs сохраняет пакет песен. При dryRun песни пакета проверяются на совпадение друг с другом, но не сохраняются.
+func (r *MemoryRepository) ImportSongs(songs []models.MusicInfo, dryRun bool) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]error, len(songs))
+	var checked []*models.MusicInfo
+	for i := range songs {
+		songInfo := &songs[i]
+		if err := r.checkUnique(0, songInfo.Group, songInfo.Song); err != nil {
+			results[i] = err
+			continue
+		}
+
+		if !dryRun {
+			r.create(songInfo)
+			continue
+		}
+
+		for _, previous := range checked {
+			if previous.SameKey(songInfo.Group, songInfo.Song) {
+				results[i] = &DuplicateSongError{Group: previous.Group, Song: previous.Song}
+				break
+			}
+		}
+		if results[i] == nil {
+			checked = append(checked, songInfo)
+		}
+	}
+
+	return results, nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -18,6 +19,14 @@ func initTestDB(t *testing.T) {
 		t.Fatalf("Ошибка загрузки .env файла: %v", err)
 	}
 
+	if os.Getenv("DB_HOST") == "" {
+		// В CI тесты базы данных обязательны: пропуск скрыл бы, что они не выполнялись
+		if os.Getenv("CI") != "" {
+			t.Fatal("в CI тестовая база данных должна быть настроена переменной DB_HOST")
+		}
+		t.Skip("тестовая база данных не настроена, для проверки без базы данных используйте MemoryRepository")
+	}
+
 	DNSTest := "host=" + os.Getenv("DB_HOST") +
 		" user=" + os.Getenv("DB_USER") +
 		" dbname=testdb_test" +
@@ -42,6 +51,12 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	return tx
 }
 
+// testTables все таблицы схемы приложения вместе с журналом миграций.
+var testTables = []string{
+	"song_revisions", "synced_lyrics", "playlist_entries", "playlists", "album_tracks", "albums",
+	"song_tags", "music_infos", "tags", "artists", "schema_migrations",
+}
+
 // Удаление таблицы в тестовой БД
 func DropTableDB(t *testing.T, db *gorm.DB, table string) {
 
@@ -51,4 +66,15 @@ func DropTableDB(t *testing.T, db *gorm.DB, table string) {
 	} else {
 		log.Printf("Таблица %s успешно удалена\n", table)
 	}
+
+	// Исходная миграция не откатывается, поэтому схема удаляется целиком и следующий InitDB создает ее заново
+	resetTestSchema(t)
+}
+
+// resetTestSchema удаляет все таблицы приложения и журнал миграций.
+func resetTestSchema(t *testing.T) {
+	err := DB.Exec("DROP TABLE IF EXISTS " + strings.Join(testTables, ", ") + " CASCADE").Error
+	if err != nil {
+		t.Fatalf("Ошибка удаления схемы: %v", err)
+	}
 }
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LyricsRepository хранилище синхронизированных текстов песен.
+type LyricsRepository interface {
+	// SongLyrics возвращает синхронизированный текст песни. Если песни или текста нет, возвращает ошибку ErrNotFound.
+	SongLyrics(songID uint) (*models.SyncedLyrics, error)
+	// SaveSongLyrics сохраняет синхронизированный текст песни, заменяя прежний.
+	SaveSongLyrics(lyrics *models.SyncedLyrics) error
+	// DeleteSongLyrics удаляет синхронизированный текст песни. Если текста нет, возвращает ошибку ErrNotFound.
+	DeleteSongLyrics(songID uint) error
+}
+
+// notFoundLyrics возвращает ошибку отсутствия синхронизированного текста песни.
+func notFoundLyrics(songID uint) error {
+	return fmt.Errorf("%w: у песни id=%d нет синхронизированного текста", ErrNotFound, songID)
+}
+
+// SongLyrics возвращение синхронизированного текста песни.
+func (r *GormRepository) SongLyrics(songID uint) (*models.SyncedLyrics, error) {
+
+	if err := r.songExists(r.db, songID); err != nil {
+		return nil, err
+	}
+
+	var lyrics models.SyncedLyrics
+	result := r.db.Take(&lyrics, "song_id = ?", songID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, notFoundLyrics(songID)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &lyrics, nil
+}
+
+// SaveSongLyrics сохранение синхронизированного текста песни.
+func (r *GormRepository) SaveSongLyrics(lyrics *models.SyncedLyrics) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.songExists(tx, lyrics.SongID); err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "song_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"lrc", "updated_at"}),
+		}).Create(lyrics).Error
+	})
+}
+
+// DeleteSongLyrics удаление синхронизированного текста песни.
+func (r *GormRepository) DeleteSongLyrics(songID uint) error {
+
+	result := r.db.Delete(&models.SyncedLyrics{}, "song_id = ?", songID)
+	if result.Error != nil {
+		return fmt.Errorf("ошибка при удалении синхронизированного текста: %v", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundLyrics(songID)
+	}
+
+	return nil
+}
+
+// SongLyrics возвращает синхронизированный текст песни.
+func (r *MemoryRepository) SongLyrics(songID uint) (*models.SyncedLyrics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.songs[songID]; !ok {
+		return nil, notFoundByID(songID)
+	}
+	lyrics, ok := r.lyrics[songID]
+	if !ok {
+		return nil, notFoundLyrics(songID)
+	}
+
+	result := *lyrics
+	return &result, nil
+}
+
+// SaveSongLyrics сохраняет синхронизированный текст песни.
+func (r *MemoryRepository) SaveSongLyrics(lyrics *models.SyncedLyrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[lyrics.SongID]; !ok {
+		return notFoundByID(lyrics.SongID)
+	}
+
+	lyrics.UpdatedAt = time.Now()
+	stored := *lyrics
+	stored.Song = nil
+	r.lyrics[stored.SongID] = &stored
+
+	return nil
+}
+
+// DeleteSongLyrics удаляет синхронизированный текст песни.
+func (r *MemoryRepository) DeleteSongLyrics(songID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.lyrics[songID]; !ok {
+		return notFoundLyrics(songID)
+	}
+	delete(r.lyrics, songID)
+
+	return nil
+}
@@ -0,0 +1,347 @@
+package database
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"music-info/models"
+)
+
+// MemoryRepository потокобезопасное хранилище песен с историей правок, исполнителей, релизов, тегов, плейлистов и синхронизированных текстов в памяти.
+// Используется в тестах и повторяет поведение GormRepository.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	songs  map[uint]*models.MusicInfo
+	nextID uint
+	// trash удаленные песни, которые можно восстановить.
+	trash map[uint]*models.MusicInfo
+
+	artists      map[uint]*models.Artist
+	nextArtistID uint
+
+	albums      map[uint]*models.Album
+	nextAlbumID uint
+
+	tags      map[uint]*models.Tag
+	nextTagID uint
+	// songTags идентификаторы тегов каждой песни.
+	songTags map[uint]map[uint]bool
+
+	playlists      map[uint]*models.Playlist
+	nextPlaylistID uint
+	nextEntryID    uint
+
+	// lyrics синхронизированные тексты по идентификатору песни.
+	lyrics map[uint]*models.SyncedLyrics
+
+	// revisions версии каждой песни в порядке номеров.
+	revisions map[uint][]models.SongRevision
+}
+
+// NewMemoryRepository создает пустое хранилище песен с историей правок, исполнителей, релизов, тегов, плейлистов и синхронизированных текстов в памяти.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		songs:  make(map[uint]*models.MusicInfo),
+		nextID: 1,
+		trash:  make(map[uint]*models.MusicInfo),
+
+		artists:      make(map[uint]*models.Artist),
+		nextArtistID: 1,
+
+		albums:      make(map[uint]*models.Album),
+		nextAlbumID: 1,
+
+		tags:      make(map[uint]*models.Tag),
+		nextTagID: 1,
+		songTags:  make(map[uint]map[uint]bool),
+
+		playlists:      make(map[uint]*models.Playlist),
+		nextPlaylistID: 1,
+		nextEntryID:    1,
+
+		lyrics: make(map[uint]*models.SyncedLyrics),
+
+		revisions: make(map[uint][]models.SongRevision),
+	}
+}
+
+// Create сохраняет новую песню.
+func (r *MemoryRepository) Create(songInfo *models.MusicInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(0, songInfo.Group, songInfo.Song); err != nil {
+		return err
+	}
+
+	r.create(songInfo)
+
+	return nil
+}
+
+// Upsert создает песню или обновляет заполненные поля существующей песни с той же парой группы и названия.
+func (r *MemoryRepository) Upsert(songInfo *models.MusicInfo) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.songs {
+		if existing.SameKey(songInfo.Group, songInfo.Song) {
+			applyUpdates(existing, songInfo)
+			existing.UpdatedAt = time.Now()
+			r.recordRevision(existing, songInfo.ChangedBy)
+			*songInfo = *existing
+			return false, nil
+		}
+	}
+
+	r.create(songInfo)
+
+	return true, nil
+}
+
+// create сохраняет новую песню, присваивая ей идентификатор и исполнителя. Вызывается под блокировкой.
+func (r *MemoryRepository) create(songInfo *models.MusicInfo) {
+	r.linkArtist(songInfo)
+
+	now := time.Now()
+	songInfo.ID = r.nextID
+	songInfo.CreatedAt = now
+	songInfo.UpdatedAt = now
+	r.nextID++
+
+	stored := *songInfo
+	stored.ChangedBy = ""
+	r.songs[stored.ID] = &stored
+	r.recordRevision(&stored, songInfo.ChangedBy)
+}
+
+// checkUnique проверяет, что пара группы и названия не занята другой песней. Вызывается под блокировкой.
+func (r *MemoryRepository) checkUnique(id uint, group, song string) error {
+	for _, existing := range r.songs {
+		if existing.ID != id && existing.SameKey(group, song) {
+			return &DuplicateSongError{ID: existing.ID, Group: existing.Group, Song: existing.Song}
+		}
+	}
+	return nil
+}
+
+// Detail возвращает песню по полям Group и Song.
+func (r *MemoryRepository) Detail(group, song string) (*models.MusicInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range r.sortedIDs() {
+		songInfo := r.songs[id]
+		if songInfo.SameKey(group, song) {
+			result := *songInfo
+			return &result, nil
+		}
+	}
+
+	return nil, notFoundByKey(group, song)
+}
+
+// Update обновляет заполненные поля всех песен с указанными Group и Song.
+func (r *MemoryRepository) Update(group, song string, updateSong *models.MusicInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	updatedCount := 0
+	for _, songInfo := range r.songs {
+		if songInfo.SameKey(group, song) {
+			updatedCount++
+			updated := *songInfo
+			applyUpdates(&updated, updateSong)
+			if err := r.checkUnique(songInfo.ID, updated.Group, updated.Song); err != nil {
+				return err
+			}
+			if updateSong.Group != "" {
+				r.linkArtist(&updated)
+			}
+			*songInfo = updated
+			songInfo.UpdatedAt = now
+			r.recordRevision(songInfo, updateSong.ChangedBy)
+		}
+	}
+
+	if updatedCount == 0 {
+		return notFoundByKey(group, song)
+	}
+
+	return nil
+}
+
+// Delete переносит в корзину все песни с указанными Group и Song.
+func (r *MemoryRepository) Delete(group, song string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	deleted := 0
+	for id, songInfo := range r.songs {
+		if songInfo.SameKey(group, song) {
+			r.trashSong(id, now)
+			deleted++
+		}
+	}
+
+	if deleted == 0 {
+		return notFoundByKey(group, song)
+	}
+
+	return nil
+}
+
+// List возвращает страницу песен, удовлетворяющих фильтру, в указанном порядке.
+func (r *MemoryRepository) List(filter SongFilter, order SongSort, page, limit int) ([]models.MusicInfo, error) {
+	songs := r.filtered(filter)
+	sortSongs(songs, order)
+
+	offset := (page - 1) * limit
+	if offset >= len(songs) {
+		return []models.MusicInfo{}, nil
+	}
+	end := offset + limit
+	if end > len(songs) {
+		end = len(songs)
+	}
+
+	return songs[offset:end], nil
+}
+
+// DetailByID возвращает песню по идентификатору.
+func (r *MemoryRepository) DetailByID(id uint) (*models.MusicInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songInfo, ok := r.songs[id]
+	if !ok {
+		return nil, notFoundByID(id)
+	}
+
+	result := *songInfo
+	return &result, nil
+}
+
+// UpdateByID обновляет заполненные поля песни с указанным идентификатором.
+func (r *MemoryRepository) UpdateByID(id uint, updateSong *models.MusicInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	songInfo, ok := r.songs[id]
+	if !ok {
+		return notFoundByID(id)
+	}
+
+	updated := *songInfo
+	applyUpdates(&updated, updateSong)
+	if err := r.checkUnique(id, updated.Group, updated.Song); err != nil {
+		return err
+	}
+	if updateSong.Group != "" {
+		r.linkArtist(&updated)
+	}
+
+	*songInfo = updated
+	songInfo.UpdatedAt = time.Now()
+	r.recordRevision(songInfo, updateSong.ChangedBy)
+
+	return nil
+}
+
+// ReplaceByID заменяет все редактируемые поля песни с указанным идентификатором.
+func (r *MemoryRepository) ReplaceByID(id uint, replaceSong *models.MusicInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	songInfo, ok := r.songs[id]
+	if !ok {
+		return notFoundByID(id)
+	}
+
+	return r.replace(songInfo, replaceSong)
+}
+
+// PatchByID изменяет песню по идентификатору под блокировкой хранилища.
+func (r *MemoryRepository) PatchByID(id uint, patch func(songInfo *models.MusicInfo) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	songInfo, ok := r.songs[id]
+	if !ok {
+		return notFoundByID(id)
+	}
+
+	patched := *songInfo
+	if err := patch(&patched); err != nil {
+		return err
+	}
+
+	return r.replace(songInfo, &patched)
+}
+
+// replace заменяет редактируемые поля songInfo значениями replaceSong. Вызывается под блокировкой r.mu.
+func (r *MemoryRepository) replace(songInfo, replaceSong *models.MusicInfo) error {
+	if err := r.checkUnique(songInfo.ID, replaceSong.Group, replaceSong.Song); err != nil {
+		return err
+	}
+
+	songInfo.Group = replaceSong.Group
+	r.linkArtist(songInfo)
+	songInfo.Song = replaceSong.Song
+	songInfo.ReleaseDate = replaceSong.ReleaseDate
+	if !replaceSong.ReleaseDate.IsZero() {
+		songInfo.ReleaseDateLegacy = ""
+	}
+	songInfo.Text = replaceSong.Text
+	songInfo.Link = replaceSong.Link
+	songInfo.UpdatedAt = time.Now()
+	r.recordRevision(songInfo, replaceSong.ChangedBy)
+
+	return nil
+}
+
+// DeleteByID переносит песню в корзину по идентификатору.
+func (r *MemoryRepository) DeleteByID(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[id]; !ok {
+		return notFoundByID(id)
+	}
+	r.trashSong(id, time.Now())
+
+	return nil
+}
+
+// sortedIDs возвращает идентификаторы песен в порядке добавления.
+func (r *MemoryRepository) sortedIDs() []uint {
+	ids := make([]uint, 0, len(r.songs))
+	for id := range r.songs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// applyUpdates переносит заполненные поля updateSong в songInfo так же, как gorm Updates со структурой.
+func applyUpdates(songInfo, updateSong *models.MusicInfo) {
+	if updateSong.Group != "" {
+		songInfo.Group = updateSong.Group
+	}
+	if updateSong.Song != "" {
+		songInfo.Song = updateSong.Song
+	}
+	if !updateSong.ReleaseDate.IsZero() {
+		songInfo.ReleaseDate = updateSong.ReleaseDate
+		songInfo.ReleaseDateLegacy = ""
+	}
+	if updateSong.Text != "" {
+		songInfo.Text = updateSong.Text
+	}
+	if updateSong.Link != "" {
+		songInfo.Link = updateSong.Link
+	}
+}
@@ -0,0 +1,775 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryCreate(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+
+	// Проверяем метод
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+	assert.NotEqual(t, uint(0), songInfo.ID)
+
+	result, err := repo.Detail("Muse", "Supermassive Black Hole")
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.Text, result.Text)
+
+	// Изменение возвращенной записи не должно затрагивать хранилище
+	result.Text = ""
+	result, err = repo.Detail("Muse", "Supermassive Black Hole")
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.Text, result.Text)
+}
+
+func TestMemoryRepositoryUpdate(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	err = repo.Update("Muse", "Supermassive Black Hole", &models.MusicInfo{Text: "I thought I was a fool for no-one"})
+	assert.NoError(t, err)
+
+	result, err := repo.Detail("Muse", "Supermassive Black Hole")
+	assert.NoError(t, err)
+	assert.Equal(t, "I thought I was a fool for no-one", result.Text)
+	assert.Equal(t, songInfo.Link, result.Link)
+}
+
+func TestMemoryRepositoryKeyNormalization(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	err := repo.Create(&models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh"})
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	result, err := repo.Detail(" MUSE", "supermassive black hole ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", result.Group)
+
+	err = repo.Update("muse", "SUPERMASSIVE BLACK HOLE", &models.MusicInfo{Text: "I thought I was a fool"})
+	assert.NoError(t, err)
+	result, err = repo.Detail("Muse", "Supermassive Black Hole")
+	assert.NoError(t, err)
+	assert.Equal(t, "I thought I was a fool", result.Text)
+
+	err = repo.Delete(" muse ", "Supermassive black hole")
+	assert.NoError(t, err)
+	_, err = repo.Detail("Muse", "Supermassive Black Hole")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepositoryDelete(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	err := repo.Create(&models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh"})
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	err = repo.Delete("Muse", "Supermassive Black Hole")
+	assert.NoError(t, err)
+
+	_, err = repo.Detail("Muse", "Supermassive Black Hole")
+	assert.Error(t, err)
+
+	err = repo.Delete("Muse", "Supermassive Black Hole")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "запись не найдена")
+
+	err = repo.Update("Muse", "Supermassive Black Hole", &models.MusicInfo{Text: "Ooh"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepositoryList(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"},
+		{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"},
+		{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"},
+	} {
+		err := repo.Create(&songInfo)
+		assert.NoError(t, err)
+	}
+
+	// Проверяем метод
+	result, err := repo.List(SongFilter{Group: "Muse"}, DefaultSongSort, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "Supermassive Black Hole", result[0].Song)
+	assert.Equal(t, "Uprising", result[1].Song)
+
+	result, err = repo.List(SongFilter{}, DefaultSongSort, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "Queen", result[0].Group)
+
+	result, err = repo.List(SongFilter{}, DefaultSongSort, 3, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestMemoryRepositorySearch(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight"},
+		{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"},
+	} {
+		err := repo.Create(&songInfo)
+		assert.NoError(t, err)
+	}
+
+	// Проверяем метод
+	result, err := repo.Search("Soul ooh", "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "Supermassive Black Hole", result[0].Song)
+	assert.Equal(t, 1, result[0].Verse)
+	assert.Equal(t, "<b>Ooh</b> baby, don&#39;t you know I suffer?", result[0].Snippet)
+
+	result, err = repo.Search("muse", "en", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 0, result[0].Verse)
+
+	_, err = repo.Search("muse", "de", 1, 10)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestMemoryRepositorySearchEscapesSnippet(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "<script>alert('soul')</script> & soul"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем метод
+	result, err := repo.Search("soul", "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "&lt;script&gt;alert(&#39;<b>soul</b>&#39;)&lt;/script&gt; &amp; <b>soul</b>", result[0].Snippet)
+}
+
+func TestEscapeSnippet(t *testing.T) {
+
+	// Проверяем метод
+	snippet := escapeSnippet("<img src=x onerror=alert(1)> " + headlineStart + "soul" + headlineStop + " … <b>")
+	assert.Equal(t, "&lt;img src=x onerror=alert(1)&gt; <b>soul</b> … &lt;b&gt;", snippet)
+}
+
+func TestMemoryRepositoryArtists(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	muse := models.Artist{Name: "Muse", Aliases: []string{"Muse UK"}}
+	assert.NoError(t, repo.CreateArtist(&muse))
+
+	uprising := models.MusicInfo{Group: "muse uk", Song: "Uprising", Text: "Paranoia is in bloom"}
+	assert.NoError(t, repo.Create(&uprising))
+	hysteria := models.MusicInfo{Group: "Muse", Song: "Hysteria", Text: "It's bugging me"}
+	assert.NoError(t, repo.Create(&hysteria))
+	queen := models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	assert.NoError(t, repo.Create(&queen))
+
+	// Проверяем связывание песен с исполнителем по имени и псевдониму
+	assert.Equal(t, muse.ID, *uprising.ArtistID)
+	assert.Equal(t, muse.ID, *hysteria.ArtistID)
+	assert.NotEqual(t, muse.ID, *queen.ArtistID)
+
+	artists, err := repo.ListArtists("", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(artists))
+	assert.Equal(t, "Queen", artists[1].Name)
+
+	err = repo.CreateArtist(&models.Artist{Name: " MUSE"})
+	var duplicate *DuplicateArtistError
+	assert.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, muse.ID, duplicate.ID)
+
+	// Переименование исполнителя меняет группу его песен
+	assert.NoError(t, repo.ReplaceArtist(muse.ID, &models.Artist{Name: "Muse (band)", Aliases: []string{}, Members: []string{}}))
+	songs, err := repo.List(SongFilter{ArtistID: &muse.ID}, DefaultSongSort, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(songs))
+	for _, songInfo := range songs {
+		assert.Equal(t, "Muse (band)", songInfo.Group)
+	}
+
+	err = repo.ReplaceArtist(muse.ID, &models.Artist{Name: "Queen"})
+	assert.ErrorAs(t, err, &duplicate)
+
+	// Исполнителя с песнями удалить нельзя, даже если песни в корзине
+	assert.ErrorIs(t, repo.DeleteArtist(muse.ID), ErrConflict)
+	assert.NoError(t, repo.DeleteByID(queen.ID))
+	assert.ErrorIs(t, repo.DeleteArtist(*queen.ArtistID), ErrConflict)
+	assert.NoError(t, repo.PurgeSong(queen.ID))
+	assert.NoError(t, repo.DeleteArtist(*queen.ArtistID))
+	assert.ErrorIs(t, repo.DeleteArtist(*queen.ArtistID), ErrNotFound)
+}
+
+func TestMemoryRepositoryAlbums(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	first := models.MusicInfo{Group: "Muse", Song: "Take a Bow", Text: "Corrupt, you corrupt"}
+	assert.NoError(t, repo.Create(&first))
+	second := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&second))
+
+	album := models.Album{
+		Title:    "Black Holes and Revelations",
+		ArtistID: *first.ArtistID,
+		Type:     models.AlbumTypeLP,
+		Tracks:   []models.AlbumTrack{{SongID: second.ID, Disc: 1, Track: 2}, {SongID: first.ID, Disc: 1, Track: 1}},
+	}
+	assert.NoError(t, repo.CreateAlbum(&album))
+
+	// Проверяем методы
+	result, err := repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Tracks))
+	assert.Equal(t, "Take a Bow", result.Tracks[0].Song.Song)
+	assert.Equal(t, "Starlight", result.Tracks[1].Song.Song)
+
+	assert.NoError(t, repo.ReplaceTracks(album.ID, []models.AlbumTrack{{SongID: second.ID, Disc: 1, Track: 1}, {SongID: first.ID, Disc: 1, Track: 2}}))
+	result, err = repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Starlight", result.Tracks[0].Song.Song)
+
+	// Треки удаленных песен не возвращаются
+	assert.NoError(t, repo.DeleteByID(second.ID))
+	result, err = repo.AlbumByID(album.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Tracks))
+
+	assert.ErrorIs(t, repo.ReplaceTracks(album.ID, []models.AlbumTrack{{SongID: second.ID, Disc: 1, Track: 1}}), ErrValidation)
+	assert.ErrorIs(t, repo.ReplaceTracks(999, nil), ErrNotFound)
+	assert.ErrorIs(t, repo.CreateAlbum(&models.Album{Title: "Unknown", ArtistID: 999, Type: models.AlbumTypeEP}), ErrValidation)
+
+	// Исполнителя с релизами удалить нельзя
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	assert.ErrorIs(t, repo.DeleteArtist(album.ArtistID), ErrConflict)
+}
+
+func TestMemoryRepositoryTags(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	uprising := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"}
+	assert.NoError(t, repo.Create(&uprising))
+	hysteria := models.MusicInfo{Group: "Muse", Song: "Hysteria", Text: "It's bugging me"}
+	assert.NoError(t, repo.Create(&hysteria))
+	queen := models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	assert.NoError(t, repo.Create(&queen))
+
+	assert.NoError(t, repo.AddSongTags(uprising.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}, {Kind: models.TagKindMood, Name: "Angry"}}))
+	assert.NoError(t, repo.AddSongTags(hysteria.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "rock"}}))
+	assert.NoError(t, repo.AddSongTags(queen.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}, {Kind: models.TagKindGenre, Name: "Opera"}}))
+
+	// Проверяем методы
+	tags, err := repo.SongTags(uprising.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tags))
+	assert.Equal(t, models.TagKindGenre, tags[0].Kind)
+
+	songs, err := repo.List(SongFilter{Tags: []models.TagRef{{Kind: models.TagKindGenre, Name: "ROCK"}}}, DefaultSongSort, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(songs))
+
+	songs, err = repo.List(SongFilter{Tags: []models.TagRef{{Name: "rock"}, {Name: "angry"}}}, DefaultSongSort, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(songs))
+	assert.Equal(t, "Uprising", songs[0].Song)
+
+	facets, err := repo.TagFacets(SongFilter{GroupEq: "Muse"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TagFacet{{Tag: tags[0], Count: 2}, {Tag: tags[1], Count: 1}}, facets)
+
+	facets, err = repo.TagFacets(SongFilter{}, models.TagKindGenre)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(facets))
+	assert.Equal(t, int64(3), facets[0].Count)
+	assert.Equal(t, "Opera", facets[1].Name)
+
+	assert.NoError(t, repo.RemoveSongTag(uprising.ID, tags[1].ID))
+	assert.ErrorIs(t, repo.RemoveSongTag(uprising.ID, tags[1].ID), ErrNotFound)
+	assert.ErrorIs(t, repo.AddSongTags(999, []models.Tag{{Kind: models.TagKindTag, Name: "x"}}), ErrNotFound)
+}
+
+// playlistSongs возвращает названия песен записей плейлиста по порядку.
+func playlistSongs(playlist *models.Playlist) []string {
+	var songs []string
+	for _, entry := range playlist.Entries {
+		songs = append(songs, entry.SongName)
+	}
+	return songs
+}
+
+func TestMemoryRepositoryPlaylists(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	var ids []uint
+	for _, song := range []string{"Uprising", "Hysteria", "Starlight"} {
+		songInfo := models.MusicInfo{Group: "Muse", Song: song, Text: song}
+		assert.NoError(t, repo.Create(&songInfo))
+		ids = append(ids, songInfo.ID)
+	}
+
+	playlist := models.Playlist{Name: "Muse", SongIDs: []uint{ids[0], ids[1]}}
+	assert.NoError(t, repo.CreatePlaylist(&playlist))
+
+	// Проверяем методы
+	entry, err := repo.InsertPlaylistEntry(playlist.ID, ids[2], 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entry.Position)
+
+	result, err := repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Starlight", "Uprising", "Hysteria"}, playlistSongs(result))
+
+	_, err = repo.InsertPlaylistEntry(playlist.ID, ids[0], 0)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.MovePlaylistEntry(playlist.ID, entry.ID, 4))
+	result, err = repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Uprising", "Hysteria", "Uprising", "Starlight"}, playlistSongs(result))
+	for i, e := range result.Entries {
+		assert.Equal(t, i+1, e.Position)
+	}
+
+	assert.NoError(t, repo.RemovePlaylistEntry(playlist.ID, result.Entries[0].ID))
+	result, err = repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hysteria", "Uprising", "Starlight"}, playlistSongs(result))
+	assert.Equal(t, 1, result.Entries[0].Position)
+
+	// Записи удаленной песни остаются недоступными
+	assert.NoError(t, repo.DeleteByID(ids[2]))
+	result, err = repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.False(t, result.Entries[2].Available)
+	assert.Nil(t, result.Entries[2].Song)
+	assert.True(t, result.Entries[0].Available)
+
+	_, err = repo.InsertPlaylistEntry(playlist.ID, ids[2], 0)
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = repo.InsertPlaylistEntry(playlist.ID, ids[0], 5)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, repo.MovePlaylistEntry(playlist.ID, entry.ID, 0), ErrValidation)
+	assert.ErrorIs(t, repo.MovePlaylistEntry(playlist.ID, 999, 1), ErrNotFound)
+	assert.ErrorIs(t, repo.RemovePlaylistEntry(999, entry.ID), ErrNotFound)
+	assert.ErrorIs(t, repo.CreatePlaylist(&models.Playlist{Name: "Missing", SongIDs: []uint{999}}), ErrValidation)
+}
+
+func TestMemoryRepositoryImportSongs(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	existing := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	assert.NoError(t, repo.Create(&existing))
+	batch := func() []models.MusicInfo {
+		return []models.MusicInfo{
+			{Group: "Muse", Song: "Starlight", Text: "Far away"},
+			{Group: " muse", Song: "UPRISING", Text: "Paranoia"},
+			{Group: "Muse", Song: "starlight ", Text: "Far away"},
+		}
+	}
+
+	// Проверяем метод
+	songs := batch()
+	results, err := repo.ImportSongs(songs, true)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0])
+	assert.Equal(t, &DuplicateSongError{ID: existing.ID, Group: "Muse", Song: "Uprising"}, results[1])
+	assert.ErrorIs(t, results[2], ErrConflict)
+	assert.Zero(t, songs[0].ID)
+	count, err := repo.Count(SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	songs = batch()
+	results, err = repo.ImportSongs(songs, false)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0])
+	assert.NotZero(t, songs[0].ID)
+	assert.NotNil(t, songs[0].ArtistID)
+	assert.Equal(t, &DuplicateSongError{ID: songs[0].ID, Group: "Muse", Song: "Starlight"}, results[2])
+	count, err = repo.Count(SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMemoryRepositoryConcurrentAccess(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	// Проверяем одновременную запись и чтение
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			song := fmt.Sprintf("Song %d", i)
+			assert.NoError(t, repo.Create(&models.MusicInfo{Group: "Muse", Song: song, Text: "Ooh"}))
+			_, err := repo.Detail("Muse", song)
+			assert.NoError(t, err)
+			_, err = repo.List(SongFilter{Group: "Muse"}, DefaultSongSort, 1, 10)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	result, err := repo.List(SongFilter{Group: "Muse"}, DefaultSongSort, 1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, len(result))
+}
+
+func TestMemoryRepositoryLegacyReleaseDate(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia", ReleaseDateLegacy: "осень 2009"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем, что изменение без даты выпуска сохраняет нераспознанную дату
+	assert.NoError(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{Link: "https://example.com/muse"}))
+	result, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.LegacyReleaseDate("осень 2009"), result.ReleaseDateLegacy)
+
+	// Проверяем, что запись даты выпуска очищает нераспознанную дату
+	assert.NoError(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{ReleaseDate: models.MustParseReleaseDate("2009")}))
+	result, err = repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, result.ReleaseDateLegacy)
+	assert.Equal(t, "2009", result.ReleaseDate.String())
+}
+
+func TestMemoryRepositoryPatchByID(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"}
+	assert.NoError(t, repo.Create(&songInfo))
+	patchErr := errors.New("неверный документ")
+
+	// Проверяем метод
+	err := repo.PatchByID(songInfo.ID, func(current *models.MusicInfo) error {
+		assert.Equal(t, "Ooh baby", current.Text)
+		current.Link = "https://example.com/muse"
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = repo.PatchByID(songInfo.ID, func(current *models.MusicInfo) error {
+		current.Text = ""
+		return patchErr
+	})
+	assert.ErrorIs(t, err, patchErr)
+
+	result, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/muse", result.Link)
+	assert.Equal(t, "Ooh baby", result.Text)
+
+	err = repo.PatchByID(42, func(current *models.MusicInfo) error { return nil })
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepositoryByID(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+
+	// Проверяем методы
+	result, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.Song, result.Song)
+
+	err = repo.UpdateByID(songInfo.ID, &models.MusicInfo{Text: "Ooh"})
+	assert.NoError(t, err)
+
+	err = repo.ReplaceByID(songInfo.ID, &models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"})
+	assert.NoError(t, err)
+
+	result, err = repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Uprising", result.Song)
+	assert.Equal(t, "", result.Link)
+
+	err = repo.DeleteByID(songInfo.ID)
+	assert.NoError(t, err)
+
+	_, err = repo.DetailByID(songInfo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{Text: "Ooh"}), ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteByID(songInfo.ID), ErrNotFound)
+}
+
+func TestMemoryRepositoryUnique(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"}
+	err := repo.Create(&songInfo)
+	assert.NoError(t, err)
+
+	other := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"}
+	err = repo.Create(&other)
+	assert.NoError(t, err)
+
+	// Проверяем создание дубликата
+	var duplicate *DuplicateSongError
+	err = repo.Create(&models.MusicInfo{Group: "muse ", Song: " Supermassive black hole", Text: "Ooh"})
+	assert.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, songInfo.ID, duplicate.ID)
+
+	// Проверяем переименование в занятую пару
+	err = repo.UpdateByID(other.ID, &models.MusicInfo{Song: "Supermassive Black Hole"})
+	assert.ErrorAs(t, err, &duplicate)
+
+	err = repo.ReplaceByID(other.ID, &models.MusicInfo{Group: "MUSE", Song: "Supermassive Black Hole", Text: "Ooh"})
+	assert.ErrorAs(t, err, &duplicate)
+
+	err = repo.Update("Muse", "Uprising", &models.MusicInfo{Song: "Supermassive Black Hole"})
+	assert.ErrorAs(t, err, &duplicate)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	result, err := repo.DetailByID(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Uprising", result.Song)
+
+	// После удаления пара снова свободна
+	err = repo.DeleteByID(songInfo.ID)
+	assert.NoError(t, err)
+	err = repo.Create(&models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh"})
+	assert.NoError(t, err)
+}
+
+func TestMemoryRepositoryUpsert(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby"}
+	created, err := repo.Upsert(&songInfo)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	// Проверяем метод
+	update := models.MusicInfo{Group: "muse", Song: "supermassive black hole", Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
+	created, err = repo.Upsert(&update)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, songInfo.ID, update.ID)
+	assert.Equal(t, "Ooh baby", update.Text)
+	assert.Equal(t, "https://www.youtube.com/watch?v=Xsp3_a-PMTw", update.Link)
+}
+
+func TestMemoryRepositoryLyrics(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем метод
+	_, err := repo.SongLyrics(songInfo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: songInfo.ID, LRC: "[00:01.00]Paranoia\n"}))
+	assert.NoError(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: songInfo.ID, LRC: "[00:02.00]Paranoia\n"}))
+	lyrics, err := repo.SongLyrics(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:02.00]Paranoia\n", lyrics.LRC)
+	assert.False(t, lyrics.UpdatedAt.IsZero())
+
+	assert.ErrorIs(t, repo.SaveSongLyrics(&models.SyncedLyrics{SongID: 999, LRC: "[00:01.00]x\n"}), ErrNotFound)
+
+	assert.NoError(t, repo.DeleteSongLyrics(songInfo.ID))
+	assert.ErrorIs(t, repo.DeleteSongLyrics(songInfo.ID), ErrNotFound)
+}
+
+func TestMemoryRepositoryRevisions(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in blom", ChangedBy: "author"}
+	assert.NoError(t, repo.Create(&songInfo))
+
+	// Проверяем метод
+	assert.NoError(t, repo.Update("Muse", "Uprising", &models.MusicInfo{Text: "Paranoia is in bloom", ChangedBy: "editor"}))
+	assert.NoError(t, repo.UpdateByID(songInfo.ID, &models.MusicInfo{Text: "Paranoia is in bloom"}))
+	assert.NoError(t, repo.ReplaceArtist(*songInfo.ArtistID, &models.Artist{Name: "MUSE", ChangedBy: "admin"}))
+
+	revisions, err := repo.SongRevisions(songInfo.ID, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, 3, revisions[0].Version)
+	assert.Equal(t, "admin", revisions[0].Author)
+	assert.Equal(t, []string{"group"}, revisions[0].Fields)
+	assert.Equal(t, []string{"text"}, revisions[1].Fields)
+	assert.Equal(t, "editor", revisions[1].Author)
+	assert.Equal(t, []string{"group", "song", "text"}, revisions[2].Fields)
+
+	page, err := repo.SongRevisions(songInfo.ID, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, 1, page[0].Version)
+
+	revision, err := repo.SongRevision(songInfo.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Paranoia is in blom", revision.Text)
+	assert.Equal(t, "author", revision.Author)
+
+	_, err = repo.SongRevision(songInfo.ID, 4)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.SongRevisions(999, 1, 10)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	stored, err := repo.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, stored.ChangedBy)
+}
+
+func TestMemoryRepositoryPurgeLinkedSong(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	first := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	second := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&first))
+	assert.NoError(t, repo.Create(&second))
+
+	album := models.Album{
+		Title:    "The Resistance",
+		ArtistID: *first.ArtistID,
+		Type:     models.AlbumTypeLP,
+		Tracks:   []models.AlbumTrack{{SongID: first.ID, Disc: 1, Track: 1}, {SongID: second.ID, Disc: 1, Track: 2}},
+	}
+	assert.NoError(t, repo.CreateAlbum(&album))
+	playlist := models.Playlist{Name: "Muse", SongIDs: []uint{first.ID, second.ID}}
+	assert.NoError(t, repo.CreatePlaylist(&playlist))
+
+	// Проверяем метод
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	assert.NoError(t, repo.PurgeSong(first.ID))
+
+	tracks := repo.albums[album.ID].Tracks
+	assert.Equal(t, 1, len(tracks))
+	assert.Equal(t, second.ID, tracks[0].SongID)
+	result, err := repo.PlaylistByID(playlist.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Entries))
+	assert.Nil(t, result.Entries[0].SongID)
+	assert.Equal(t, "Uprising", result.Entries[0].SongName)
+	assert.False(t, result.Entries[0].Available)
+	assert.Equal(t, second.ID, *result.Entries[1].SongID)
+}
+
+func TestMemoryRepositoryTrash(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	first := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	second := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&first))
+	assert.NoError(t, repo.Create(&second))
+	assert.NoError(t, repo.AddSongTags(first.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}}))
+
+	// Проверяем метод
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	assert.NoError(t, repo.Delete("Muse", "Starlight"))
+	_, err := repo.DetailByID(first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	songs, err := repo.DeletedSongs(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(songs))
+	assert.True(t, songs[0].DeletedAt.Valid)
+	assert.ErrorIs(t, repo.RestoreSong(999), ErrNotFound)
+
+	assert.NoError(t, repo.RestoreSong(first.ID))
+	restored, err := repo.DetailByID(first.ID)
+	assert.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	tags, err := repo.SongTags(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tags))
+	assert.ErrorIs(t, repo.RestoreSong(first.ID), ErrNotFound)
+
+	// Пара группы и названия удаленной песни свободна, но мешает ее восстановлению
+	duplicate := models.MusicInfo{Group: "muse", Song: "starlight", Text: "Far away"}
+	assert.NoError(t, repo.Create(&duplicate))
+	assert.ErrorIs(t, repo.RestoreSong(second.ID), ErrConflict)
+
+	assert.ErrorIs(t, repo.PurgeSong(first.ID), ErrNotFound)
+	assert.NoError(t, repo.PurgeSong(second.ID))
+	assert.ErrorIs(t, repo.PurgeSong(second.ID), ErrNotFound)
+
+	assert.NoError(t, repo.DeleteByID(first.ID))
+	purged, err := repo.PurgeDeletedSongs(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = repo.PurgeDeletedSongs(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	songs, err = repo.DeletedSongs(1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, songs)
+}
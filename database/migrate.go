@@ -0,0 +1,335 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles SQL-скрипты миграций схемы, встроенные в исполняемый файл.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey ключ рекомендательной блокировки PostgreSQL, под которой выполняются миграции.
+// Второй экземпляр, запущенный одновременно, дожидается завершения первого.
+const migrationLockKey int64 = 4_726_150_918_403
+
+// migrationFileName имя файла миграции: <версия>_<название>.up.sql или <версия>_<название>.down.sql.
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration версия схемы базы данных со скриптами применения и отката.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus состояние миграции в базе данных.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Missing миграция применена, но ее скрипта нет в этой версии приложения.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// schemaMigration запись таблицы schema_migrations.
+type schemaMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// LoadMigrations читает миграции из корня fsys, упорядочивая их по возрастанию версии.
+// У каждой версии должны быть оба скрипта, up и down.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("неверное имя файла миграции %s: ожидается <версия>_<название>.up.sql или <версия>_<название>.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("неверная версия миграции в имени файла %s", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("у миграции %d два названия: %s и %s", version, migration.Name, match[2])
+		}
+
+		script := &migration.Up
+		if match[3] == "down" {
+			script = &migration.Down
+		}
+		if *script != "" {
+			return nil, fmt.Errorf("повторный скрипт %s миграции %d", match[3], version)
+		}
+		*script = string(data)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("у миграции %d нет скрипта up", migration.Version)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("у миграции %d нет скрипта down", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции схемы, записывая примененные версии в таблицу schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator создает мигратор со встроенными миграциями.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Status возвращает состояние всех известных и примененных миграций по возрастанию версии.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &record.AppliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		for _, record := range applied {
+			if m.find(record.Version) == nil {
+				statuses = append(statuses, MigrationStatus{Version: record.Version, Name: record.Name, Applied: true, AppliedAt: &record.AppliedAt, Missing: true})
+			}
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// Up применяет все непримененные миграции и возвращает их.
+func (m *Migrator) Up() ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает steps последних примененных миграций и возвращает их в порядке отката.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, NewValidationError(errors.New("количество откатываемых миграций должно быть больше 0"))
+	}
+
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		versions := appliedVersions(applied)
+		for i := len(versions) - 1; i >= 0 && len(done) < steps; i-- {
+			migration, err := m.rollback(conn, versions[i])
+			if err != nil {
+				return err
+			}
+			done = append(done, *migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To приводит схему к версии version: применяет миграции до нее включительно и откатывает более поздние.
+// Версия 0 откатывает все миграции. Возвращает примененные и откатанные миграции в порядке выполнения.
+func (m *Migrator) To(version int64) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, NewValidationError(fmt.Errorf("миграция %d не найдена", version))
+	}
+
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		versions := appliedVersions(applied)
+		for i := len(versions) - 1; i >= 0 && versions[i] > version; i-- {
+			migration, err := m.rollback(conn, versions[i])
+			if err != nil {
+				return err
+			}
+			done = append(done, *migration)
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// locked выполняет fn на одном соединении под рекомендательной блокировкой миграций,
+// передавая примененные миграции, прочитанные после получения блокировки.
+func (m *Migrator) locked(fn func(conn *gorm.DB, applied map[int64]schemaMigration) error) error {
+	return m.db.Connection(func(conn *gorm.DB) (err error) {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("ошибка получения блокировки миграций: %w", err)
+		}
+		defer func() {
+			if unlockErr := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; unlockErr != nil && err == nil {
+				err = fmt.Errorf("ошибка снятия блокировки миграций: %w", unlockErr)
+			}
+		}()
+
+		err = conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`).Error
+		if err != nil {
+			return fmt.Errorf("ошибка создания таблицы schema_migrations: %w", err)
+		}
+
+		var records []schemaMigration
+		if err := conn.Table("schema_migrations").Order("version").Find(&records).Error; err != nil {
+			return err
+		}
+		applied := make(map[int64]schemaMigration, len(records))
+		for _, record := range records {
+			applied[record.Version] = record
+		}
+
+		return fn(conn, applied)
+	})
+}
+
+// apply применяет миграцию и записывает ее версию в одной транзакции.
+func (m *Migrator) apply(conn *gorm.DB, migration Migration) error {
+	log.Printf("Применение миграции %d_%s\n", migration.Version, migration.Name)
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if !emptyScript(migration.Up) {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name).Error
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка применения миграции %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// rollback откатывает примененную миграцию и удаляет ее версию в одной транзакции.
+func (m *Migrator) rollback(conn *gorm.DB, version int64) (*Migration, error) {
+	migration := m.find(version)
+	if migration == nil {
+		return nil, fmt.Errorf("нет скрипта отката примененной миграции %d", version)
+	}
+
+	log.Printf("Откат миграции %d_%s\n", migration.Version, migration.Name)
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if !emptyScript(migration.Down) {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отката миграции %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return migration, nil
+}
+
+// find возвращает известную миграцию по версии или nil.
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// appliedVersions возвращает версии примененных миграций по возрастанию.
+func appliedVersions(applied map[int64]schemaMigration) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// emptyScript сообщает, что скрипт не содержит ничего, кроме пробелов и строковых комментариев.
+func emptyScript(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
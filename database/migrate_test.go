@@ -0,0 +1,213 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+
+	// Создаем тестовые данные
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX idx ON t (c);")},
+		"0002_add_index.down.sql": {Data: []byte("DROP INDEX idx;")},
+		"0001_baseline.up.sql":    {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_baseline.down.sql":  {Data: []byte("DROP TABLE t;")},
+	}
+
+	// Проверяем метод
+	migrations, err := LoadMigrations(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "baseline", Up: "CREATE TABLE t (c int);", Down: "DROP TABLE t;"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX idx ON t (c);", Down: "DROP INDEX idx;"},
+	}, migrations)
+}
+
+func TestLoadMigrationsInvalid(t *testing.T) {
+
+	// Создаем тестовые данные
+	cases := map[string]fstest.MapFS{
+		"нет down": {
+			"0001_baseline.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"нет up": {
+			"0001_baseline.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"повтор версии": {
+			"0001_baseline.up.sql":    {Data: []byte("SELECT 1;")},
+			"0001_baseline.down.sql":  {Data: []byte("SELECT 1;")},
+			"1_other.up.sql":          {Data: []byte("SELECT 1;")},
+			"0001_other.down.sql":     {Data: []byte("SELECT 1;")},
+			"0002_add_index.up.sql":   {Data: []byte("SELECT 1;")},
+			"0002_add_index.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"неверное имя": {
+			"baseline.sql": {Data: []byte("SELECT 1;")},
+		},
+		"нулевая версия": {
+			"0000_baseline.up.sql":   {Data: []byte("SELECT 1;")},
+			"0000_baseline.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	// Проверяем метод
+	for name, fsys := range cases {
+		_, err := LoadMigrations(fsys)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+
+	// Проверяем метод
+	migrator, err := NewMigrator(nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrator.migrations)
+	assert.Equal(t, int64(1), migrator.migrations[0].Version)
+	assert.Equal(t, "baseline", migrator.migrations[0].Name)
+}
+
+func TestEmptyScript(t *testing.T) {
+
+	// Проверяем метод
+	assert.True(t, emptyScript(""))
+	assert.True(t, emptyScript("-- только комментарий\n\n  -- еще один\n"))
+	assert.False(t, emptyScript("-- комментарий\nSELECT 1;"))
+}
+
+func TestMigrator(t *testing.T) {
+
+	// Создаем тестовые данные
+	initTestDB(t)
+	migrator, err := NewMigrator(DB)
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.NotNil(t, status.AppliedAt)
+	}
+
+	done, err := migrator.Down(1)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, statuses[len(statuses)-1].Version, done[0].Version)
+
+	done, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+
+	done, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	// Исходная миграция не откатывается: остальные откатываются, таблица песен остается
+	done, err = migrator.To(0)
+	assert.Error(t, err)
+	assert.Len(t, done, len(statuses)-1)
+	assert.True(t, DB.Migrator().HasTable("music_infos"))
+	assert.False(t, DB.Migrator().HasTable("artists"))
+
+	done, err = migrator.To(statuses[len(statuses)-1].Version)
+	assert.NoError(t, err)
+	assert.Len(t, done, len(statuses)-1)
+	assert.True(t, DB.Migrator().HasTable("artists"))
+
+	_, err = migrator.To(999999)
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = migrator.Down(0)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+// baselineMusicInfo модель песни до появления миграций, таблицу которой создавал AutoMigrate.
+type baselineMusicInfo struct {
+	gorm.Model
+	Group       string `gorm:"not null"`
+	Song        string `gorm:"not null"`
+	ReleaseDate string
+	Text        string `gorm:"not null"`
+	Link        string
+}
+
+func (baselineMusicInfo) TableName() string { return "music_infos" }
+
+func TestMigrateFromBaseline(t *testing.T) {
+
+	// Создаем тестовые данные
+	initTestDB(t)
+	resetTestSchema(t)
+	defer resetTestSchema(t)
+	assert.NoError(t, DB.AutoMigrate(&baselineMusicInfo{}))
+	songs := []baselineMusicInfo{
+		{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: "16.07.2006", Text: "Ooh baby"},
+		{Group: " muse ", Song: "Starlight", ReleaseDate: "2006", Text: "Far away"},
+		{Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: "осень 1975", Text: "Is this the real life"},
+		{Group: "MUSE", Song: " starlight", Text: "Far away"},
+		{Group: "Muse", Song: "Knights of Cydonia", ReleaseDate: "31.02.2006", Text: "Come ride with me"},
+		{Group: "Muse", Song: "Map of the Problematique", ReleaseDate: "13.2006", Text: "Fear and panic in the air"},
+	}
+	assert.NoError(t, DB.Create(&songs).Error)
+	migrator, err := NewMigrator(DB)
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	done, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.Len(t, done, len(migrator.migrations))
+
+	type row struct {
+		ID                uint
+		ReleaseDate       *time.Time
+		ReleasePrecision  *string
+		ReleaseDateLegacy *string
+		ArtistID          *uint
+		DeletedAt         *time.Time
+	}
+	var rows []row
+	assert.NoError(t, DB.Table("music_infos").Order("id").Find(&rows).Error)
+	assert.Len(t, rows, 6)
+
+	assert.Equal(t, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC), rows[0].ReleaseDate.UTC())
+	assert.Equal(t, "day", *rows[0].ReleasePrecision)
+	assert.Nil(t, rows[0].ReleaseDateLegacy)
+	assert.Equal(t, "year", *rows[1].ReleasePrecision)
+	assert.Nil(t, rows[2].ReleaseDate)
+	assert.Equal(t, "осень 1975", *rows[2].ReleaseDateLegacy)
+
+	// Даты подходящего вида, но несуществующие, сохраняются как нераспознанные
+	for i, value := range map[int]string{4: "31.02.2006", 5: "13.2006"} {
+		assert.Nil(t, rows[i].ReleaseDate)
+		assert.Nil(t, rows[i].ReleasePrecision)
+		assert.Equal(t, value, *rows[i].ReleaseDateLegacy)
+	}
+
+	assert.NotNil(t, rows[0].ArtistID)
+	assert.Equal(t, rows[0].ArtistID, rows[1].ArtistID)
+	assert.NotEqual(t, rows[0].ArtistID, rows[2].ArtistID)
+
+	// Дубликат песни перенесен в корзину, первая из совпадающих песен осталась
+	assert.Nil(t, rows[1].DeletedAt)
+	assert.NotNil(t, rows[3].DeletedAt)
+
+	// Откат до исходной схемы сохраняет песни и строковую дату выпуска
+	_, err = migrator.Down(len(migrator.migrations) - 1)
+	assert.NoError(t, err)
+	var restored []baselineMusicInfo
+	assert.NoError(t, DB.Order("id").Find(&restored).Error)
+	assert.Len(t, restored, 5)
+	assert.Equal(t, "16.07.2006", restored[0].ReleaseDate)
+	assert.Equal(t, "2006", restored[1].ReleaseDate)
+	assert.Equal(t, "осень 1975", restored[2].ReleaseDate)
+
+	_, err = migrator.Down(1)
+	assert.Error(t, err)
+	assert.True(t, DB.Migrator().HasTable("music_infos"))
+}
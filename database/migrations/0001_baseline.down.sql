@@ -0,0 +1,7 @@
+-- Таблица песен могла быть создана до появления миграций и содержит данные пользователей,
+-- поэтому исходная миграция не откатывается.
+DO $$
+BEGIN
+	RAISE EXCEPTION 'исходную схему нельзя откатить: таблица music_infos содержит данные, созданные до миграций';
+END
+$$;
@@ -0,0 +1,19 @@
+-- Исходная схема приложения: таблица песен в том виде, в котором ее создавал AutoMigrate до появления миграций.
+-- Операции идемпотентны, поэтому база, созданная прежними версиями приложения, принимает миграцию без изменений.
+CREATE TABLE IF NOT EXISTS music_infos (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	"group" text NOT NULL,
+	song text NOT NULL,
+	release_date text,
+	text text NOT NULL,
+	link text
+);
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS created_at timestamptz;
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS updated_at timestamptz;
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_date text;
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS link text;
+CREATE INDEX IF NOT EXISTS idx_music_infos_deleted_at ON music_infos (deleted_at);
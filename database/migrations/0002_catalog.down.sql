@@ -0,0 +1,23 @@
+-- Удаляются только объекты, созданные этой миграцией. Таблица music_infos и ее строки остаются,
+-- дата выпуска возвращается к строковому виду.
+DROP TABLE IF EXISTS song_revisions, synced_lyrics, playlist_entries, playlists, album_tracks, albums, song_tags, tags;
+
+DROP INDEX IF EXISTS idx_music_infos_search_english;
+DROP INDEX IF EXISTS idx_music_infos_search_russian;
+DROP INDEX IF EXISTS idx_music_infos_updated_at;
+DROP INDEX IF EXISTS idx_music_infos_created_at;
+DROP INDEX IF EXISTS idx_music_infos_release_date;
+DROP INDEX IF EXISTS idx_music_infos_group_song_order;
+DROP INDEX IF EXISTS idx_music_infos_group_song;
+DROP INDEX IF EXISTS idx_music_infos_artist_id;
+ALTER TABLE music_infos DROP COLUMN IF EXISTS artist_id;
+DROP TABLE IF EXISTS artists;
+
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_date_legacy text;
+ALTER TABLE music_infos ALTER COLUMN release_date TYPE text USING coalesce(release_date_legacy, CASE release_precision
+	WHEN 'day' THEN to_char(release_date, 'DD.MM.YYYY')
+	WHEN 'month' THEN to_char(release_date, 'MM.YYYY')
+	WHEN 'year' THEN to_char(release_date, 'YYYY')
+END, '');
+ALTER TABLE music_infos DROP COLUMN IF EXISTS release_precision;
+ALTER TABLE music_infos DROP COLUMN IF EXISTS release_date_legacy;
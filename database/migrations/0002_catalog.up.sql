@@ -0,0 +1,219 @@
+-- Каталог: исполнители, теги, альбомы, плейлисты, синхронизированный текст и история правок.
+-- Таблица music_infos уже создана исходной миграцией, поэтому новые столбцы добавляются к ней через ALTER TABLE:
+-- в базе, созданной AutoMigrate прежних версий, CREATE TABLE IF NOT EXISTS был бы пропущен.
+
+-- Разбор строковой даты выпуска в форматах DD.MM.YYYY, MM.YYYY, YYYY и ISO-8601. Строки подходящего вида,
+-- но с несуществующей датой, например 31.02.2006 или 13.2006, не разбираются: to_date либо завершается ошибкой,
+-- которая перехватывается, либо переносит дату, и обратное преобразование не совпадает с исходной строкой.
+CREATE FUNCTION pg_temp.parse_release_date(value text, OUT parsed_date date, OUT parsed_precision text) AS $$
+DECLARE
+	source text := value;
+	date_format text;
+BEGIN
+	IF value ~ '^\d{2}\.\d{2}\.\d{4}$' THEN
+		date_format := 'DD.MM.YYYY';
+		parsed_precision := 'day';
+	ELSIF value ~ '^\d{4}-\d{2}-\d{2}' THEN
+		source := substr(value, 1, 10);
+		date_format := 'YYYY-MM-DD';
+		parsed_precision := 'day';
+	ELSIF value ~ '^\d{2}\.\d{4}$' THEN
+		date_format := 'MM.YYYY';
+		parsed_precision := 'month';
+	ELSIF value ~ '^\d{4}-\d{2}$' THEN
+		date_format := 'YYYY-MM';
+		parsed_precision := 'month';
+	ELSIF value ~ '^\d{4}$' THEN
+		date_format := 'YYYY';
+		parsed_precision := 'year';
+	ELSE
+		RETURN;
+	END IF;
+
+	parsed_date := to_date(source, date_format);
+	IF to_char(parsed_date, date_format) <> source THEN
+		parsed_date := NULL;
+		parsed_precision := NULL;
+	END IF;
+EXCEPTION WHEN data_exception THEN
+	parsed_date := NULL;
+	parsed_precision := NULL;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Перевод строкового столбца release_date в тип date. Нераспознанные и несуществующие даты сохраняются
+-- в release_date_legacy.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'music_infos' AND column_name = 'release_date'
+			AND data_type IN ('text', 'character varying')
+	) THEN
+		ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_precision varchar(5);
+		ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_date_legacy text;
+		UPDATE music_infos SET release_date = btrim(release_date);
+		UPDATE music_infos SET release_precision = (pg_temp.parse_release_date(release_date)).parsed_precision;
+		UPDATE music_infos SET release_date_legacy = release_date
+			WHERE release_date <> '' AND release_precision IS NULL;
+		ALTER TABLE music_infos ALTER COLUMN release_date TYPE date
+			USING (pg_temp.parse_release_date(release_date)).parsed_date;
+	END IF;
+END
+$$;
+
+DROP FUNCTION pg_temp.parse_release_date(text);
+
+CREATE TABLE IF NOT EXISTS artists (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text NOT NULL,
+	aliases jsonb,
+	country text,
+	formed_year bigint,
+	members jsonb
+);
+CREATE INDEX IF NOT EXISTS idx_artists_deleted_at ON artists (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_artists_name ON artists (lower(btrim(name))) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS tags (
+	id bigserial PRIMARY KEY,
+	kind varchar(5) NOT NULL,
+	name text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_kind_name ON tags (kind, lower(btrim(name)));
+
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_precision varchar(5);
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS release_date_legacy text;
+ALTER TABLE music_infos ADD COLUMN IF NOT EXISTS artist_id bigint;
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM pg_constraint WHERE conrelid = 'music_infos'::regclass AND conname = 'fk_music_infos_artist'
+	) THEN
+		ALTER TABLE music_infos ADD CONSTRAINT fk_music_infos_artist FOREIGN KEY (artist_id) REFERENCES artists (id)
+			ON UPDATE CASCADE ON DELETE RESTRICT;
+	END IF;
+END
+$$;
+CREATE INDEX IF NOT EXISTS idx_music_infos_artist_id ON music_infos (artist_id);
+
+-- Песни, совпадающие по группе и названию без учета регистра и пробелов по краям, не пройдут уникальный индекс.
+-- Из каждой такой группы остается песня с наименьшим идентификатором, остальные переносятся в корзину,
+-- откуда их можно восстановить после переименования. Идентификаторы перенесенных песен выводятся в журнал.
+DO $$
+DECLARE
+	duplicates text;
+BEGIN
+	WITH ranked AS (
+		SELECT id, row_number() OVER (PARTITION BY lower(btrim("group")), lower(btrim(song)) ORDER BY id) AS n
+		FROM music_infos WHERE deleted_at IS NULL
+	), trashed AS (
+		UPDATE music_infos m SET deleted_at = now() FROM ranked r WHERE m.id = r.id AND r.n > 1 RETURNING m.id
+	)
+	SELECT string_agg(id::text, ', ' ORDER BY id) INTO duplicates FROM trashed;
+	IF duplicates IS NOT NULL THEN
+		RAISE WARNING 'песни-дубликаты перенесены в корзину: %', duplicates;
+	END IF;
+END
+$$;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_music_infos_group_song ON music_infos (lower(btrim("group")), lower(btrim(song))) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_music_infos_group_song_order ON music_infos ("group", song);
+
+-- Индексы сортировки списка песен по полям, не покрытым idx_music_infos_group_song_order
+CREATE INDEX IF NOT EXISTS idx_music_infos_release_date ON music_infos (release_date, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_music_infos_created_at ON music_infos (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_music_infos_updated_at ON music_infos (updated_at, id) WHERE deleted_at IS NULL;
+
+-- Индексы полнотекстового поиска, выражения совпадают с searchVector
+CREATE INDEX IF NOT EXISTS idx_music_infos_search_russian ON music_infos USING GIN ((setweight(to_tsvector('russian', coalesce("group", '')), 'A') || setweight(to_tsvector('russian', coalesce(song, '')), 'A') || setweight(to_tsvector('russian', coalesce(text, '')), 'B')));
+CREATE INDEX IF NOT EXISTS idx_music_infos_search_english ON music_infos USING GIN ((setweight(to_tsvector('english', coalesce("group", '')), 'A') || setweight(to_tsvector('english', coalesce(song, '')), 'A') || setweight(to_tsvector('english', coalesce(text, '')), 'B')));
+
+CREATE TABLE IF NOT EXISTS song_tags (
+	music_info_id bigint CONSTRAINT fk_song_tags_music_info REFERENCES music_infos (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	tag_id bigint CONSTRAINT fk_song_tags_tag REFERENCES tags (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	PRIMARY KEY (music_info_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS albums (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	title text NOT NULL,
+	artist_id bigint NOT NULL CONSTRAINT fk_albums_artist REFERENCES artists (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+	release_date date,
+	release_precision varchar(5),
+	type varchar(11) NOT NULL,
+	cover_url text
+);
+CREATE INDEX IF NOT EXISTS idx_albums_deleted_at ON albums (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_albums_artist_id ON albums (artist_id);
+
+CREATE TABLE IF NOT EXISTS album_tracks (
+	album_id bigint CONSTRAINT fk_albums_tracks REFERENCES albums (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	disc bigint,
+	track bigint,
+	song_id bigint NOT NULL CONSTRAINT fk_album_tracks_song REFERENCES music_infos (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	PRIMARY KEY (album_id, disc, track)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_tracks_album_song ON album_tracks (album_id, song_id);
+CREATE INDEX IF NOT EXISTS idx_album_tracks_song_id ON album_tracks (song_id);
+
+CREATE TABLE IF NOT EXISTS playlists (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text NOT NULL,
+	description text
+);
+CREATE INDEX IF NOT EXISTS idx_playlists_deleted_at ON playlists (deleted_at);
+
+CREATE TABLE IF NOT EXISTS playlist_entries (
+	id bigserial PRIMARY KEY,
+	playlist_id bigint NOT NULL CONSTRAINT fk_playlists_entries REFERENCES playlists (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	position bigint NOT NULL,
+	song_id bigint CONSTRAINT fk_playlist_entries_song REFERENCES music_infos (id) ON UPDATE CASCADE ON DELETE SET NULL,
+	"group" text,
+	song_name text,
+	added_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_position ON playlist_entries (playlist_id, position);
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_song_id ON playlist_entries (song_id);
+
+CREATE TABLE IF NOT EXISTS synced_lyrics (
+	song_id bigint PRIMARY KEY CONSTRAINT fk_synced_lyrics_song REFERENCES music_infos (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	lrc text NOT NULL,
+	updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS song_revisions (
+	id bigserial PRIMARY KEY,
+	song_id bigint NOT NULL CONSTRAINT fk_song_revisions_song REFERENCES music_infos (id) ON UPDATE CASCADE ON DELETE CASCADE,
+	version bigint NOT NULL,
+	author text NOT NULL DEFAULT '',
+	created_at timestamptz,
+	fields jsonb,
+	"group" text NOT NULL,
+	song_name text NOT NULL,
+	release_date date,
+	release_precision varchar(5),
+	text text NOT NULL,
+	link text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_revisions_song_version ON song_revisions (song_id, version);
+
+-- Исполнители для групп песен, еще не связанных с исполнителем. Варианты написания группы,
+-- отличающиеся регистром и пробелами по краям, относятся к одному исполнителю.
+INSERT INTO artists (created_at, updated_at, name, aliases, members)
+SELECT now(), now(), min(btrim("group")), '[]', '[]' FROM music_infos m
+WHERE m.deleted_at IS NULL AND m.artist_id IS NULL AND NOT EXISTS (
+	SELECT 1 FROM artists a WHERE a.deleted_at IS NULL AND lower(btrim(a.name)) = lower(btrim(m."group"))
+)
+GROUP BY lower(btrim("group"));
+
+UPDATE music_infos m SET artist_id = a.id FROM artists a
+WHERE m.artist_id IS NULL AND a.deleted_at IS NULL AND lower(btrim(a.name)) = lower(btrim(m."group"));
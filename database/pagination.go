@@ -0,0 +1,202 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"music-info/models"
+)
+
+// Cursor позиция в упорядоченном списке песен: значения полей сортировки последней показанной песни.
+// Передается клиенту в непрозрачном виде, см. Encode и DecodeCursor.
+type Cursor struct {
+	// Sort порядок, для которого получен курсор, в формате параметра sort.
+	Sort   string            `json:"o"`
+	Values []json.RawMessage `json:"v"`
+	// Before означает, что нужна страница перед позицией, а не после нее.
+	Before bool `json:"b,omitempty"`
+}
+
+// SongPage страница песен, полученная по курсору.
+type SongPage struct {
+	Songs []models.MusicInfo
+	// Next курсор следующей страницы, nil если страница последняя.
+	Next *Cursor
+	// Prev курсор предыдущей страницы, nil если страница первая.
+	Prev *Cursor
+}
+
+// Encode возвращает курсор в виде непрозрачной строки.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную из Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, NewValidationError(errors.New("неверный курсор"))
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Values) == 0 {
+		return nil, NewValidationError(errors.New("неверный курсор"))
+	}
+
+	return &cursor, nil
+}
+
+// cursorOf возвращает курсор, указывающий на песню в указанном порядке.
+func cursorOf(songInfo *models.MusicInfo, order SongSort, before bool) *Cursor {
+	cursor := &Cursor{Sort: order.String(), Before: before}
+	for _, value := range order.values(songInfo) {
+		data, _ := json.Marshal(value)
+		cursor.Values = append(cursor.Values, data)
+	}
+	return cursor
+}
+
+// cursorValues возвращает значения полей сортировки из курсора, проверяя, что курсор получен для этого порядка.
+func cursorValues(cursor *Cursor, order SongSort) ([]any, error) {
+	if cursor.Sort != order.String() {
+		return nil, NewValidationError(errors.New("курсор получен для другого порядка сортировки"))
+	}
+	values, err := order.decodeValues(cursor.Values)
+	if err != nil {
+		return nil, NewValidationError(errors.New("неверный курсор"))
+	}
+	return values, nil
+}
+
+// newSongPage формирует страницу из выборки размером до limit+1 записей в порядке обхода.
+// Лишняя запись означает, что в направлении обхода есть еще страницы.
+func newSongPage(songs []models.MusicInfo, order SongSort, cursor *Cursor, limit int) SongPage {
+	more := len(songs) > limit
+	if more {
+		songs = songs[:limit]
+	}
+	if songs == nil {
+		songs = []models.MusicInfo{}
+	}
+
+	backward := cursor != nil && cursor.Before
+	if backward {
+		for i, j := 0, len(songs)-1; i < j; i, j = i+1, j-1 {
+			songs[i], songs[j] = songs[j], songs[i]
+		}
+	}
+
+	page := SongPage{Songs: songs}
+	if len(songs) == 0 {
+		return page
+	}
+
+	first, last := &songs[0], &songs[len(songs)-1]
+	if backward {
+		page.Next = cursorOf(last, order, false)
+		if more {
+			page.Prev = cursorOf(first, order, true)
+		}
+	} else {
+		if cursor != nil {
+			page.Prev = cursorOf(first, order, true)
+		}
+		if more {
+			page.Next = cursorOf(last, order, false)
+		}
+	}
+
+	return page
+}
+
+// ListAfter возвращение страницы песен после курсора или перед ним. Без курсора возвращается первая страница.
+func (r *GormRepository) ListAfter(filter SongFilter, order SongSort, cursor *Cursor, limit int) (SongPage, error) {
+	var songs []models.MusicInfo
+
+	query := r.db.Select(songColumns).Scopes(filter.Scope)
+	if cursor == nil {
+		query = query.Scopes(order.Scope)
+	} else {
+		values, err := cursorValues(cursor, order)
+		if err != nil {
+			return SongPage{}, err
+		}
+		walk := order
+		if cursor.Before {
+			walk = order.reversed()
+		}
+		query = query.Scopes(walk.afterScope(values), walk.Scope)
+	}
+
+	if err := query.Limit(limit + 1).Find(&songs).Error; err != nil {
+		return SongPage{}, err
+	}
+
+	return newSongPage(songs, order, cursor, limit), nil
+}
+
+// Count возвращение количества песен, удовлетворяющих фильтру.
+func (r *GormRepository) Count(filter SongFilter) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.MusicInfo{}).Scopes(filter.Scope).Count(&total).Error
+	return total, err
+}
+
+// ListAfter возвращает страницу песен после курсора или перед ним.
+func (r *MemoryRepository) ListAfter(filter SongFilter, order SongSort, cursor *Cursor, limit int) (SongPage, error) {
+	walk := order
+	var values []any
+	if cursor != nil {
+		var err error
+		if values, err = cursorValues(cursor, order); err != nil {
+			return SongPage{}, err
+		}
+		if cursor.Before {
+			walk = order.reversed()
+		}
+	}
+
+	songs := r.filtered(filter)
+	sortSongs(songs, walk)
+
+	var window []models.MusicInfo
+	for i := range songs {
+		if values == nil || walk.compare(walk.values(&songs[i]), values) > 0 {
+			window = append(window, songs[i])
+		}
+		if len(window) > limit {
+			break
+		}
+	}
+
+	return newSongPage(window, order, cursor, limit), nil
+}
+
+// Count возвращает количество песен, удовлетворяющих фильтру.
+func (r *MemoryRepository) Count(filter SongFilter) (int64, error) {
+	return int64(len(r.filtered(filter))), nil
+}
+
+// filtered возвращает копии песен, удовлетворяющих фильтру.
+func (r *MemoryRepository) filtered(filter SongFilter) []models.MusicInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var songs []models.MusicInfo
+	for _, id := range r.sortedIDs() {
+		if filter.Match(r.songs[id]) && r.hasTags(id, filter.Tags) {
+			songs = append(songs, *r.songs[id])
+		}
+	}
+	return songs
+}
+
+// sortSongs упорядочивает песни так же, как SongSort.Scope в PostgreSQL.
+func sortSongs(songs []models.MusicInfo, order SongSort) {
+	sort.SliceStable(songs, func(i, j int) bool {
+		return order.compare(order.values(&songs[i]), order.values(&songs[j])) < 0
+	})
+}
@@ -0,0 +1,444 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlaylistRepository хранилище плейлистов. Операции с записями выполняются атомарно:
+// позиции записей плейлиста всегда идут подряд, начиная с 1.
+type PlaylistRepository interface {
+	// CreatePlaylist сохраняет новый плейлист с записями для песен SongIDs и заполняет его идентификатор.
+	// Если одна из песен не найдена, возвращает ошибку ErrValidation.
+	CreatePlaylist(playlist *models.Playlist) error
+	// PlaylistByID возвращает плейлист с записями в порядке позиций и песнями записей.
+	// Записи удаленных песен возвращаются без песни и с Available равным false.
+	PlaylistByID(id uint) (*models.Playlist, error)
+	// InsertPlaylistEntry вставляет песню на позицию position, сдвигая следующие записи.
+	// Нулевая позиция означает добавление в конец.
+	InsertPlaylistEntry(playlistID, songID uint, position int) (*models.PlaylistEntry, error)
+	// MovePlaylistEntry переносит запись на позицию position.
+	MovePlaylistEntry(playlistID, entryID uint, position int) error
+	// RemovePlaylistEntry удаляет запись из плейлиста.
+	RemovePlaylistEntry(playlistID, entryID uint) error
+}
+
+// playlistColumns поля плейлиста, возвращаемые клиенту.
+var playlistColumns = []string{"id", "created_at", "updated_at", "name", "description"}
+
+// CreatePlaylist создание нового плейлиста.
+func (r *GormRepository) CreatePlaylist(playlist *models.Playlist) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var songs []models.MusicInfo
+		if len(playlist.SongIDs) > 0 {
+			if err := tx.Select("id", "group", "song").Where("id IN ?", playlist.SongIDs).Find(&songs).Error; err != nil {
+				return err
+			}
+		}
+
+		entries, err := playlistEntries(playlist.SongIDs, songs)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(playlist).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		for i := range entries {
+			entries[i].PlaylistID = playlist.ID
+		}
+
+		return tx.Omit(clause.Associations).Create(&entries).Error
+	})
+}
+
+// PlaylistByID возвращение плейлиста с записями по идентификатору.
+func (r *GormRepository) PlaylistByID(id uint) (*models.Playlist, error) {
+
+	var playlist models.Playlist
+
+	result := r.db.Select(playlistColumns).
+		Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Entries.Song", func(db *gorm.DB) *gorm.DB { return db.Select(songColumns) }).
+		First(&playlist, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, notFoundPlaylist(id)
+		}
+		return nil, result.Error
+	}
+
+	// Удаленные песни не загружаются, их записи остаются в плейлисте недоступными
+	if playlist.Entries == nil {
+		playlist.Entries = []models.PlaylistEntry{}
+	}
+	for i := range playlist.Entries {
+		playlist.Entries[i].Available = playlist.Entries[i].Song != nil
+	}
+
+	return &playlist, nil
+}
+
+// InsertPlaylistEntry вставка песни в плейлист.
+func (r *GormRepository) InsertPlaylistEntry(playlistID, songID uint, position int) (*models.PlaylistEntry, error) {
+
+	var entry models.PlaylistEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		count, err := lockPlaylist(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if position, err = insertPosition(position, count); err != nil {
+			return err
+		}
+
+		var songInfo models.MusicInfo
+		result := tx.Select("id", "group", "song").Take(&songInfo, songID)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return NewValidationError(fmt.Errorf("песня id=%d не найдена", songID))
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		err = tx.Model(&models.PlaylistEntry{}).
+			Where("playlist_id = ? AND position >= ?", playlistID, position).
+			Update("position", gorm.Expr("position + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		entry = models.NewPlaylistEntry(&songInfo, position)
+		entry.PlaylistID = playlistID
+		if err := tx.Omit(clause.Associations).Create(&entry).Error; err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, playlistID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// MovePlaylistEntry перенос записи плейлиста на другую позицию.
+func (r *GormRepository) MovePlaylistEntry(playlistID, entryID uint, position int) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		count, err := lockPlaylist(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if err := checkPosition(position, count); err != nil {
+			return err
+		}
+
+		entry, err := playlistEntry(tx, playlistID, entryID)
+		if err != nil {
+			return err
+		}
+		if entry.Position == position {
+			return nil
+		}
+
+		entries := tx.Model(&models.PlaylistEntry{}).Where("playlist_id = ?", playlistID)
+		if position < entry.Position {
+			err = entries.Where("position >= ? AND position < ?", position, entry.Position).
+				Update("position", gorm.Expr("position + 1")).Error
+		} else {
+			err = entries.Where("position > ? AND position <= ?", entry.Position, position).
+				Update("position", gorm.Expr("position - 1")).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.PlaylistEntry{}).Where("id = ?", entryID).Update("position", position).Error; err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, playlistID)
+	})
+}
+
+// RemovePlaylistEntry удаление записи плейлиста.
+func (r *GormRepository) RemovePlaylistEntry(playlistID, entryID uint) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockPlaylist(tx, playlistID); err != nil {
+			return err
+		}
+
+		entry, err := playlistEntry(tx, playlistID, entryID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&models.PlaylistEntry{}, entryID).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.PlaylistEntry{}).
+			Where("playlist_id = ? AND position > ?", playlistID, entry.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, playlistID)
+	})
+}
+
+// lockPlaylist блокирует плейлист до конца транзакции и возвращает количество его записей.
+// Блокировка упорядочивает одновременные изменения записей одного плейлиста.
+func lockPlaylist(tx *gorm.DB, id uint) (int, error) {
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&models.Playlist{}, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return 0, notFoundPlaylist(id)
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var count int64
+	err := tx.Model(&models.PlaylistEntry{}).Where("playlist_id = ?", id).Count(&count).Error
+
+	return int(count), err
+}
+
+// playlistEntry возвращает запись плейлиста.
+func playlistEntry(tx *gorm.DB, playlistID, entryID uint) (*models.PlaylistEntry, error) {
+	var entry models.PlaylistEntry
+	result := tx.Where("playlist_id = ?", playlistID).Take(&entry, entryID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, notFoundPlaylistEntry(playlistID, entryID)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+// touchPlaylist обновляет время изменения плейлиста.
+func touchPlaylist(tx *gorm.DB, id uint) error {
+	return tx.Model(&models.Playlist{}).Where("id = ?", id).Update("updated_at", time.Now()).Error
+}
+
+// playlistEntries создает записи плейлиста для песен ids в том же порядке.
+// Если какой-то песни нет среди songs, возвращает ошибку валидации.
+func playlistEntries(ids []uint, songs []models.MusicInfo) ([]models.PlaylistEntry, error) {
+	byID := make(map[uint]*models.MusicInfo, len(songs))
+	found := make([]uint, 0, len(songs))
+	for i := range songs {
+		byID[songs[i].ID] = &songs[i]
+		found = append(found, songs[i].ID)
+	}
+	if err := missingSongsError(ids, found); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.PlaylistEntry, 0, len(ids))
+	for i, id := range ids {
+		entries = append(entries, models.NewPlaylistEntry(byID[id], i+1))
+	}
+	return entries, nil
+}
+
+// insertPosition проверяет позицию вставки в плейлист из count записей. Нулевая позиция означает конец плейлиста.
+func insertPosition(position, count int) (int, error) {
+	if position == 0 {
+		return count + 1, nil
+	}
+	return position, checkPosition(position, count+1)
+}
+
+// checkPosition проверяет, что позиция находится в пределах от 1 до count.
+func checkPosition(position, count int) error {
+	if position < 1 || position > count {
+		return NewValidationError(fmt.Errorf("позиция должна быть от 1 до %d", count))
+	}
+	return nil
+}
+
+// CreatePlaylist сохраняет новый плейлист с записями.
+func (r *MemoryRepository) CreatePlaylist(playlist *models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var songs []models.MusicInfo
+	for _, id := range playlist.SongIDs {
+		if songInfo, ok := r.songs[id]; ok {
+			songs = append(songs, *songInfo)
+		}
+	}
+	entries, err := playlistEntries(playlist.SongIDs, songs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	playlist.ID = r.nextPlaylistID
+	playlist.CreatedAt = now
+	playlist.UpdatedAt = now
+	r.nextPlaylistID++
+
+	stored := *playlist
+	stored.SongIDs = nil
+	stored.Entries = nil
+	for _, entry := range entries {
+		r.addPlaylistEntry(&stored, entry, now)
+	}
+	r.playlists[stored.ID] = &stored
+
+	return nil
+}
+
+// PlaylistByID возвращает плейлист с записями и песнями записей.
+func (r *MemoryRepository) PlaylistByID(id uint) (*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	playlist, ok := r.playlists[id]
+	if !ok {
+		return nil, notFoundPlaylist(id)
+	}
+
+	result := *playlist
+	result.Entries = make([]models.PlaylistEntry, 0, len(playlist.Entries))
+	for _, entry := range playlist.Entries {
+		if entry.SongID == nil {
+			result.Entries = append(result.Entries, entry)
+			continue
+		}
+		if songInfo, ok := r.songs[*entry.SongID]; ok {
+			song := *songInfo
+			entry.Song = &song
+			entry.Available = true
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return &result, nil
+}
+
+// InsertPlaylistEntry вставляет песню в плейлист.
+func (r *MemoryRepository) InsertPlaylistEntry(playlistID, songID uint, position int) (*models.PlaylistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, ok := r.playlists[playlistID]
+	if !ok {
+		return nil, notFoundPlaylist(playlistID)
+	}
+	position, err := insertPosition(position, len(playlist.Entries))
+	if err != nil {
+		return nil, err
+	}
+	songInfo, ok := r.songs[songID]
+	if !ok {
+		return nil, NewValidationError(fmt.Errorf("песня id=%d не найдена", songID))
+	}
+
+	for i := range playlist.Entries {
+		if playlist.Entries[i].Position >= position {
+			playlist.Entries[i].Position++
+		}
+	}
+	now := time.Now()
+	entry := r.addPlaylistEntry(playlist, models.NewPlaylistEntry(songInfo, position), now)
+	sortPlaylistEntries(playlist.Entries)
+	playlist.UpdatedAt = now
+
+	return &entry, nil
+}
+
+// MovePlaylistEntry переносит запись плейлиста на другую позицию.
+func (r *MemoryRepository) MovePlaylistEntry(playlistID, entryID uint, position int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, ok := r.playlists[playlistID]
+	if !ok {
+		return notFoundPlaylist(playlistID)
+	}
+	if err := checkPosition(position, len(playlist.Entries)); err != nil {
+		return err
+	}
+	index := playlistEntryIndex(playlist, entryID)
+	if index < 0 {
+		return notFoundPlaylistEntry(playlistID, entryID)
+	}
+
+	entry := playlist.Entries[index]
+	entries := append(playlist.Entries[:index:index], playlist.Entries[index+1:]...)
+	entries = append(entries[:position-1], append([]models.PlaylistEntry{entry}, entries[position-1:]...)...)
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+	playlist.Entries = entries
+	playlist.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// RemovePlaylistEntry удаляет запись плейлиста.
+func (r *MemoryRepository) RemovePlaylistEntry(playlistID, entryID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	playlist, ok := r.playlists[playlistID]
+	if !ok {
+		return notFoundPlaylist(playlistID)
+	}
+	index := playlistEntryIndex(playlist, entryID)
+	if index < 0 {
+		return notFoundPlaylistEntry(playlistID, entryID)
+	}
+
+	playlist.Entries = append(playlist.Entries[:index:index], playlist.Entries[index+1:]...)
+	for i := range playlist.Entries {
+		playlist.Entries[i].Position = i + 1
+	}
+	playlist.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// addPlaylistEntry добавляет запись в плейлист, присваивая ей идентификатор. Вызывается под блокировкой.
+func (r *MemoryRepository) addPlaylistEntry(playlist *models.Playlist, entry models.PlaylistEntry, now time.Time) models.PlaylistEntry {
+	entry.ID = r.nextEntryID
+	entry.PlaylistID = playlist.ID
+	entry.AddedAt = now
+	r.nextEntryID++
+
+	playlist.Entries = append(playlist.Entries, entry)
+	return entry
+}
+
+// playlistEntryIndex возвращает индекс записи в плейлисте или -1, если записи нет.
+func playlistEntryIndex(playlist *models.Playlist, entryID uint) int {
+	for i, entry := range playlist.Entries {
+		if entry.ID == entryID {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortPlaylistEntries упорядочивает записи плейлиста по позиции.
+func sortPlaylistEntries(entries []models.PlaylistEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Position < entries[j].Position })
+}
@@ -0,0 +1,417 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SongRepository хранилище информации о песнях. Каждое изменение полей песни записывается в историю правок
+// с автором из поля ChangedBy переданной песни.
+type SongRepository interface {
+	// Create сохраняет новую песню и заполняет её идентификатор.
+	// Если песня с такой же парой группы и названия уже существует, возвращает *DuplicateSongError.
+	Create(songInfo *models.MusicInfo) error
+	// Upsert создает песню или обновляет заполненные поля существующей песни с той же парой группы и названия.
+	// Заполняет songInfo сохраненными данными и возвращает true, если песня была создана.
+	Upsert(songInfo *models.MusicInfo) (bool, error)
+	// Detail возвращает песню по полям Group и Song. Поля сравниваются без учета регистра и пробелов по краям,
+	// как в уникальном индексе; так же ищут песню Update и Delete.
+	Detail(group, song string) (*models.MusicInfo, error)
+	// Update обновляет заполненные поля песни, найденной по полям Group и Song.
+	// Если песня не найдена, возвращает ошибку ErrNotFound.
+	Update(group, song string, updateSong *models.MusicInfo) error
+	// Delete переносит песню, найденную по полям Group и Song, в корзину.
+	Delete(group, song string) error
+	// List возвращает страницу песен, удовлетворяющих фильтру, в указанном порядке.
+	List(filter SongFilter, order SongSort, page, limit int) ([]models.MusicInfo, error)
+	// ListAfter возвращает страницу из не более чем limit песен, удовлетворяющих фильтру, после курсора
+	// или перед ним, если у курсора установлен Before. Без курсора возвращается первая страница.
+	// Курсор должен быть получен для того же порядка.
+	ListAfter(filter SongFilter, order SongSort, cursor *Cursor, limit int) (SongPage, error)
+	// Count возвращает количество песен, удовлетворяющих фильтру.
+	Count(filter SongFilter) (int64, error)
+	// Search возвращает страницу песен, найденных полнотекстовым поиском, в порядке убывания релевантности.
+	// Параметр lang выбирает языковую конфигурацию: ru, en или обе, если он пуст.
+	Search(query, lang string, page, limit int) ([]models.SearchResult, error)
+
+	// DetailByID возвращает песню по идентификатору.
+	DetailByID(id uint) (*models.MusicInfo, error)
+	// UpdateByID обновляет заполненные поля песни с указанным идентификатором.
+	UpdateByID(id uint, updateSong *models.MusicInfo) error
+	// ReplaceByID заменяет все поля песни с указанным идентификатором, включая пустые.
+	ReplaceByID(id uint, songInfo *models.MusicInfo) error
+	// PatchByID атомарно изменяет песню с указанным идентификатором: передает patch текущую версию песни
+	// и сохраняет все редактируемые поля измененной версии. Параллельные изменения той же песни ждут
+	// завершения, поэтому ни одно из них не теряется. Ошибка patch возвращается без изменений.
+	PatchByID(id uint, patch func(songInfo *models.MusicInfo) error) error
+	// DeleteByID переносит песню с указанным идентификатором в корзину.
+	DeleteByID(id uint) error
+
+	// ImportSongs сохраняет пакет песен одной транзакцией, каждую в своей точке сохранения, так что ошибка
+	// одной песни не отменяет остальные. Возвращает результат для каждой песни: nil, если песня создана,
+	// *DuplicateSongError, если такая пара группы и названия уже есть в хранилище или выше в пакете.
+	// При dryRun песни только проверяются: транзакция откатывается, идентификаторы не заполняются.
+	ImportSongs(songs []models.MusicInfo, dryRun bool) ([]error, error)
+}
+
+// Repository все хранилища приложения. GormRepository и MemoryRepository реализуют их все.
+type Repository interface {
+	SongRepository
+	RevisionRepository
+	TrashRepository
+	ArtistRepository
+	AlbumRepository
+	TagRepository
+	PlaylistRepository
+	LyricsRepository
+}
+
+// songColumns поля песни, возвращаемые клиенту.
+var songColumns = []string{"id", "created_at", "updated_at", "group", "song", "release_date", "release_precision", "release_date_legacy", "text", "link", "artist_id"}
+
+// editableColumns поля песни, которые изменяются при замене. Исполнитель определяется по полю group.
+var editableColumns = []string{"group", "song", "release_date", "release_precision", "text", "link", "artist_id"}
+
+// keyCondition условие поиска песни по нормализованной паре группы и названия, совпадающее с уникальным индексом.
+const keyCondition = "lower(btrim(\"group\")) = lower(btrim(?)) AND lower(btrim(song)) = lower(btrim(?))"
+
+// Проверка соответствия хранилищ интерфейсу
+var (
+	_ Repository = (*GormRepository)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
+
+// GormRepository хранилище песен в базе данных PostgreSQL.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRepository создает хранилище песен поверх соединения с базой данных.
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+// Create создание новой записи в базе данных.
+func (r *GormRepository) Create(songInfo *models.MusicInfo) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := linkArtist(tx, songInfo); err != nil {
+			return err
+		}
+		if err := tx.Create(songInfo).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, songInfo.ID, songInfo.ChangedBy)
+	})
+
+	return r.conflictError(err, songInfo.Group, songInfo.Song)
+}
+
+// Upsert создание новой записи или обновление существующей с той же парой группы и названия.
+func (r *GormRepository) Upsert(songInfo *models.MusicInfo) (bool, error) {
+
+	created := false
+	author := songInfo.ChangedBy
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.MusicInfo
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(keyCondition, songInfo.Group, songInfo.Song).First(&existing)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			created = true
+			if err := linkArtist(tx, songInfo); err != nil {
+				return err
+			}
+			if err := tx.Create(songInfo).Error; err != nil {
+				return err
+			}
+			return recordRevision(tx, songInfo.ID, author)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := recordBaseline(tx, existing.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&existing).Updates(songInfo).Error; err != nil {
+			return err
+		}
+		if !songInfo.ReleaseDate.IsZero() {
+			if err := clearLegacyReleaseDate(tx, existing.ID); err != nil {
+				return err
+			}
+		}
+		if err := recordRevision(tx, existing.ID, author); err != nil {
+			return err
+		}
+
+		var updated models.MusicInfo
+		if err := tx.Select(songColumns).First(&updated, existing.ID).Error; err != nil {
+			return err
+		}
+		*songInfo = updated
+
+		return nil
+	})
+
+	return created, r.conflictError(err, songInfo.Group, songInfo.Song)
+}
+
+// Update обновление информации о песне по полям Group и Song.
+func (r *GormRepository) Update(group, song string, updateSong *models.MusicInfo) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := linkArtist(tx, updateSong); err != nil {
+			return err
+		}
+
+		var ids []uint
+		result := tx.Model(&models.MusicInfo{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(keyCondition, group, song).Pluck("id", &ids)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(ids) == 0 {
+			return notFoundByKey(group, song)
+		}
+
+		for _, id := range ids {
+			if err := recordBaseline(tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.MusicInfo{}).Where("id IN ?", ids).Updates(updateSong).Error; err != nil {
+			return err
+		}
+		if !updateSong.ReleaseDate.IsZero() {
+			if err := clearLegacyReleaseDate(tx, ids...); err != nil {
+				return err
+			}
+		}
+		for _, id := range ids {
+			if err := recordRevision(tx, id, updateSong.ChangedBy); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return r.conflictError(err, firstNonEmpty(updateSong.Group, group), firstNonEmpty(updateSong.Song, song))
+}
+
+// Delete удаление информации о песне по полям Group и Song.
+func (r *GormRepository) Delete(group, song string) error {
+
+	result := r.db.Where(keyCondition, group, song).Delete(&models.MusicInfo{})
+
+	if result.Error != nil {
+		return fmt.Errorf("ошибка при удалении записи: %v", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundByKey(group, song)
+	}
+
+	return result.Error
+}
+
+// Detail возвращение информации о песне по полям Group и Song.
+func (r *GormRepository) Detail(group, song string) (*models.MusicInfo, error) {
+
+	var songInfo models.MusicInfo
+
+	if r.db == nil {
+		return nil, errors.New("база данных не инициализирована")
+	}
+
+	result := r.db.Select(songColumns).Where(keyCondition, group, song).First(&songInfo)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, notFoundByKey(group, song)
+		}
+		return nil, result.Error
+	}
+
+	return &songInfo, nil
+}
+
+// List возвращение списка песен.
+func (r *GormRepository) List(filter SongFilter, order SongSort, page, limit int) ([]models.MusicInfo, error) {
+	var songs []models.MusicInfo
+
+	offset := (page - 1) * limit
+	query := r.db.Select(songColumns).Scopes(filter.Scope, order.Scope).Offset(offset).Limit(limit).Find(&songs)
+
+	return songs, query.Error
+}
+
+// DetailByID возвращение информации о песне по идентификатору.
+func (r *GormRepository) DetailByID(id uint) (*models.MusicInfo, error) {
+
+	var songInfo models.MusicInfo
+
+	result := r.db.Select(songColumns).First(&songInfo, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, notFoundByID(id)
+		}
+		return nil, result.Error
+	}
+
+	return &songInfo, nil
+}
+
+// UpdateByID обновление заполненных полей песни по идентификатору.
+func (r *GormRepository) UpdateByID(id uint, updateSong *models.MusicInfo) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := linkArtist(tx, updateSong); err != nil {
+			return err
+		}
+
+		if err := recordBaseline(tx, id); err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.MusicInfo{}).Where("id = ?", id).Updates(updateSong)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return notFoundByID(id)
+		}
+
+		if !updateSong.ReleaseDate.IsZero() {
+			if err := clearLegacyReleaseDate(tx, id); err != nil {
+				return err
+			}
+		}
+
+		return recordRevision(tx, id, updateSong.ChangedBy)
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		if current, detailErr := r.DetailByID(id); detailErr == nil {
+			return r.conflictError(err, firstNonEmpty(updateSong.Group, current.Group), firstNonEmpty(updateSong.Song, current.Song))
+		}
+	}
+
+	return err
+}
+
+// ReplaceByID замена всех редактируемых полей песни по идентификатору.
+func (r *GormRepository) ReplaceByID(id uint, songInfo *models.MusicInfo) error {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceSong(tx, id, songInfo)
+	})
+
+	return r.conflictError(err, songInfo.Group, songInfo.Song)
+}
+
+// PatchByID изменение песни по идентификатору в одной транзакции. Строка песни блокируется
+// SELECT ... FOR UPDATE до сохранения, так что параллельные изменения выполняются по очереди.
+func (r *GormRepository) PatchByID(id uint, patch func(songInfo *models.MusicInfo) error) error {
+
+	var songInfo models.MusicInfo
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select(songColumns).First(&songInfo, id)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return notFoundByID(id)
+			}
+			return result.Error
+		}
+
+		if err := patch(&songInfo); err != nil {
+			return err
+		}
+
+		return replaceSong(tx, id, &songInfo)
+	})
+
+	return r.conflictError(err, songInfo.Group, songInfo.Song)
+}
+
+// replaceSong заменяет все редактируемые поля песни в транзакции tx и записывает ревизию.
+func replaceSong(tx *gorm.DB, id uint, songInfo *models.MusicInfo) error {
+	if err := linkArtist(tx, songInfo); err != nil {
+		return err
+	}
+
+	if err := recordBaseline(tx, id); err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.MusicInfo{}).Where("id = ?", id).Select(editableColumns).Updates(songInfo)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundByID(id)
+	}
+
+	if !songInfo.ReleaseDate.IsZero() {
+		if err := clearLegacyReleaseDate(tx, id); err != nil {
+			return err
+		}
+	}
+
+	return recordRevision(tx, id, songInfo.ChangedBy)
+}
+
+// clearLegacyReleaseDate удаляет нераспознанную дату выпуска у песен, которым записана новая дата выпуска,
+// чтобы устаревшее значение не пережило исправленное.
+func clearLegacyReleaseDate(tx *gorm.DB, ids ...uint) error {
+	return tx.Exec("UPDATE music_infos SET release_date_legacy = NULL WHERE id IN ? AND release_date_legacy IS NOT NULL", ids).Error
+}
+
+// DeleteByID удаление песни по идентификатору.
+func (r *GormRepository) DeleteByID(id uint) error {
+
+	result := r.db.Delete(&models.MusicInfo{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("ошибка при удалении записи: %v", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundByID(id)
+	}
+
+	return nil
+}
+
+// conflictError преобразует нарушение уникального индекса пары группы и названия в *DuplicateSongError
+// с идентификатором существующей песни. Остальные ошибки возвращаются без изменений.
+// Требует включенного TranslateError в настройках gorm.
+func (r *GormRepository) conflictError(err error, group, song string) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	var existing models.MusicInfo
+	if result := r.db.Select(songColumns).Where(keyCondition, group, song).First(&existing); result.Error != nil {
+		return &DuplicateSongError{Group: group, Song: song}
+	}
+
+	return &DuplicateSongError{ID: existing.ID, Group: existing.Group, Song: existing.Song}
+}
+
+// firstNonEmpty возвращает первую непустую строку.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
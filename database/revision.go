@@ -0,0 +1,185 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevisionRepository история правок песен. Версии записываются хранилищем песен при каждом изменении полей песни
+// в той же транзакции, что и само изменение; изменение, не затронувшее поля, версию не создает.
+type RevisionRepository interface {
+	// SongRevisions возвращает страницу версий песни от новых к старым.
+	SongRevisions(songID uint, page, limit int) ([]models.RevisionSummary, error)
+	// SongRevision возвращает версию песни с номером version.
+	SongRevision(songID uint, version int) (*models.SongRevision, error)
+}
+
+// notFoundRevision ошибка отсутствия версии песни.
+func notFoundRevision(songID uint, version int) error {
+	return fmt.Errorf("%w: у песни id=%d нет версии %d", ErrNotFound, songID, version)
+}
+
+// SongRevisions возвращение версий песни.
+func (r *GormRepository) SongRevisions(songID uint, page, limit int) ([]models.RevisionSummary, error) {
+
+	if err := r.songExists(r.db, songID); err != nil {
+		return nil, err
+	}
+
+	revisions := []models.RevisionSummary{}
+	result := r.db.Model(&models.SongRevision{}).
+		Select("version", "author", "created_at", "fields").
+		Where("song_id = ?", songID).
+		Order("version DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&revisions)
+
+	return revisions, result.Error
+}
+
+// SongRevision возвращение версии песни.
+func (r *GormRepository) SongRevision(songID uint, version int) (*models.SongRevision, error) {
+
+	if err := r.songExists(r.db, songID); err != nil {
+		return nil, err
+	}
+
+	var revision models.SongRevision
+	result := r.db.Where("song_id = ? AND version = ?", songID, version).Take(&revision)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, notFoundRevision(songID, version)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &revision, nil
+}
+
+// recordBaseline сохраняет прежнее состояние песни без автора перед ее изменением, если у песни еще нет истории.
+// Так песня, сохраненная до появления истории, получает исходную версию. Если история уже есть, ничего
+// не записывается: иначе каждое изменение в обход хранилища порождало бы лишнюю версию без автора.
+// Строка песни блокируется до конца транзакции.
+func recordBaseline(tx *gorm.DB, id uint) error {
+	var songInfo models.MusicInfo
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&songInfo, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return notFoundByID(id)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+
+	var revisions int64
+	if err := tx.Model(&models.SongRevision{}).Where("song_id = ?", id).Count(&revisions).Error; err != nil {
+		return err
+	}
+	if revisions > 0 {
+		return nil
+	}
+
+	return recordRevision(tx, id, "")
+}
+
+// recordRevision сохраняет текущее состояние песни новой версией, если оно отличается от последней версии.
+// Строка песни блокируется до конца транзакции, поэтому номера версий не повторяются.
+func recordRevision(tx *gorm.DB, id uint, author string) error {
+	var songInfo models.MusicInfo
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select(songColumns).Take(&songInfo, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return notFoundByID(id)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+
+	var previous models.SongRevision
+	result = tx.Where("song_id = ?", id).Order("version DESC").Limit(1).Find(&previous)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	songInfo.ChangedBy = author
+	revision := models.NewSongRevision(&songInfo)
+	if result.RowsAffected == 0 {
+		revision.Fields = revision.ChangedFields(nil)
+	} else {
+		revision.Fields = revision.ChangedFields(&previous)
+	}
+	if len(revision.Fields) == 0 {
+		return nil
+	}
+	revision.Version = previous.Version + 1
+
+	return tx.Omit(clause.Associations).Create(&revision).Error
+}
+
+// SongRevisions возвращает версии песни от новых к старым.
+func (r *MemoryRepository) SongRevisions(songID uint, page, limit int) ([]models.RevisionSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.songs[songID]; !ok {
+		return nil, notFoundByID(songID)
+	}
+
+	history := r.revisions[songID]
+	revisions := []models.RevisionSummary{}
+	for i := len(history) - 1 - (page-1)*limit; i >= 0 && len(revisions) < limit; i-- {
+		revision := history[i]
+		revisions = append(revisions, models.RevisionSummary{
+			Version:   revision.Version,
+			Author:    revision.Author,
+			CreatedAt: revision.CreatedAt,
+			Fields:    revision.Fields,
+		})
+	}
+
+	return revisions, nil
+}
+
+// SongRevision возвращает версию песни с номером version.
+func (r *MemoryRepository) SongRevision(songID uint, version int) (*models.SongRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.songs[songID]; !ok {
+		return nil, notFoundByID(songID)
+	}
+
+	history := r.revisions[songID]
+	if version < 1 || version > len(history) {
+		return nil, notFoundRevision(songID, version)
+	}
+
+	revision := history[version-1]
+	return &revision, nil
+}
+
+// recordRevision сохраняет состояние песни новой версией, если оно отличается от последней версии.
+// Вызывается под блокировкой.
+func (r *MemoryRepository) recordRevision(songInfo *models.MusicInfo, author string) {
+	history := r.revisions[songInfo.ID]
+
+	stored := *songInfo
+	stored.ChangedBy = author
+	revision := models.NewSongRevision(&stored)
+	if len(history) == 0 {
+		revision.Fields = revision.ChangedFields(nil)
+	} else {
+		revision.Fields = revision.ChangedFields(&history[len(history)-1])
+	}
+	if len(revision.Fields) == 0 {
+		return
+	}
+	revision.Version = len(history) + 1
+	revision.CreatedAt = time.Now()
+
+	r.revisions[songInfo.ID] = append(history, revision)
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+
+	"music-info/models"
+)
+
+// searchConfigs конфигурации полнотекстового поиска PostgreSQL для значений параметра lang.
+// Пустое значение означает поиск одновременно по русской и английской конфигурациям.
+var searchConfigs = map[string][]string{
+	"":        {"russian", "english"},
+	"ru":      {"russian"},
+	"russian": {"russian"},
+	"en":      {"english"},
+	"english": {"english"},
+}
+
+// SearchConfigs возвращает конфигурации полнотекстового поиска для языка запроса.
+func SearchConfigs(lang string) ([]string, error) {
+	configs, ok := searchConfigs[strings.ToLower(lang)]
+	if !ok {
+		return nil, NewValidationError(fmt.Errorf("неподдерживаемый язык поиска '%s': ожидается ru или en", lang))
+	}
+	return configs, nil
+}
+
+// searchVector выражение поискового вектора песни. Группа и название весят больше текста.
+// Совпадает с выражением GIN-индекса из migrations/0002_catalog.up.sql, иначе индекс не будет использован.
+func searchVector(config string) string {
+	return fmt.Sprintf(`(setweight(to_tsvector('%[1]s', coalesce("group", '')), 'A') || `+
+		`setweight(to_tsvector('%[1]s', coalesce(song, '')), 'A') || `+
+		`setweight(to_tsvector('%[1]s', coalesce(text, '')), 'B'))`, config)
+}
+
+// searchPart запрос совпадений для одной конфигурации поиска.
+// Номер куплета вычисляется разбиением текста по пустым строкам, как в models.MusicInfo.Verses.
+const searchPart = `SELECT id, "group", song,
+	ts_rank(%[2]s, q) AS rank,
+	ts_headline('%[1]s', coalesce(text, ''), q, @headline) AS snippet,
+	coalesce(v.n, 0) AS verse
+FROM music_infos
+CROSS JOIN websearch_to_tsquery('%[1]s', @query) q
+LEFT JOIN LATERAL (
+	SELECT n FROM regexp_split_to_table(btrim(replace(coalesce(text, ''), E'\r\n', E'\n'), E' \t\n'), E'\n\\s*\n') WITH ORDINALITY AS verses(verse, n)
+	WHERE to_tsvector('%[1]s', verse) @@ q
+	ORDER BY n LIMIT 1
+) v ON true
+WHERE deleted_at IS NULL AND %[2]s @@ q`
+
+// Маркеры начала и конца совпадения в ts_headline. Символы из области частного использования Unicode
+// не встречаются в текстах песен и не изменяются при экранировании HTML.
+const (
+	headlineStart = "\uE000"
+	headlineStop  = "\uE001"
+)
+
+// headlineOptions параметры ts_headline. Совпадения выделяются маркерами, а не тегами,
+// потому что ts_headline не экранирует текст песни.
+var headlineOptions = `MaxFragments=2, MaxWords=15, MinWords=5, FragmentDelimiter=" … ", ` +
+	`StartSel=` + headlineStart + `, StopSel=` + headlineStop
+
+// escapeSnippet экранирует HTML во фрагменте ts_headline и заменяет маркеры совпадений тегами <b> и </b>.
+func escapeSnippet(snippet string) string {
+	return strings.NewReplacer(headlineStart, "<b>", headlineStop, "</b>").Replace(html.EscapeString(snippet))
+}
+
+// Search полнотекстовый поиск песен по группе, названию и тексту.
+// Если песня найдена в нескольких конфигурациях, используется совпадение с наибольшей релевантностью.
+func (r *GormRepository) Search(query, lang string, page, limit int) ([]models.SearchResult, error) {
+	configs, err := SearchConfigs(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(configs))
+	for i, config := range configs {
+		parts[i] = fmt.Sprintf(searchPart, config, searchVector(config))
+	}
+
+	statement := `SELECT * FROM (
+	SELECT DISTINCT ON (id) * FROM (` + strings.Join(parts, "\nUNION ALL\n") + `) matches ORDER BY id, rank DESC
+) best ORDER BY rank DESC, id LIMIT @limit OFFSET @offset`
+
+	var results []models.SearchResult
+	result := r.db.Raw(statement, sql.Named("query", query), sql.Named("headline", headlineOptions), sql.Named("limit", limit), sql.Named("offset", (page-1)*limit)).Scan(&results)
+	if result.Error != nil {
+		log.Printf("Ошибка полнотекстового поиска: %v\n", result.Error)
+		return nil, result.Error
+	}
+	for i := range results {
+		results[i].Snippet = escapeSnippet(results[i].Snippet)
+	}
+
+	return results, nil
+}
+
+// Search упрощенный поиск песен: все слова запроса должны входить в группу, название или текст без учета регистра.
+// Морфология не учитывается, релевантность равна числу вхождений слов запроса.
+func (r *MemoryRepository) Search(query, lang string, page, limit int) ([]models.SearchResult, error) {
+	if _, err := SearchConfigs(lang); err != nil {
+		return nil, err
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []models.SearchResult
+	for _, id := range r.sortedIDs() {
+		songInfo := r.songs[id]
+		content := strings.ToLower(songInfo.Group + " " + songInfo.Song + " " + songInfo.Text)
+
+		rank := 0
+		for _, word := range words {
+			count := strings.Count(content, word)
+			if count == 0 {
+				rank = 0
+				break
+			}
+			rank += count
+		}
+		if rank == 0 {
+			continue
+		}
+
+		searchResult := models.SearchResult{ID: songInfo.ID, Group: songInfo.Group, Song: songInfo.Song, Rank: float64(rank)}
+		for _, verse := range songInfo.Verses() {
+			if containsAny(strings.ToLower(verse.Text), words) {
+				searchResult.Verse = verse.Number
+				searchResult.Snippet = highlight(verse.Text, words)
+				break
+			}
+		}
+		results = append(results, searchResult)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	offset := (page - 1) * limit
+	if offset >= len(results) {
+		return []models.SearchResult{}, nil
+	}
+	end := offset + limit
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[offset:end], nil
+}
+
+// containsAny проверяет, что строка содержит хотя бы одно из слов.
+func containsAny(s string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight выделяет слова запроса тегами <b> и </b>, как ts_headline. Остальной текст экранируется.
+func highlight(s string, words []string) string {
+	lower := strings.ToLower(s)
+	if len(lower) != len(s) {
+		return html.EscapeString(s)
+	}
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(s); {
+		matched := 0
+		for _, word := range words {
+			if strings.HasPrefix(lower[i:], word) && len(word) > matched {
+				matched = len(word)
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		b.WriteString(html.EscapeString(s[start:i]) + "<b>" + html.EscapeString(s[i:i+matched]) + "</b>")
+		i += matched
+		start = i
+	}
+	b.WriteString(html.EscapeString(s[start:]))
+	return b.String()
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+)
+
+// sortField поле, по которому клиент может упорядочить список песен.
+type sortField struct {
+	// column выражение столбца в запросе.
+	column string
+	// nullable столбец может содержать NULL.
+	nullable bool
+	// value возвращает значение поля песни: string, time.Time, uint или nil для NULL.
+	value func(songInfo *models.MusicInfo) any
+	// decode разбирает значение поля, сохраненное в курсоре.
+	decode func(data json.RawMessage) (any, error)
+}
+
+// sortFields поля, разрешенные в параметре sort. Для каждого поля есть индекс, см. migrations/0002_catalog.up.sql.
+var sortFields = map[string]sortField{
+	"group": {
+		column: "\"group\"",
+		value:  func(songInfo *models.MusicInfo) any { return songInfo.Group },
+		decode: decodeSortValue[string],
+	},
+	"song": {
+		column: "song",
+		value:  func(songInfo *models.MusicInfo) any { return songInfo.Song },
+		decode: decodeSortValue[string],
+	},
+	"release_date": {
+		column:   "release_date",
+		nullable: true,
+		value: func(songInfo *models.MusicInfo) any {
+			if songInfo.ReleaseDate.IsZero() {
+				return nil
+			}
+			return *songInfo.ReleaseDate.Date
+		},
+		decode: decodeSortValue[time.Time],
+	},
+	"created_at": {
+		column: "created_at",
+		value:  func(songInfo *models.MusicInfo) any { return songInfo.CreatedAt },
+		decode: decodeSortValue[time.Time],
+	},
+	"updated_at": {
+		column: "updated_at",
+		value:  func(songInfo *models.MusicInfo) any { return songInfo.UpdatedAt },
+		decode: decodeSortValue[time.Time],
+	},
+	"id": {
+		column: "id",
+		value:  func(songInfo *models.MusicInfo) any { return songInfo.ID },
+		decode: decodeSortValue[uint],
+	},
+}
+
+// decodeSortValue разбирает значение поля из курсора. null означает NULL.
+func decodeSortValue[T any](data json.RawMessage) (any, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// SortKey поле сортировки и ее направление.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SongSort порядок списка песен. Последним ключом всегда идет id, поэтому порядок однозначен.
+type SongSort []SortKey
+
+// DefaultSongSort порядок списка песен по умолчанию.
+var DefaultSongSort = SongSort{{Field: "group"}, {Field: "song"}, {Field: "id"}}
+
+// ParseSongSort разбирает параметр sort: поля через запятую, знак минус перед полем означает обратный порядок,
+// например -release_date,song. Если id не указан, он добавляется в конце в направлении последнего поля.
+func ParseSongSort(spec string) (SongSort, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultSongSort, nil
+	}
+
+	var order SongSort
+	seen := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		key := SortKey{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if _, ok := sortFields[key.Field]; !ok {
+			return nil, NewValidationError(fmt.Errorf("недопустимое поле сортировки '%s': ожидается group, song, release_date, created_at, updated_at или id", item))
+		}
+		if seen[key.Field] {
+			return nil, NewValidationError(fmt.Errorf("поле сортировки '%s' указано несколько раз", key.Field))
+		}
+		seen[key.Field] = true
+		order = append(order, key)
+	}
+
+	if !seen["id"] {
+		order = append(order, SortKey{Field: "id", Desc: order[len(order)-1].Desc})
+	}
+
+	return order, nil
+}
+
+// String возвращает порядок в формате параметра sort.
+func (s SongSort) String() string {
+	items := make([]string, len(s))
+	for i, key := range s {
+		items[i] = key.Field
+		if key.Desc {
+			items[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// reversed возвращает обратный порядок для выборки страницы перед курсором.
+func (s SongSort) reversed() SongSort {
+	result := make(SongSort, len(s))
+	for i, key := range s {
+		result[i] = SortKey{Field: key.Field, Desc: !key.Desc}
+	}
+	return result
+}
+
+// Scope возвращает условие ORDER BY для запроса gorm. NULL идут последними при прямом порядке
+// и первыми при обратном, как по умолчанию в PostgreSQL.
+func (s SongSort) Scope(db *gorm.DB) *gorm.DB {
+	for _, key := range s {
+		column := sortFields[key.Field].column
+		if key.Desc {
+			column += " DESC"
+		}
+		db = db.Order(column)
+	}
+	return db
+}
+
+// values возвращает значения полей сортировки песни.
+func (s SongSort) values(songInfo *models.MusicInfo) []any {
+	values := make([]any, len(s))
+	for i, key := range s {
+		values[i] = sortFields[key.Field].value(songInfo)
+	}
+	return values
+}
+
+// afterScope возвращает условие отбора записей, следующих в этом порядке за значениями полей сортировки.
+// Условие раскрывается в (a > ?) OR (a = ? AND b > ?) ..., поскольку сравнение строк
+// не поддерживает разные направления и NULL.
+func (s SongSort) afterScope(values []any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var terms []string
+		var args []any
+		var equal []string
+		var equalArgs []any
+
+		for i, key := range s {
+			field := sortFields[key.Field]
+			column := field.column
+			value := values[i]
+
+			var greater string
+			var greaterArgs []any
+			switch {
+			case value == nil && key.Desc:
+				greater = column + " IS NOT NULL"
+			case value == nil:
+				greater = ""
+			case key.Desc:
+				greater, greaterArgs = column+" < ?", []any{value}
+			case !field.nullable:
+				greater, greaterArgs = column+" > ?", []any{value}
+			default:
+				greater, greaterArgs = "("+column+" > ? OR "+column+" IS NULL)", []any{value}
+			}
+
+			if greater != "" {
+				terms = append(terms, "("+strings.Join(append(append([]string{}, equal...), greater), " AND ")+")")
+				args = append(append(args, equalArgs...), greaterArgs...)
+			}
+
+			if value == nil {
+				equal = append(equal, column+" IS NULL")
+			} else {
+				equal = append(equal, column+" = ?")
+				equalArgs = append(equalArgs, value)
+			}
+		}
+
+		if len(terms) == 0 {
+			return db.Where("FALSE")
+		}
+		return db.Where(strings.Join(terms, " OR "), args...)
+	}
+}
+
+// compare сравнивает значения полей сортировки так же, как PostgreSQL при этом порядке.
+func (s SongSort) compare(a, b []any) int {
+	for i, key := range s {
+		result := compareSortValues(a[i], b[i])
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareSortValues сравнивает значения одного поля. NULL больше любого значения.
+func compareSortValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uint:
+		switch {
+		case a < b.(uint):
+			return -1
+		case a > b.(uint):
+			return 1
+		}
+	}
+	return 0
+}
+
+// decodeValues разбирает значения полей сортировки из курсора.
+func (s SongSort) decodeValues(data []json.RawMessage) ([]any, error) {
+	if len(data) != len(s) {
+		return nil, errors.New("неверное количество значений")
+	}
+	values := make([]any, len(s))
+	for i, key := range s {
+		value, err := sortFields[key.Field].decode(data[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"testing"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSongSort(t *testing.T) {
+
+	// Проверяем метод
+	order, err := ParseSongSort("")
+	assert.NoError(t, err)
+	assert.Equal(t, "group,song,id", order.String())
+
+	order, err = ParseSongSort("-release_date, song")
+	assert.NoError(t, err)
+	assert.Equal(t, "-release_date,song,id", order.String())
+
+	order, err = ParseSongSort("-created_at")
+	assert.NoError(t, err)
+	assert.Equal(t, "-created_at,-id", order.String())
+
+	order, err = ParseSongSort("-id,group")
+	assert.NoError(t, err)
+	assert.Equal(t, "-id,group", order.String())
+
+	for _, spec := range []string{"text", "song,song", "song,", "+song"} {
+		_, err = ParseSongSort(spec)
+		assert.ErrorIs(t, err, ErrValidation, spec)
+	}
+}
+
+func TestMemoryRepositoryListAfterSort(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := NewMemoryRepository()
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Uprising", ReleaseDate: models.MustParseReleaseDate("2009"), Text: "Ooh"},
+		{Group: "Muse", Song: "Hysteria", Text: "Ooh"},
+		{Group: "Muse", Song: "Madness", ReleaseDate: models.MustParseReleaseDate("2012"), Text: "Ooh"},
+		{Group: "Muse", Song: "Bliss", Text: "Ooh"},
+		{Group: "Muse", Song: "Starlight", ReleaseDate: models.MustParseReleaseDate("2006"), Text: "Ooh"},
+	} {
+		assert.NoError(t, repo.Create(&songInfo))
+	}
+
+	order, err := ParseSongSort("-release_date,song")
+	assert.NoError(t, err)
+
+	// Проверяем метод: при обратном порядке песни без даты идут первыми, как в PostgreSQL
+	var songs []string
+	var cursor *Cursor
+	for {
+		page, err := repo.ListAfter(SongFilter{}, order, cursor, 2)
+		assert.NoError(t, err)
+		for _, songInfo := range page.Songs {
+			songs = append(songs, songInfo.Song)
+		}
+		if page.Next == nil {
+			break
+		}
+		cursor = page.Next
+	}
+	assert.Equal(t, []string{"Bliss", "Hysteria", "Madness", "Uprising", "Starlight"}, songs)
+
+	// Страница перед курсором, указывающим на Uprising
+	page, err := repo.ListAfter(SongFilter{}, order, &Cursor{Sort: cursor.Sort, Values: cursor.Values, Before: true}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(page.Songs))
+	assert.Equal(t, "Bliss", page.Songs[0].Song)
+	assert.Equal(t, "Madness", page.Songs[2].Song)
+	assert.Nil(t, page.Prev)
+
+	result, err := repo.List(SongFilter{}, order, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "Starlight", result[4].Song)
+
+	// Курсор другого порядка сортировки не принимается
+	_, err = repo.ListAfter(SongFilter{}, DefaultSongSort, cursor, 2)
+	assert.ErrorIs(t, err, ErrValidation)
+}
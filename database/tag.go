@@ -0,0 +1,277 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository хранилище тегов песен.
+type TagRepository interface {
+	// SongTags возвращает теги песни в порядке вида и имени.
+	SongTags(songID uint) ([]models.Tag, error)
+	// AddSongTags добавляет песне теги, создавая отсутствующие. Теги, которые уже есть у песни, пропускаются.
+	AddSongTags(songID uint, tags []models.Tag) error
+	// RemoveSongTag убирает тег у песни. Если у песни нет такого тега, возвращает ошибку ErrNotFound.
+	RemoveSongTag(songID, tagID uint) error
+	// TagFacets возвращает теги песен, удовлетворяющих фильтру, с количеством песен для каждого тега.
+	// Если kind не пуст, возвращаются только теги этого вида.
+	TagFacets(filter SongFilter, kind models.TagKind) ([]models.TagFacet, error)
+}
+
+// tagNameCondition условие поиска тега по виду и имени, совпадающее с уникальным индексом.
+const tagNameCondition = "kind = ? AND lower(btrim(name)) = lower(btrim(?))"
+
+// SongTags возвращение тегов песни.
+func (r *GormRepository) SongTags(songID uint) ([]models.Tag, error) {
+
+	if err := r.songExists(r.db, songID); err != nil {
+		return nil, err
+	}
+
+	tags := []models.Tag{}
+	result := r.db.Joins("JOIN song_tags ON song_tags.tag_id = tags.id").
+		Where("song_tags.music_info_id = ?", songID).
+		Order("tags.kind, lower(tags.name), tags.id").
+		Find(&tags)
+
+	return tags, result.Error
+}
+
+// AddSongTags добавление тегов песне.
+func (r *GormRepository) AddSongTags(songID uint, tags []models.Tag) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.songExists(tx, songID); err != nil {
+			return err
+		}
+
+		rows := make([]map[string]any, 0, len(tags))
+		for _, tag := range tags {
+			if err := findOrCreateTag(tx, &tag); err != nil {
+				return err
+			}
+			rows = append(rows, map[string]any{"music_info_id": songID, "tag_id": tag.ID})
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		return tx.Table("song_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+	})
+}
+
+// RemoveSongTag удаление тега у песни.
+func (r *GormRepository) RemoveSongTag(songID, tagID uint) error {
+
+	result := r.db.Exec("DELETE FROM song_tags WHERE music_info_id = ? AND tag_id = ?", songID, tagID)
+	if result.Error != nil {
+		return fmt.Errorf("ошибка при удалении тега: %v", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: у песни id=%d нет тега id=%d", ErrNotFound, songID, tagID)
+	}
+
+	return nil
+}
+
+// TagFacets подсчет песен, удовлетворяющих фильтру, по тегам.
+func (r *GormRepository) TagFacets(filter SongFilter, kind models.TagKind) ([]models.TagFacet, error) {
+
+	songs := r.db.Model(&models.MusicInfo{}).Select("id").Scopes(filter.Scope)
+
+	query := r.db.Table("tags").
+		Select("tags.id, tags.kind, tags.name, count(*) AS count").
+		Joins("JOIN song_tags ON song_tags.tag_id = tags.id").
+		Where("song_tags.music_info_id IN (?)", songs)
+	if kind != "" {
+		query = query.Where("tags.kind = ?", string(kind))
+	}
+
+	facets := []models.TagFacet{}
+	result := query.Group("tags.id").Order("tags.kind, count DESC, lower(tags.name), tags.id").Scan(&facets)
+
+	return facets, result.Error
+}
+
+// songExists проверяет, что песня с указанным идентификатором существует.
+func (r *GormRepository) songExists(tx *gorm.DB, id uint) error {
+	result := tx.Select("id").Take(&models.MusicInfo{}, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return notFoundByID(id)
+	}
+	return result.Error
+}
+
+// findOrCreateTag заполняет идентификатор тега, создавая тег при необходимости.
+// Если тег с таким видом и именем уже существует, его имя не меняется.
+func findOrCreateTag(tx *gorm.DB, tag *models.Tag) error {
+	var existing models.Tag
+	result := tx.Where(tagNameCondition, string(tag.Kind), tag.Name).Take(&existing)
+	if result.Error == nil {
+		*tag = existing
+		return nil
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return result.Error
+	}
+
+	// Одновременное добавление одного и того же тега не считается ошибкой
+	created := models.Tag{Kind: tag.Kind, Name: tag.Name}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "lower(btrim(name))", Raw: true}},
+		DoNothing: true,
+	}).Create(&created).Error
+	if err != nil {
+		return err
+	}
+	if created.ID == 0 {
+		if err := tx.Where(tagNameCondition, string(tag.Kind), tag.Name).Take(&created).Error; err != nil {
+			return err
+		}
+	}
+
+	*tag = created
+	return nil
+}
+
+// SongTags возвращает теги песни.
+func (r *MemoryRepository) SongTags(songID uint) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.songs[songID]; !ok {
+		return nil, notFoundByID(songID)
+	}
+
+	tags := []models.Tag{}
+	for tagID := range r.songTags[songID] {
+		tags = append(tags, *r.tags[tagID])
+	}
+	sortTags(tags)
+
+	return tags, nil
+}
+
+// AddSongTags добавляет песне теги, создавая отсутствующие.
+func (r *MemoryRepository) AddSongTags(songID uint, tags []models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[songID]; !ok {
+		return notFoundByID(songID)
+	}
+
+	if r.songTags[songID] == nil {
+		r.songTags[songID] = make(map[uint]bool)
+	}
+	for _, tag := range tags {
+		r.songTags[songID][r.findOrCreateTag(tag).ID] = true
+	}
+
+	return nil
+}
+
+// RemoveSongTag убирает тег у песни.
+func (r *MemoryRepository) RemoveSongTag(songID, tagID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.songTags[songID][tagID] {
+		return fmt.Errorf("%w: у песни id=%d нет тега id=%d", ErrNotFound, songID, tagID)
+	}
+	delete(r.songTags[songID], tagID)
+
+	return nil
+}
+
+// TagFacets подсчитывает песни, удовлетворяющие фильтру, по тегам.
+func (r *MemoryRepository) TagFacets(filter SongFilter, kind models.TagKind) ([]models.TagFacet, error) {
+	songs := r.filtered(filter)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uint]int64)
+	for _, songInfo := range songs {
+		for tagID := range r.songTags[songInfo.ID] {
+			if kind == "" || r.tags[tagID].Kind == kind {
+				counts[tagID]++
+			}
+		}
+	}
+
+	facets := []models.TagFacet{}
+	for tagID, count := range counts {
+		facets = append(facets, models.TagFacet{Tag: *r.tags[tagID], Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		a, b := facets[i], facets[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return tagLess(a.Tag, b.Tag)
+	})
+
+	return facets, nil
+}
+
+// hasTags проверяет, что у песни есть все указанные теги. Вызывается под блокировкой.
+func (r *MemoryRepository) hasTags(songID uint, refs []models.TagRef) bool {
+	for _, ref := range refs {
+		found := false
+		for tagID := range r.songTags[songID] {
+			if r.tags[tagID].Matches(ref) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// findOrCreateTag возвращает тег с тем же видом и именем, создавая его при необходимости. Вызывается под блокировкой.
+func (r *MemoryRepository) findOrCreateTag(tag models.Tag) *models.Tag {
+	for _, existing := range r.tags {
+		if existing.Matches(models.TagRef{Kind: tag.Kind, Name: tag.Name}) {
+			return existing
+		}
+	}
+
+	created := &models.Tag{ID: r.nextTagID, Kind: tag.Kind, Name: tag.Name}
+	r.tags[created.ID] = created
+	r.nextTagID++
+
+	return created
+}
+
+// sortTags упорядочивает теги по виду и имени так же, как SongTags в PostgreSQL.
+func sortTags(tags []models.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Kind != tags[j].Kind {
+			return tags[i].Kind < tags[j].Kind
+		}
+		return tagLess(tags[i], tags[j])
+	})
+}
+
+// tagLess сравнивает теги по имени без учета регистра и идентификатору.
+func tagLess(a, b models.Tag) bool {
+	if strings.ToLower(a.Name) != strings.ToLower(b.Name) {
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+	return a.ID < b.ID
+}
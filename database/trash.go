@@ -0,0 +1,211 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"music-info/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TrashRepository корзина удаленных песен. Удаление песни только помечает ее временем удаления DeletedAt:
+// песня пропадает из остальных запросов, но ее можно восстановить, пока она не удалена окончательно.
+type TrashRepository interface {
+	// DeletedSongs возвращает страницу удаленных песен, начиная с удаленных последними.
+	DeletedSongs(page, limit int) ([]models.MusicInfo, error)
+	// RestoreSong восстанавливает удаленную песню и заново определяет ее исполнителя.
+	// Если пара группы и названия уже занята другой песней, возвращает *DuplicateSongError.
+	RestoreSong(id uint) error
+	// PurgeSong окончательно удаляет песню из корзины вместе с тегами, синхронизированным текстом,
+	// историей правок и треками релизов. Записи плейлистов остаются без песни.
+	PurgeSong(id uint) error
+	// PurgeDeletedSongs окончательно удаляет песни, удаленные раньше before, и возвращает их количество.
+	PurgeDeletedSongs(before time.Time) (int64, error)
+}
+
+// notFoundDeleted ошибка отсутствия песни в корзине.
+func notFoundDeleted(id uint) error {
+	return fmt.Errorf("%w: в корзине нет песни id=%d", ErrNotFound, id)
+}
+
+// DeletedSongs возвращение удаленных песен.
+func (r *GormRepository) DeletedSongs(page, limit int) ([]models.MusicInfo, error) {
+
+	songs := []models.MusicInfo{}
+	result := r.db.Unscoped().Select(append(songColumns, "deleted_at")).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&songs)
+
+	return songs, result.Error
+}
+
+// RestoreSong восстановление удаленной песни.
+func (r *GormRepository) RestoreSong(id uint) error {
+
+	var songInfo models.MusicInfo
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select(songColumns).
+			Where("deleted_at IS NOT NULL").Take(&songInfo, id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return notFoundDeleted(id)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Исполнитель мог быть удален или переименован, пока песня была в корзине
+		if err := linkArtist(tx, &songInfo); err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&models.MusicInfo{}).Where("id = ?", id).
+			Updates(map[string]any{"deleted_at": nil, "artist_id": songInfo.ArtistID}).Error
+	})
+
+	return r.conflictError(err, songInfo.Group, songInfo.Song)
+}
+
+// PurgeSong окончательное удаление песни из корзины.
+func (r *GormRepository) PurgeSong(id uint) error {
+
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.MusicInfo{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("ошибка при удалении записи: %v", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundDeleted(id)
+	}
+
+	return nil
+}
+
+// PurgeDeletedSongs окончательное удаление песен, удаленных раньше before.
+func (r *GormRepository) PurgeDeletedSongs(before time.Time) (int64, error) {
+
+	result := r.db.Unscoped().Where("deleted_at < ?", before).Delete(&models.MusicInfo{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("ошибка при очистке корзины: %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeletedSongs возвращает удаленные песни, начиная с удаленных последними.
+func (r *MemoryRepository) DeletedSongs(page, limit int) ([]models.MusicInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs := make([]models.MusicInfo, 0, len(r.trash))
+	for _, songInfo := range r.trash {
+		songs = append(songs, *songInfo)
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		if !songs[i].DeletedAt.Time.Equal(songs[j].DeletedAt.Time) {
+			return songs[i].DeletedAt.Time.After(songs[j].DeletedAt.Time)
+		}
+		return songs[i].ID > songs[j].ID
+	})
+
+	offset := (page - 1) * limit
+	if offset >= len(songs) {
+		return []models.MusicInfo{}, nil
+	}
+	end := offset + limit
+	if end > len(songs) {
+		end = len(songs)
+	}
+
+	return songs[offset:end], nil
+}
+
+// RestoreSong восстанавливает удаленную песню.
+func (r *MemoryRepository) RestoreSong(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	songInfo, ok := r.trash[id]
+	if !ok {
+		return notFoundDeleted(id)
+	}
+	if err := r.checkUnique(id, songInfo.Group, songInfo.Song); err != nil {
+		return err
+	}
+
+	r.linkArtist(songInfo)
+	songInfo.DeletedAt = gorm.DeletedAt{}
+	songInfo.UpdatedAt = time.Now()
+	delete(r.trash, id)
+	r.songs[id] = songInfo
+
+	return nil
+}
+
+// PurgeSong окончательно удаляет песню из корзины.
+func (r *MemoryRepository) PurgeSong(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trash[id]; !ok {
+		return notFoundDeleted(id)
+	}
+	r.purge(id)
+
+	return nil
+}
+
+// PurgeDeletedSongs окончательно удаляет песни, удаленные раньше before.
+func (r *MemoryRepository) PurgeDeletedSongs(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, songInfo := range r.trash {
+		if songInfo.DeletedAt.Time.Before(before) {
+			r.purge(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// trashSong переносит песню в корзину. Вызывается под блокировкой.
+func (r *MemoryRepository) trashSong(id uint, now time.Time) {
+	songInfo := r.songs[id]
+	songInfo.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	delete(r.songs, id)
+	r.trash[id] = songInfo
+}
+
+// purge удаляет песню из корзины вместе со связанными с ней данными, как внешние ключи в базе:
+// треки релизов удаляются, записи плейлистов остаются без ссылки на песню. Вызывается под блокировкой.
+func (r *MemoryRepository) purge(id uint) {
+	delete(r.trash, id)
+	delete(r.songTags, id)
+	delete(r.lyrics, id)
+	delete(r.revisions, id)
+
+	for _, album := range r.albums {
+		tracks := album.Tracks[:0]
+		for _, track := range album.Tracks {
+			if track.SongID != id {
+				tracks = append(tracks, track)
+			}
+		}
+		album.Tracks = tracks
+	}
+	for _, playlist := range r.playlists {
+		for i := range playlist.Entries {
+			if songID := playlist.Entries[i].SongID; songID != nil && *songID == id {
+				playlist.Entries[i].SongID = nil
+			}
+		}
+	}
+}
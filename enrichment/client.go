@@ -0,0 +1,134 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"music-info/models"
+)
+
+// Ошибки клиента относятся к ErrUpstream.
+var (
+	// ErrUpstream ошибка обращения к внешнему сервису.
+	ErrUpstream = errors.New("ошибка внешнего сервиса")
+	// ErrBadRequest возвращается, если внешний сервис отклонил запрос (ответ 400).
+	ErrBadRequest = fmt.Errorf("%w: внешний сервис отклонил запрос", ErrUpstream)
+	// ErrUnavailable возвращается, если внешний сервис ответил ошибкой или некорректными данными.
+	ErrUnavailable = fmt.Errorf("%w: внешний сервис недоступен", ErrUpstream)
+	// ErrTimeout возвращается, если внешний сервис не ответил за отведенное время.
+	ErrTimeout = fmt.Errorf("%w: превышено время ожидания ответа", ErrUpstream)
+)
+
+// SongDetail информация о песне, возвращаемая внешним сервисом.
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Enricher дополняет информацию о песне недостающими данными.
+type Enricher interface {
+	Enrich(ctx context.Context, songInfo *models.MusicInfo) error
+}
+
+// Client клиент внешнего сервиса информации о песнях.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient создает клиент внешнего сервиса с указанным адресом и таймаутом запросов.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Info запрашивает информацию о песне во внешнем сервисе.
+func (c *Client) Info(ctx context.Context, group, song string) (*SongDetail, error) {
+	query := url.Values{}
+	query.Set("group", group)
+	query.Set("song", song)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/info?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании запроса: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+			return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: group=%s, song=%s: %s", ErrBadRequest, group, song, readMessage(resp.Body))
+	default:
+		return nil, fmt.Errorf("%w: статус %d: %s", ErrUnavailable, resp.StatusCode, readMessage(resp.Body))
+	}
+
+	var detail SongDetail
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: некорректный ответ: %v", ErrUnavailable, err)
+	}
+
+	return &detail, nil
+}
+
+// Enrich заполняет пустые поля ReleaseDate, Text и Link данными внешнего сервиса.
+// Поля, переданные клиентом, не перезаписываются.
+func (c *Client) Enrich(ctx context.Context, songInfo *models.MusicInfo) error {
+	if !songInfo.NeedsEnrichment() {
+		return nil
+	}
+
+	detail, err := c.Info(ctx, songInfo.Group, songInfo.Song)
+	if err != nil {
+		return err
+	}
+
+	if songInfo.ReleaseDate.IsZero() {
+		releaseDate, err := models.ParseReleaseDate(detail.ReleaseDate)
+		if err != nil {
+			return fmt.Errorf("%w: некорректный ответ: %v", ErrUnavailable, err)
+		}
+		songInfo.ReleaseDate = releaseDate
+	}
+	if songInfo.Text == "" {
+		songInfo.Text = detail.Text
+	}
+	if songInfo.Link == "" {
+		songInfo.Link = detail.Link
+	}
+
+	return nil
+}
+
+// isTimeout проверяет, что ошибка вызвана истечением таймаута.
+func isTimeout(err error) bool {
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
+// readMessage читает начало тела ответа для включения в текст ошибки.
+func readMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 512))
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,100 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientEnrich(t *testing.T) {
+
+	// Создаем тестовый внешний сервис
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/info", r.URL.Path)
+		assert.Equal(t, "Muse", r.URL.Query().Get("group"))
+		assert.Equal(t, "Supermassive Black Hole", r.URL.Query().Get("song"))
+
+		json.NewEncoder(w).Encode(SongDetail{
+			ReleaseDate: "16.07.2006",
+			Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+			Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", time.Second)
+
+	// Проверяем метод
+	songInfo := models.MusicInfo{
+		Group: "Muse",
+		Song:  "Supermassive Black Hole",
+		Link:  "https://example.com/muse",
+	}
+	err := client.Enrich(context.Background(), &songInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "16.07.2006", songInfo.ReleaseDate.String())
+	assert.Equal(t, "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?", songInfo.Text)
+	assert.Equal(t, "https://example.com/muse", songInfo.Link)
+}
+
+func TestClientEnrichSkipsFilledSong(t *testing.T) {
+
+	// Создаем тестовый внешний сервис
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("внешний сервис не должен вызываться")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, time.Second)
+
+	// Проверяем метод
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := client.Enrich(context.Background(), &songInfo)
+	assert.NoError(t, err)
+}
+
+func TestClientInfoErrors(t *testing.T) {
+
+	// Создаем тестовый внешний сервис
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("song") {
+		case "bad":
+			http.Error(w, "bad request", http.StatusBadRequest)
+		case "fail":
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.Write([]byte("not json"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 50*time.Millisecond)
+
+	// Проверяем метод
+	_, err := client.Info(context.Background(), "Muse", "bad")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.Info(context.Background(), "Muse", "fail")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	_, err = client.Info(context.Background(), "Muse", "slow")
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	_, err = client.Info(context.Background(), "Muse", "garbage")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
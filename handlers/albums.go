@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+)
+
+// albumID возвращает идентификатор релиза из пути запроса.
+func albumID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный идентификатор релиза")
+	}
+	return uint(id), nil
+}
+
+// AlbumCreateHandler создает релиз.
+// @Summary Создать релиз
+// @Description Добавляет релиз исполнителя вместе со списком треков. Трек без номера диска относится к первому диску,
+// @Description трек без номера получает номер, следующий за предыдущим треком того же диска
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param album body models.Album true "Данные релиза и треки"
+// @Success 201 {object} models.Album "Релиз создан"
+// @Header 201 {string} Location "Адрес созданного релиза"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON, данные не прошли проверку, исполнитель или песни не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums [post]
+func (h *Handler) AlbumCreateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var album models.Album
+	if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	album.Normalize()
+	if err := album.Validate(); err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	if err := h.Albums.CreateAlbum(&album); err != nil {
+		log.Printf("Ошибка при создании релиза: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	created, err := h.Albums.AlbumByID(album.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении релиза: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Релиз создан: id=%d, title=%s, треков: %d\n", created.ID, created.Title, len(created.Tracks))
+	w.Header().Set("Location", "/albums/"+strconv.FormatUint(uint64(created.ID), 10))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// AlbumDetailHandler возвращает релиз со списком треков.
+// @Summary Получить релиз
+// @Description Возвращает релиз с треками в порядке дисков и номеров. Треки удаленных песен не возвращаются
+// @Tags albums
+// @Produce json
+// @Param id path int true "Идентификатор релиза"
+// @Success 200 {object} models.Album "Успешный ответ с информацией о релизе"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Релиз не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [get]
+func (h *Handler) AlbumDetailHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := albumID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	album, err := h.Albums.AlbumByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении релиза: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(album)
+}
+
+// AlbumTracksHandler заменяет список треков релиза.
+// @Summary Заменить треки релиза
+// @Description Заменяет список треков релиза целиком, что позволяет добавлять, удалять и переставлять треки.
+// @Description Номера дисков и треков заполняются так же, как при создании релиза
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор релиза"
+// @Param tracks body []models.AlbumTrack true "Новый список треков"
+// @Success 200 {object} models.Album "Успешный ответ с обновлённым релизом"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON, позиции или песни повторяются, песни не найдены"
+// @Failure 404 {object} ErrorResponse "Релиз не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id}/tracks [put]
+func (h *Handler) AlbumTracksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := albumID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	var tracks []models.AlbumTrack
+	if err := json.NewDecoder(r.Body).Decode(&tracks); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	tracks = models.NumberTracks(tracks)
+	if err := models.ValidateTracks(tracks); err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	if err := h.Albums.ReplaceTracks(id, tracks); err != nil {
+		log.Printf("Ошибка при обновлении треков релиза: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	album, err := h.Albums.AlbumByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении релиза: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Треки релиза обновлены: id=%d, треков: %d\n", id, len(album.Tracks))
+	json.NewEncoder(w).Encode(album)
+}
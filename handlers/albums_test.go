@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumCreateHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	starlight := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, h.Songs.Create(&starlight))
+	body := fmt.Sprintf(`{"title":"Black Holes and Revelations","artistId":%d,"releaseDate":"2006-07-03","type":"LP","tracks":[{"songId":%d},{"songId":%d}]}`,
+		*songInfo.ArtistID, starlight.ID, songInfo.ID)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/albums", bytes.NewBufferString(body)))
+
+	var album models.Album
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &album))
+	assert.Equal(t, fmt.Sprintf("/albums/%d", album.ID), rec.Header().Get("Location"))
+	assert.Equal(t, "03.07.2006", album.ReleaseDate.String())
+	assert.Equal(t, 2, len(album.Tracks))
+	assert.Equal(t, 1, album.Tracks[0].Track)
+	assert.Equal(t, "Starlight", album.Tracks[0].Song.Song)
+	assert.Equal(t, 2, album.Tracks[1].Track)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/albums/%d", album.ID), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	body = fmt.Sprintf(`{"title":"Origin of Symmetry","artistId":%d,"type":"LP","tracks":[{"songId":999}]}`, *songInfo.ArtistID)
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/albums", bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/albums", bytes.NewBufferString(`{"title":"Showbiz","artistId":1,"type":"album"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/albums/999", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAlbumTracksHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	starlight := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, h.Songs.Create(&starlight))
+	album := models.Album{
+		Title:    "Black Holes and Revelations",
+		ArtistID: *songInfo.ArtistID,
+		Type:     models.AlbumTypeLP,
+		Tracks:   []models.AlbumTrack{{SongID: starlight.ID, Disc: 1, Track: 1}, {SongID: songInfo.ID, Disc: 1, Track: 2}},
+	}
+	assert.NoError(t, h.Albums.CreateAlbum(&album))
+	target := fmt.Sprintf("/albums/%d/tracks", album.ID)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	body := fmt.Sprintf(`[{"songId":%d},{"songId":%d,"disc":2}]`, songInfo.ID, starlight.ID)
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", target, bytes.NewBufferString(body)))
+
+	var result models.Album
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, 2, len(result.Tracks))
+	assert.Equal(t, songInfo.ID, result.Tracks[0].SongID)
+	assert.Equal(t, 2, result.Tracks[1].Disc)
+	assert.Equal(t, 1, result.Tracks[1].Track)
+
+	rec = httptest.NewRecorder()
+	body = fmt.Sprintf(`[{"songId":%d,"track":1},{"songId":%d,"track":1}]`, songInfo.ID, starlight.ID)
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", target, bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/albums/999/tracks", bytes.NewBufferString("[]")))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
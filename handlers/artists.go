@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+)
+
+// artistID возвращает идентификатор исполнителя из пути запроса.
+func artistID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный идентификатор исполнителя")
+	}
+	return uint(id), nil
+}
+
+// decodeArtist разбирает и проверяет исполнителя из тела запроса.
+func decodeArtist(w http.ResponseWriter, r *http.Request) (*models.Artist, bool) {
+	var artist models.Artist
+	if err := json.NewDecoder(r.Body).Decode(&artist); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return nil, false
+	}
+
+	artist.Normalize()
+	if err := artist.Validate(); err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return nil, false
+	}
+
+	return &artist, true
+}
+
+// ArtistCreateHandler создает исполнителя.
+// @Summary Создать исполнителя
+// @Description Добавляет исполнителя. Песни, группа которых совпадает с именем или псевдонимом исполнителя, связываются с ним при сохранении
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param artist body models.Artist true "Данные исполнителя"
+// @Success 201 {object} models.Artist "Исполнитель создан"
+// @Header 201 {string} Location "Адрес созданного исполнителя"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или данные не прошли проверку"
+// @Failure 409 {object} ErrorResponse "Исполнитель с таким именем уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists [post]
+func (h *Handler) ArtistCreateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	artist, ok := decodeArtist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Artists.CreateArtist(artist); err != nil {
+		log.Printf("Ошибка при создании исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Исполнитель создан: id=%d, name=%s\n", artist.ID, artist.Name)
+	w.Header().Set("Location", "/artists/"+strconv.FormatUint(uint64(artist.ID), 10))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(artist)
+}
+
+// GetArtistsHandler возвращает список исполнителей.
+// @Summary Получить список исполнителей
+// @Description Возвращает исполнителей в порядке имени с фильтрацией по подстроке имени и пагинацией
+// @Tags artists
+// @Produce json
+// @Param name query string false "Подстрока имени исполнителя"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице, не более 100" default(10)
+// @Success 200 {array} models.Artist "Успешный ответ со списком исполнителей"
+// @Failure 400 {object} ErrorResponse "Неверные параметры постраничного вывода"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists [get]
+func (h *Handler) GetArtistsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	artists, err := h.Artists.ListArtists(r.URL.Query().Get("name"), page, limit)
+	if err != nil {
+		log.Printf("Ошибка при получении исполнителей: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Получено %d исполнителей\n", len(artists))
+	json.NewEncoder(w).Encode(artists)
+}
+
+// ArtistDetailHandler возвращает исполнителя по идентификатору.
+// @Summary Получить исполнителя
+// @Tags artists
+// @Produce json
+// @Param id path int true "Идентификатор исполнителя"
+// @Success 200 {object} models.Artist "Успешный ответ с информацией об исполнителе"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Исполнитель не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists/{id} [get]
+func (h *Handler) ArtistDetailHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := artistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	artist, err := h.Artists.ArtistByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(artist)
+}
+
+// ArtistReplaceHandler заменяет информацию об исполнителе.
+// @Summary Заменить информацию об исполнителе
+// @Description Заменяет все поля исполнителя. При смене имени поле group всех песен исполнителя тоже меняется
+// @Tags artists
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор исполнителя"
+// @Param artist body models.Artist true "Новые данные исполнителя"
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} models.Artist "Успешный ответ с обновлённой информацией об исполнителе"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или данные не прошли проверку"
+// @Failure 404 {object} ErrorResponse "Исполнитель не найден"
+// @Failure 409 {object} ErrorResponse "Имя занято другим исполнителем или у песен исполнителя совпадут группа и название"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists/{id} [put]
+func (h *Handler) ArtistReplaceHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := artistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	artist, ok := decodeArtist(w, r)
+	if !ok {
+		return
+	}
+	artist.ChangedBy = changeAuthor(r)
+
+	if err := h.Artists.ReplaceArtist(id, artist); err != nil {
+		log.Printf("Ошибка при обновлении исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	updated, err := h.Artists.ArtistByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Исполнитель обновлен: id=%d\n", id)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// ArtistDeleteHandler удаляет исполнителя.
+// @Summary Удалить исполнителя
+// @Description Удаляет исполнителя, у которого нет песен и релизов. Песни в корзине тоже учитываются: их нужно восстановить и перенести или удалить окончательно
+// @Tags artists
+// @Param id path int true "Идентификатор исполнителя"
+// @Success 204 "Исполнитель удален"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Исполнитель не найден"
+// @Failure 409 {object} ErrorResponse "У исполнителя есть песни, в том числе в корзине, или релизы"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists/{id} [delete]
+func (h *Handler) ArtistDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := artistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Artists.DeleteArtist(id); err != nil {
+		log.Printf("Ошибка при удалении исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Исполнитель удален: id=%d\n", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ArtistSongsHandler возвращает песни исполнителя.
+// @Summary Получить песни исполнителя
+// @Description Возвращает песни исполнителя. Поддерживает те же фильтры, сортировку и пагинацию, что и GET /songs
+// @Tags artists
+// @Produce json
+// @Param id path int true "Идентификатор исполнителя"
+// @Param sort query string false "Порядок сортировки, как в GET /songs" default(group,song)
+// @Param cursor query string false "Курсор страницы из заголовка Link"
+// @Param page query int false "Номер страницы. Несовместим с cursor"
+// @Param limit query int false "Количество записей на странице, не более 100" default(10)
+// @Param total query bool false "Вернуть общее количество записей в заголовке X-Total-Count"
+// @Success 200 {array} models.MusicInfo "Успешный ответ со списком песен"
+// @Header 200 {string} Link "Ссылки на следующую (rel=next) и предыдущую (rel=prev) страницы"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Исполнитель не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /artists/{id}/songs [get]
+func (h *Handler) ArtistSongsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := artistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.Artists.ArtistByID(id); err != nil {
+		log.Printf("Ошибка при получении исполнителя: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	filter, err := parseSongFilter(r.URL.Query())
+	if err != nil {
+		log.Printf("Ошибка в параметрах фильтра: %v\n", err)
+		writeError(w, err)
+		return
+	}
+	filter.ArtistID = &id
+
+	h.writeSongList(w, r, filter)
+}
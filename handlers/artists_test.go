@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtistCreateHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	body := []byte(`{"name":"Muse","aliases":["Muse UK"],"country":"gb","formedYear":1994,"members":["Matthew Bellamy","Chris Wolstenholme","Dominic Howard"]}`)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/artists", bytes.NewBuffer(body)))
+
+	var artist models.Artist
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &artist))
+	assert.Equal(t, fmt.Sprintf("/artists/%d", artist.ID), rec.Header().Get("Location"))
+	assert.Equal(t, "GB", artist.Country)
+	assert.Equal(t, 3, len(artist.Members))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/artists", bytes.NewBufferString(`{"name":"muse"}`)))
+
+	var response ErrorResponse
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, artist.ID, response.ID)
+	assert.Equal(t, fmt.Sprintf("/artists/%d", artist.ID), rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/artists", bytes.NewBufferString(`{"name":"Queen","formedYear":3000}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestArtistReplaceHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	target := fmt.Sprintf("/artists/%d", *songInfo.ArtistID)
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", target, bytes.NewBufferString(`{"name":"MUSE","country":"GB"}`)))
+
+	var artist models.Artist
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &artist))
+	assert.Equal(t, "MUSE", artist.Name)
+	assert.Equal(t, []string{}, artist.Aliases)
+
+	result, err := h.Songs.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "MUSE", result.Group)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/artists/999", bytes.NewBufferString(`{"name":"Queen"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestArtistSongsHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "muse", Song: "Uprising", Text: "Paranoia is in bloom"}))
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}))
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/artists/%d/songs?sort=-song&total=true", *songInfo.ArtistID), nil))
+
+	var songs []models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &songs))
+	assert.Equal(t, 2, len(songs))
+	assert.Equal(t, "Uprising", songs[0].Song)
+	assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/artists/999/songs", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestArtistDeleteHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	h := NewHandler(repo, nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	target := fmt.Sprintf("/artists/%d", *songInfo.ArtistID)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", target, nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Песня в корзине тоже не дает удалить исполнителя
+	assert.NoError(t, h.Songs.DeleteByID(songInfo.ID))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", target, nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	assert.NoError(t, repo.PurgeSong(songInfo.ID))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", target, nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/artists", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
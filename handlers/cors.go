@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// corsMethods методы, разрешенные в ответе на предварительный запрос CORS.
+const corsMethods = "GET, POST, PUT, PATCH, DELETE"
+
+// corsExposedHeaders заголовки ответа, доступные скриптам браузера.
+const corsExposedHeaders = "Deprecation, Content-Disposition"
+
+// CORS разрешает запросы из браузера с источников origins. Значение "*" разрешает любой источник.
+// Предварительные запросы OPTIONS с разрешенных источников обрабатываются без передачи дальше.
+// Если список пуст, заголовки CORS не добавляются.
+func CORS(origins []string) func(http.Handler) http.Handler {
+	anyOrigin := slices.Contains(origins, "*")
+	return func(next http.Handler) http.Handler {
+		if len(origins) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" || (!anyOrigin && !slices.Contains(origins, strings.TrimSuffix(origin, "/"))) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+
+	// Создаем тестовые данные
+	router := mux.NewRouter()
+	router.HandleFunc("/songs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+	handler := CORS([]string{"https://app.example.com"})(router)
+
+	serve := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/songs", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Проверяем метод
+	rec := serve("GET", "https://app.example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Deprecation")
+
+	rec = serve("OPTIONS", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "Content-Type, X-User",
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "PUT")
+	assert.Equal(t, "Content-Type, X-User", rec.Header().Get("Access-Control-Allow-Headers"))
+
+	rec = serve("GET", "https://evil.example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = serve("OPTIONS", "https://evil.example.com", map[string]string{"Access-Control-Request-Method": "PUT"})
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = serve("GET", "", nil)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+
+	// Создаем тестовые данные
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest("GET", "/songs", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	CORS([]string{"*"})(next).ServeHTTP(rec, req)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = httptest.NewRecorder()
+	CORS(nil)(next).ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Vary"))
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/enrichment"
+)
+
+// Машиночитаемые коды ошибок, передаваемые клиенту в поле code.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUpstream             = "upstream_failed"
+	CodeUpstreamTimeout      = "upstream_timeout"
+	CodeInternal             = "internal_error"
+)
+
+// ErrorResponse тело ответа с ошибкой.
+// @Description Описание ошибки: сообщение, машиночитаемый код и, для конфликтов, идентификатор существующей записи.
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+	ID    uint   `json:"id,omitempty"`
+}
+
+// sendError отправляет ошибку клиенту.
+func sendError(w http.ResponseWriter, statusCode int, code, errorMessage string) {
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: errorMessage, Code: code})
+}
+
+// writeError определяет HTTP-статус и код по виду ошибки и отправляет её клиенту.
+// Текст внутренних ошибок клиенту не передается.
+func writeError(w http.ResponseWriter, err error) {
+	var duplicate *database.DuplicateSongError
+	var duplicateArtist *database.DuplicateArtistError
+
+	switch {
+	case errors.As(err, &duplicate):
+		w.Header().Set("Location", "/songs/"+strconv.FormatUint(uint64(duplicate.ID), 10))
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Песня уже существует", Code: CodeConflict, ID: duplicate.ID})
+	case errors.As(err, &duplicateArtist):
+		w.Header().Set("Location", "/artists/"+strconv.FormatUint(uint64(duplicateArtist.ID), 10))
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Исполнитель уже существует", Code: CodeConflict, ID: duplicateArtist.ID})
+	case errors.Is(err, database.ErrConflict):
+		sendError(w, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, database.ErrValidation):
+		sendError(w, http.StatusBadRequest, CodeValidation, err.Error())
+	case errors.Is(err, database.ErrNotFound):
+		sendError(w, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, enrichment.ErrTimeout):
+		sendError(w, http.StatusGatewayTimeout, CodeUpstreamTimeout, "Внешний сервис не ответил вовремя")
+	case errors.Is(err, enrichment.ErrBadRequest):
+		sendError(w, http.StatusBadRequest, CodeUpstream, "Внешний сервис не смог найти информацию о песне")
+	case errors.Is(err, enrichment.ErrUpstream):
+		sendError(w, http.StatusBadGateway, CodeUpstream, "Ошибка при обращении к внешнему сервису")
+	default:
+		log.Printf("Внутренняя ошибка: %v\n", err)
+		sendError(w, http.StatusInternalServerError, CodeInternal, "Внутренняя ошибка сервера")
+	}
+}
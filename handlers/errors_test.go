@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/enrichment"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+
+	// Создаем тестовые данные
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{database.NewValidationError(errors.New("поле 'Group' обязательно для заполнения")), http.StatusBadRequest, CodeValidation},
+		{fmt.Errorf("%w: id=1", database.ErrNotFound), http.StatusNotFound, CodeNotFound},
+		{&database.DuplicateSongError{ID: 7}, http.StatusConflict, CodeConflict},
+		{fmt.Errorf("%w: статус 500", enrichment.ErrUnavailable), http.StatusBadGateway, CodeUpstream},
+		{fmt.Errorf("%w: group=Muse", enrichment.ErrBadRequest), http.StatusBadRequest, CodeUpstream},
+		{enrichment.ErrTimeout, http.StatusGatewayTimeout, CodeUpstreamTimeout},
+		{errors.New("connection refused"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	// Проверяем метод
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		writeError(rec, c.err)
+
+		var response ErrorResponse
+		assert.Equal(t, c.status, rec.Code, c.err.Error())
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, c.code, response.Code)
+		assert.NotEmpty(t, response.Error)
+	}
+
+	// Проверяем, что текст внутренней ошибки не передается клиенту
+	rec := httptest.NewRecorder()
+	writeError(rec, errors.New("pq: password authentication failed"))
+	assert.NotContains(t, rec.Body.String(), "password")
+
+	// Проверяем идентификатор существующей записи при конфликте
+	rec = httptest.NewRecorder()
+	writeError(rec, &database.DuplicateSongError{ID: 7})
+	assert.Equal(t, "/songs/7", rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), `"id":7`)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"music-info/database"
+	"music-info/songfile"
+)
+
+// exportBatchSize количество песен, читаемых из хранилища одним запросом при выгрузке.
+const exportBatchSize = 500
+
+// exportBatchTimeout срок отправки одной части выгрузки. WriteTimeout сервера отсчитывается от начала запроса
+// и обрезал бы большую выгрузку, поэтому срок записи продлевается перед каждой частью.
+const exportBatchTimeout = time.Minute
+
+// SongExportHandler выгружает песни в файл CSV или JSON Lines.
+// @Summary Выгрузить песни
+// @Description Передает все песни, удовлетворяющие фильтру, в порядке из параметра sort. Песни читаются из хранилища
+// @Description частями и сразу отправляются клиенту, поэтому размер выгрузки не ограничен. Таймаут записи сервера
+// @Description продлевается на минуту перед отправкой каждой части.
+// @Description CSV содержит заголовок id,group,song,releaseDate,text,link,artistId,createdAt,updatedAt, многострочный текст заключается в кавычки.
+// @Description Вариант excel добавляет метку порядка байтов, переводы строк CRLF и апостроф перед значениями, похожими на формулы.
+// @Description Выгрузку csv и jsonl можно загрузить обратно через POST /songs/import
+// @Tags songs
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Формат файла" Enums(csv, excel, jsonl) default(csv)
+// @Param group query string false "Подстрока названия группы"
+// @Param group_eq query string false "Точное название группы"
+// @Param song query string false "Подстрока названия песни"
+// @Param song_eq query string false "Точное название песни"
+// @Param text query string false "Подстрока текста песни"
+// @Param has_link query bool false "Наличие ссылки"
+// @Param artist_id query int false "Идентификатор исполнителя"
+// @Param tag query []string false "Тег в виде вид:имя или имя любого вида, например genre:Rock. При повторении песня должна иметь все теги" collectionFormat(multi)
+// @Param released_from query string false "Дата выпуска не раньше"
+// @Param released_to query string false "Дата выпуска не позже"
+// @Param created_from query string false "Время создания не раньше"
+// @Param created_to query string false "Время создания не позже"
+// @Param updated_from query string false "Время изменения не раньше"
+// @Param updated_to query string false "Время изменения не позже"
+// @Param sort query string false "Порядок: поля group, song, release_date, created_at, updated_at, id через запятую, минус означает обратный порядок" default(id)
+// @Success 200 {file} file "Файл с песнями"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/export [get]
+func (h *Handler) SongExportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := songfile.FormatCSV
+	if value := query.Get("format"); value != "" {
+		var err error
+		if format, err = songfile.ParseFormat(value); err != nil {
+			writeJSONError(w, database.NewValidationError(err))
+			return
+		}
+	}
+	filter, err := parseSongFilter(query)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	order := database.SongSort{{Field: "id"}}
+	if value := query.Get("sort"); value != "" {
+		if order, err = database.ParseSongSort(value); err != nil {
+			writeJSONError(w, err)
+			return
+		}
+	}
+
+	// Первая часть читается до отправки заголовков, чтобы ошибка хранилища вернулась клиенту как ошибка.
+	page, err := h.Songs.ListAfter(filter, order, nil, exportBatchSize)
+	if err != nil {
+		log.Printf("Ошибка при получении данных: %v\n", err)
+		writeJSONError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "songs."+format.Extension()))
+	writer, err := songfile.NewWriter(w, format)
+	if err != nil {
+		log.Printf("Ошибка при выгрузке песен: %v\n", err)
+		return
+	}
+	controller := http.NewResponseController(w)
+
+	// После отправки заголовков об ошибке можно только сообщить в журнале: клиент получит неполный файл.
+	exported := 0
+	for {
+		if err := controller.SetWriteDeadline(time.Now().Add(exportBatchTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Ошибка при выгрузке песен: %v\n", err)
+			return
+		}
+		for i := range page.Songs {
+			if err := writer.Write(&page.Songs[i]); err != nil {
+				log.Printf("Ошибка при выгрузке песен: %v\n", err)
+				return
+			}
+		}
+		exported += len(page.Songs)
+		if err := writer.Flush(); err != nil {
+			log.Printf("Ошибка при выгрузке песен: %v\n", err)
+			return
+		}
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Ошибка при выгрузке песен: %v\n", err)
+			return
+		}
+
+		if page.Next == nil {
+			break
+		}
+		if err := r.Context().Err(); err != nil {
+			log.Printf("Выгрузка песен прервана клиентом после %d песен\n", exported)
+			return
+		}
+		if page, err = h.Songs.ListAfter(filter, order, page.Next, exportBatchSize); err != nil {
+			log.Printf("Ошибка при получении данных: выгрузка прервана после %d песен: %v\n", exported, err)
+			return
+		}
+	}
+
+	log.Printf("Выгружено песен в %s: %d\n", format, exported)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongExportHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	for i := 1; i <= exportBatchSize+2; i++ {
+		songInfo := models.MusicInfo{Group: "Muse", Song: fmt.Sprintf("Song %03d", i), Text: "Line 1\nLine 2"}
+		assert.NoError(t, h.Songs.Create(&songInfo))
+	}
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}))
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	h.SongExportHandler(rec, httptest.NewRequest("GET", "/songs/export?group=muse", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="songs.csv"`, rec.Header().Get("Content-Disposition"))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "id,group,song,releaseDate,text,link,artistId,createdAt,updatedAt\n1,Muse,Song 001,,\"Line 1\nLine 2\","))
+	assert.Equal(t, exportBatchSize+2, strings.Count(body, "\"Line 1\n"))
+	assert.NotContains(t, body, "Queen")
+
+	rec = httptest.NewRecorder()
+	h.SongExportHandler(rec, httptest.NewRequest("GET", "/songs/export?format=jsonl&sort=-song&song=Song", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="songs.jsonl"`, rec.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	assert.Equal(t, exportBatchSize+2, len(lines))
+	assert.Contains(t, lines[0], fmt.Sprintf(`"song":"Song %03d"`, exportBatchSize+2))
+
+	rec = httptest.NewRecorder()
+	h.SongExportHandler(rec, httptest.NewRequest("GET", "/songs/export?format=excel&group=queen", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "\xEF\xBB\xBFid,"))
+	assert.Contains(t, rec.Body.String(), "Bohemian Rhapsody")
+
+	rec = httptest.NewRecorder()
+	h.SongExportHandler(rec, httptest.NewRequest("GET", "/songs/export?format=xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	h.SongExportHandler(rec, httptest.NewRequest("GET", "/songs/export?sort=title", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// slowSongs хранилище, читающее каждую часть выгрузки с задержкой.
+type slowSongs struct {
+	database.SongRepository
+	delay time.Duration
+}
+
+func (s slowSongs) ListAfter(filter database.SongFilter, order database.SongSort, cursor *database.Cursor, limit int) (database.SongPage, error) {
+	time.Sleep(s.delay)
+	return s.SongRepository.ListAfter(filter, order, cursor, limit)
+}
+
+func TestSongExportHandlerWriteTimeout(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	for i := 1; i <= 2*exportBatchSize+1; i++ {
+		songInfo := models.MusicInfo{Group: "Muse", Song: fmt.Sprintf("Song %04d", i), Text: "Ooh"}
+		assert.NoError(t, h.Songs.Create(&songInfo))
+	}
+	h.Songs = slowSongs{SongRepository: h.Songs, delay: 100 * time.Millisecond}
+
+	server := httptest.NewUnstartedServer(NewRouter(h))
+	server.Config.WriteTimeout = 150 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// Проверяем метод: выгрузка длится дольше WriteTimeout, но передается полностью
+	resp, err := http.Get(server.URL + "/songs/export?format=jsonl")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*exportBatchSize+1, strings.Count(string(body), "\n"))
+}
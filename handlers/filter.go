@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"music-info/database"
+	"music-info/models"
+)
+
+// Параметры фильтрации списка песен.
+const (
+	filterGroup        = "group"
+	filterGroupEq      = "group_eq"
+	filterSong         = "song"
+	filterSongEq       = "song_eq"
+	filterText         = "text"
+	filterHasLink      = "has_link"
+	filterArtistID     = "artist_id"
+	filterTag          = "tag"
+	filterReleasedFrom = "released_from"
+	filterReleasedTo   = "released_to"
+	filterCreatedFrom  = "created_from"
+	filterCreatedTo    = "created_to"
+	filterUpdatedFrom  = "updated_from"
+	filterUpdatedTo    = "updated_to"
+)
+
+// parseSongFilter разбирает параметры запроса в фильтр списка песен.
+// Ошибки в значениях параметров возвращаются как ошибки валидации.
+func parseSongFilter(query url.Values) (database.SongFilter, error) {
+	filter := database.SongFilter{
+		Group:   query.Get(filterGroup),
+		GroupEq: query.Get(filterGroupEq),
+		Song:    query.Get(filterSong),
+		SongEq:  query.Get(filterSongEq),
+		Text:    query.Get(filterText),
+	}
+
+	var errs []error
+
+	if value := query.Get(filterHasLink); value != "" {
+		hasLink, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("параметр '%s' должен быть true или false", filterHasLink))
+		} else {
+			filter.HasLink = &hasLink
+		}
+	}
+
+	if value := query.Get(filterArtistID); value != "" {
+		artistID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || artistID == 0 {
+			errs = append(errs, fmt.Errorf("параметр '%s' должен быть идентификатором исполнителя", filterArtistID))
+		} else {
+			id := uint(artistID)
+			filter.ArtistID = &id
+		}
+	}
+
+	for _, value := range query[filterTag] {
+		ref, err := models.ParseTagRef(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("параметр '%s': %v", filterTag, err))
+		} else {
+			filter.Tags = append(filter.Tags, ref)
+		}
+	}
+
+	var err error
+	if filter.ReleasedFrom, err = parseReleaseBound(query, filterReleasedFrom, false); err != nil {
+		errs = append(errs, err)
+	}
+	if filter.ReleasedTo, err = parseReleaseBound(query, filterReleasedTo, true); err != nil {
+		errs = append(errs, err)
+	}
+	if filter.CreatedFrom, err = parseTimeBound(query, filterCreatedFrom, false); err != nil {
+		errs = append(errs, err)
+	}
+	if filter.CreatedTo, err = parseTimeBound(query, filterCreatedTo, true); err != nil {
+		errs = append(errs, err)
+	}
+	if filter.UpdatedFrom, err = parseTimeBound(query, filterUpdatedFrom, false); err != nil {
+		errs = append(errs, err)
+	}
+	if filter.UpdatedTo, err = parseTimeBound(query, filterUpdatedTo, true); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs,
+		checkRange(filterReleasedFrom, filterReleasedTo, filter.ReleasedFrom, filter.ReleasedTo),
+		checkRange(filterCreatedFrom, filterCreatedTo, filter.CreatedFrom, filter.CreatedTo),
+		checkRange(filterUpdatedFrom, filterUpdatedTo, filter.UpdatedFrom, filter.UpdatedTo),
+	)
+
+	if err := errors.Join(errs...); err != nil {
+		return database.SongFilter{}, database.NewValidationError(err)
+	}
+
+	return filter, nil
+}
+
+// parseReleaseBound разбирает границу диапазона даты выпуска в любом из форматов даты выпуска.
+// Для верхней границы неполная дата означает конец года или месяца.
+func parseReleaseBound(query url.Values, name string, upper bool) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := models.ParseReleaseDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("параметр '%s': %v", name, err)
+	}
+
+	bound := *date.Date
+	if upper {
+		bound = date.PeriodEnd()
+	}
+	return &bound, nil
+}
+
+// parseTimeBound разбирает границу диапазона времени в формате RFC3339 или YYYY-MM-DD.
+// Для верхней границы дата без времени означает конец дня.
+func parseTimeBound(query url.Values, name string, upper bool) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	if bound, err := time.Parse(time.RFC3339, value); err == nil {
+		return &bound, nil
+	}
+
+	bound, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("параметр '%s' должен быть в формате RFC3339 или YYYY-MM-DD", name)
+	}
+	if upper {
+		bound = bound.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &bound, nil
+}
+
+// checkRange проверяет, что нижняя граница диапазона не больше верхней.
+func checkRange(fromName, toName string, from, to *time.Time) error {
+	if from != nil && to != nil && from.After(*to) {
+		return fmt.Errorf("параметр '%s' не может быть больше '%s'", fromName, toName)
+	}
+	return nil
+}
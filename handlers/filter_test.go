@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSongFilter(t *testing.T) {
+
+	// Создаем тестовые данные
+	query := url.Values{
+		"group_eq":      {"Muse"},
+		"song":          {"hole"},
+		"has_link":      {"false"},
+		"released_from": {"2006"},
+		"released_to":   {"07.2006"},
+		"created_to":    {"2024-01-31"},
+		"updated_from":  {"2024-01-01T10:00:00Z"},
+	}
+
+	// Проверяем метод
+	filter, err := parseSongFilter(query)
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", filter.GroupEq)
+	assert.Equal(t, "hole", filter.Song)
+	assert.False(t, *filter.HasLink)
+	assert.Equal(t, time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC), *filter.ReleasedFrom)
+	assert.Equal(t, time.Date(2006, 7, 31, 0, 0, 0, 0, time.UTC), *filter.ReleasedTo)
+	assert.Equal(t, time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC), *filter.CreatedTo)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), *filter.UpdatedFrom)
+	assert.Nil(t, filter.CreatedFrom)
+}
+
+func TestParseSongFilterInvalid(t *testing.T) {
+
+	// Создаем тестовые данные
+	cases := []url.Values{
+		{"has_link": {"maybe"}},
+		{"released_from": {"вчера"}},
+		{"created_from": {"01.01.2024"}},
+		{"released_from": {"2007"}, "released_to": {"2006"}},
+		{"updated_from": {"2024-02-01"}, "updated_to": {"2024-01-01"}},
+	}
+
+	// Проверяем метод
+	for _, query := range cases {
+		_, err := parseSongFilter(query)
+		assert.ErrorIs(t, err, database.ErrValidation, query.Encode())
+	}
+}
+
+func TestGetSongsHandlerFilters(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: models.MustParseReleaseDate("16.07.2006"), Text: "Ooh baby", Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw"},
+		{Group: "Muse", Song: "Uprising", ReleaseDate: models.MustParseReleaseDate("2009"), Text: "Paranoia is in bloom"},
+		{Group: "Queen", Song: "Bohemian Rhapsody", ReleaseDate: models.MustParseReleaseDate("31.10.1975"), Text: "Is this the real life? 100%"},
+	} {
+		assert.NoError(t, h.Songs.Create(&songInfo))
+	}
+
+	router := NewRouter(h)
+
+	cases := []struct {
+		query string
+		songs []string
+	}{
+		{"group=muse", []string{"Supermassive Black Hole", "Uprising"}},
+		{"group_eq=MUSE&song=rising", []string{"Uprising"}},
+		{"has_link=true", []string{"Supermassive Black Hole"}},
+		{"released_from=2000&released_to=2008", []string{"Supermassive Black Hole"}},
+		{"released_to=12.2009", []string{"Supermassive Black Hole", "Uprising", "Bohemian Rhapsody"}},
+		{"text=100%25", []string{"Bohemian Rhapsody"}},
+		{"text=%25%25", nil},
+	}
+
+	// Проверяем метод
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/songs?"+c.query, nil)
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, c.query)
+		var response []models.MusicInfo
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		var songs []string
+		for _, songInfo := range response {
+			songs = append(songs, songInfo.Song)
+		}
+		assert.Equal(t, c.songs, songs, c.query)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/songs?released_from=вчера&has_link=1x", nil)
+	router.ServeHTTP(rec, req)
+
+	var response ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, CodeValidation, response.Code)
+	assert.Contains(t, response.Error, "released_from")
+	assert.Contains(t, response.Error, "has_link")
+}
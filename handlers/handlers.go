@@ -2,56 +2,189 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 
 	"music-info/database"
+	"music-info/enrichment"
 	"music-info/models"
-
-	"gorm.io/gorm"
 )
 
-// sendError отправляет ошибку клиенту.
-func sendError(w http.ResponseWriter, statusCode int, errorMessage string) {
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(struct {
-		Error string `json:"error"`
-	}{Error: errorMessage})
+// Handler обработчики HTTP-запросов к API песен и исполнителей.
+type Handler struct {
+	// Songs хранилище песен.
+	Songs database.SongRepository
+	// Artists хранилище исполнителей.
+	Artists database.ArtistRepository
+	// Albums хранилище релизов.
+	Albums database.AlbumRepository
+	// Tags хранилище тегов песен.
+	Tags database.TagRepository
+	// Playlists хранилище плейлистов.
+	Playlists database.PlaylistRepository
+	// Lyrics хранилище синхронизированных текстов песен.
+	Lyrics database.LyricsRepository
+	// Revisions история правок песен.
+	Revisions database.RevisionRepository
+	// Trash корзина удаленных песен.
+	Trash database.TrashRepository
+	// Enricher дополняет новые песни данными внешнего сервиса. Если не задан, обогащение не выполняется.
+	Enricher enrichment.Enricher
+}
+
+// NewHandler создает обработчики, работающие с указанным хранилищем.
+func NewHandler(repo database.Repository, enricher enrichment.Enricher) *Handler {
+	return &Handler{Songs: repo, Artists: repo, Albums: repo, Tags: repo, Playlists: repo, Lyrics: repo, Revisions: repo, Trash: repo, Enricher: enricher}
+}
+
+// parsePagination возвращает номер страницы и количество записей на странице из параметров запроса.
+// Номер страницы ограничен так, чтобы смещение (page-1)*limit не переполнялось: хранилища получают
+// только корректное неотрицательное смещение. Слишком большой номер страницы — ошибка ErrValidation.
+func parsePagination(r *http.Request) (int, int, error) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	if page > math.MaxInt/limit {
+		return 0, 0, database.NewValidationError(fmt.Errorf("параметр 'page' не может превышать %d", math.MaxInt/limit))
+	}
+	return page, limit, nil
+}
+
+// writeSongText отправляет клиенту страницу куплетов песни согласно параметрам verse, page и limit.
+func writeSongText(w http.ResponseWriter, r *http.Request, songInfo *models.MusicInfo) {
+	verses := songInfo.Verses()
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var pageVerses []models.Verse
+	if v := r.URL.Query().Get("verse"); v != "" {
+		number, err := strconv.Atoi(v)
+		if err != nil || number < 1 {
+			sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный номер куплета")
+			return
+		}
+		if number > len(verses) {
+			sendError(w, http.StatusNotFound, CodeNotFound, "Куплет не найден")
+			return
+		}
+		page, limit = number, 1
+		pageVerses = verses[number-1 : number]
+	} else {
+		start := (page - 1) * limit
+		if start > len(verses) {
+			start = len(verses)
+		}
+		end := start + limit
+		if end > len(verses) {
+			end = len(verses)
+		}
+		pageVerses = verses[start:end]
+	}
+	if pageVerses == nil {
+		pageVerses = []models.Verse{}
+	}
+
+	log.Printf("Получено %d куплетов из %d: group=%s, song=%s\n", len(pageVerses), len(verses), songInfo.Group, songInfo.Song)
+	json.NewEncoder(w).Encode(models.SongText{
+		SongID: songInfo.ID,
+		Group:  songInfo.Group,
+		Song:   songInfo.Song,
+		Page:   page,
+		Limit:  limit,
+		Total:  len(verses),
+		Verses: pageVerses,
+	})
 }
 
 // SongCreateHandler создает новое сообщение.
 // @Summary Создать новое сообщение
-// @Description Добавляет новое сообщение в базу данных
+// @Description Добавляет новое сообщение в базу данных. Незаполненные поля releaseDate, text и link запрашиваются во внешнем сервисе.
+// @Description Если песня с такими группой и названием уже существует, возвращается 409 с её идентификатором, а в режиме upsert она обновляется
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body models.MusicInfo true "Данные сообщения"
+// @Param upsert query bool false "Обновить существующую песню вместо ошибки 409"
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} models.MusicInfo "Существующая песня обновлена в режиме upsert"
 // @Success 201 {object} models.MusicInfo
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Песня уже существует"
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 504 {object} ErrorResponse
 // @Router /messages [post]
-func SongCreateHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) SongCreateHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	upsert := false
+	if value := r.URL.Query().Get("upsert"); value != "" {
+		var err error
+		upsert, err = strconv.ParseBool(value)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверное значение параметра upsert")
+			return
+		}
+	}
+
 	var songInfo models.MusicInfo
 	err := json.NewDecoder(r.Body).Decode(&songInfo)
 	if err != nil {
 		log.Printf("Ошибка при декодировании JSON: %v", err)
-		sendError(w, http.StatusBadRequest, "Неверный формат JSON")
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+	songInfo.ChangedBy = changeAuthor(r)
+
+	if err := songInfo.ValidateKey(); err != nil {
+		log.Printf("Ошибка валидации: %v", err)
+		writeError(w, database.NewValidationError(err))
 		return
 	}
 
+	if h.Enricher != nil {
+		if err := h.Enricher.Enrich(r.Context(), &songInfo); err != nil {
+			log.Printf("Ошибка при получении данных из внешнего сервиса: %v", err)
+			writeError(w, err)
+			return
+		}
+	}
+
 	if err := songInfo.Validate(); err != nil {
 		log.Printf("Ошибка валидации: %v", err)
-		sendError(w, http.StatusBadRequest, err.Error())
+		writeError(w, database.NewValidationError(err))
 		return
 	}
 
-	err = database.DBSongCreate(&songInfo)
+	created := true
+	if upsert {
+		created, err = h.Songs.Upsert(&songInfo)
+	} else {
+		err = h.Songs.Create(&songInfo)
+	}
 	if err != nil {
 		log.Printf("Ошибка при вставке данных: %v", err)
-		sendError(w, http.StatusInternalServerError, "Ошибка при вставке данных")
+		writeError(w, err)
+		return
+	}
+
+	if !created {
+		log.Printf("Существующая песня обновлена: %+v\n", songInfo)
+		json.NewEncoder(w).Encode(songInfo)
 		return
 	}
 
@@ -68,25 +201,21 @@ func SongCreateHandler(w http.ResponseWriter, r *http.Request) {
 // @Param group query string true "Название группы"
 // @Param song query string true "Название песни"
 // @Success 200 {object} models.MusicInfo "Успешный ответ с информацией о песне"
-// @Failure 400 {object} map[string]string "Неверные параметры запроса"
-// @Failure 404 {object} map[string]string "Запись не найдена"
-// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Deprecated
 // @Router /songs/detail [get]
-func SongDetailHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) SongDetailHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	group := r.URL.Query().Get("group")
 	song := r.URL.Query().Get("song")
 
-	songInfo, err := database.DBSongDetail(group, song)
+	songInfo, err := h.Songs.Detail(group, song)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("Сообщение не найдено: group=%s, song=%s\n", group, song)
-			sendError(w, http.StatusNotFound, "Сообщение не найдено")
-		} else {
-			log.Printf("Ошибка при получении сообщения: %v\n", err)
-			sendError(w, http.StatusInternalServerError, "Ошибка при получении сообщения")
-		}
+		log.Printf("Ошибка при получении сообщения: %v\n", err)
+		writeError(w, err)
 		return
 	}
 
@@ -94,21 +223,58 @@ func SongDetailHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(songInfo)
 }
 
+// SongTextHandler возвращает текст песни, разбитый на куплеты.
+// @Summary Получить куплеты песни
+// @Description Возвращает страницу куплетов песни и общее количество куплетов. Параметр verse позволяет получить один куплет по номеру
+// @Tags songs
+// @Produce json
+// @Param group query string true "Название группы"
+// @Param song query string true "Название песни"
+// @Param verse query int false "Номер куплета, начиная с 1"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество куплетов на странице" default(10)
+// @Success 200 {object} models.SongText "Успешный ответ с куплетами песни"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Запись или куплет не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Deprecated
+// @Router /songs/info/text [get]
+func (h *Handler) SongTextHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+
+	songInfo, err := h.Songs.Detail(group, song)
+	if err != nil {
+		log.Printf("Ошибка при получении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня найдена: group=%s, song=%s\n", group, song)
+	writeSongText(w, r, songInfo)
+}
+
 // SongUpdateHandler обновляет информацию о песне.
 // @Summary Обновить информацию о песне
-// @Description Обновляет информацию о песне по указанным группе и названию песни
+// @Description Обновляет заполненные поля песни по указанным группе и названию песни. Пустые поля не изменяются.
+// @Description Поля group и song в теле запроса переименовывают песню. Для очистки полей используйте PATCH /songs/{id}
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param group query string true "Название группы"
 // @Param song query string true "Название песни"
 // @Param updateInfo body models.MusicInfo true "Данные для обновления"
+// @Param X-User header string false "Автор изменения для истории правок"
 // @Success 200 {object} models.MusicInfo "Успешный ответ с обновлённой информацией о песне"
-// @Failure 400 {object} map[string]string "Неверный формат JSON или параметры запроса"
-// @Failure 404 {object} map[string]string "Запись не найдена"
-// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или параметры запроса"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 409 {object} ErrorResponse "Песня с новыми группой и названием уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Deprecated
 // @Router /songs/update [put]
-func SongUpdateHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) SongUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	group := r.URL.Query().Get("group")
@@ -118,80 +284,105 @@ func SongUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&updateInfo)
 	if err != nil {
 		log.Printf("Ошибка при декодировании JSON: %v\n", err)
-		sendError(w, http.StatusBadRequest, "Неверный формат JSON")
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
 		return
 	}
+	updateInfo.ChangedBy = changeAuthor(r)
 
-	err = database.DBSongUpdate(group, song, &updateInfo)
+	err = h.Songs.Update(group, song, &updateInfo)
 	if err != nil {
 		log.Printf("Ошибка при обновлении сообщения: %v\n", err)
-		sendError(w, http.StatusInternalServerError, "Ошибка при обновлении сообщения")
+		writeError(w, err)
+		return
+	}
+
+	// Песня могла быть переименована полями group и song из тела запроса
+	if updateInfo.Group != "" {
+		group = updateInfo.Group
+	}
+	if updateInfo.Song != "" {
+		song = updateInfo.Song
+	}
+
+	songInfo, err := h.Songs.Detail(group, song)
+	if err != nil {
+		log.Printf("Ошибка при получении сообщения: %v\n", err)
+		writeError(w, err)
 		return
 	}
 
 	log.Printf("Сообщение обновлено: group=%s, song=%s\n", group, song)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updateInfo)
+	json.NewEncoder(w).Encode(songInfo)
 }
 
 // GetSongsHandler возвращает список песен.
 // @Summary Получить список песен
-// @Description Возвращает список песен с возможностью фильтрации по группе и пагинацией
+// @Description Возвращает список песен с фильтрацией и пагинацией. Подстроки сравниваются без учета регистра.
+// @Description Даты выпуска принимаются в любом из форматов даты выпуска, время создания и изменения — в формате RFC3339 или YYYY-MM-DD. Границы диапазонов включаются
 // @Tags songs
 // @Produce json
-// @Param group query string false "Фильтр по группе"
-// @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Количество записей на странице" default(10)
+// @Param group query string false "Подстрока названия группы"
+// @Param group_eq query string false "Точное название группы"
+// @Param song query string false "Подстрока названия песни"
+// @Param song_eq query string false "Точное название песни"
+// @Param text query string false "Подстрока текста песни"
+// @Param has_link query bool false "Наличие ссылки"
+// @Param artist_id query int false "Идентификатор исполнителя"
+// @Param tag query []string false "Тег в виде вид:имя или имя любого вида, например genre:Rock. При повторении песня должна иметь все теги" collectionFormat(multi)
+// @Param released_from query string false "Дата выпуска не раньше"
+// @Param released_to query string false "Дата выпуска не позже"
+// @Param created_from query string false "Время создания не раньше"
+// @Param created_to query string false "Время создания не позже"
+// @Param updated_from query string false "Время изменения не раньше"
+// @Param updated_to query string false "Время изменения не позже"
+// @Param sort query string false "Порядок: поля group, song, release_date, created_at, updated_at, id через запятую, минус означает обратный порядок" default(group,song)
+// @Param cursor query string false "Курсор страницы из заголовка Link. Без курсора и номера страницы возвращается первая страница"
+// @Param page query int false "Номер страницы. Устаревший постраничный вывод, несовместим с cursor"
+// @Param limit query int false "Количество записей на странице, не более 100" default(10)
+// @Param total query bool false "Вернуть общее количество записей в заголовке X-Total-Count"
 // @Success 200 {array} models.MusicInfo "Успешный ответ со списком песен"
-// @Failure 400 {object} map[string]string "Неверные параметры запроса"
-// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Header 200 {string} Link "Ссылки на следующую (rel=next) и предыдущую (rel=prev) страницы"
+// @Header 200 {integer} X-Total-Count "Общее количество записей, если указан total=true"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs [get]
-func GetSongsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetSongsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	group := r.URL.Query().Get("group")
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 {
-		limit = 10
-	}
-
-	messages, err := database.DBGetSongs(group, page, limit)
+	filter, err := parseSongFilter(r.URL.Query())
 	if err != nil {
-		log.Printf("Ошибка при получении данных: %v", err)
-		sendError(w, http.StatusInternalServerError, "Ошибка при получении данных")
+		log.Printf("Ошибка в параметрах фильтра: %v\n", err)
+		writeError(w, err)
 		return
 	}
 
-	log.Printf("Получено %d сообщений\n", len(messages))
-	json.NewEncoder(w).Encode(messages)
+	h.writeSongList(w, r, filter)
 }
 
 // SongDeleteHandler удаляет запись о песне.
 // @Summary Удалить запись о песне
-// @Description Удаляет запись о песне по указанным группе и названию песни
+// @Description Переносит запись о песне с указанными группой и названием в корзину
 // @Tags songs
 // @Produce json
 // @Param group query string true "Название группы"
 // @Param song query string true "Название песни"
 // @Success 204 "Запись успешно удалена"
-// @Failure 400 {object} map[string]string "Неверные параметры запроса"
-// @Failure 404 {object} map[string]string "Запись не найдена"
-// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Deprecated
 // @Router /songs [delete]
-func SongDeleteHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) SongDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	group := r.URL.Query().Get("group")
 	song := r.URL.Query().Get("song")
 
-	err := database.DBSongDelete(group, song)
+	err := h.Songs.Delete(group, song)
 	if err != nil {
 		log.Printf("Ошибка при удалении записи: %v\n", err)
-		sendError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, err)
 		return
 	}
 
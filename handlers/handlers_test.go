@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"music-info/database"
+	"music-info/enrichment"
 	"music-info/models"
 
 	"github.com/gorilla/mux"
@@ -17,13 +20,12 @@ import (
 func TestSongCreateHandler(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -36,8 +38,7 @@ func TestSongCreateHandler(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/add", SongCreateHandler).Methods("POST")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response models.MusicInfo
@@ -46,7 +47,7 @@ func TestSongCreateHandler(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, songInfo.Group, response.Group)
 	assert.Equal(t, songInfo.Song, response.Song)
-	assert.Equal(t, songInfo.ReleaseDate, response.ReleaseDate)
+	assert.Equal(t, songInfo.ReleaseDate.String(), response.ReleaseDate.String())
 	assert.Equal(t, songInfo.Text, response.Text)
 	assert.Equal(t, songInfo.Link, response.Link)
 	assert.NotEqual(t, 0, response.ID)
@@ -55,8 +56,7 @@ func TestSongCreateHandler(t *testing.T) {
 func TestSongCreateHandlerBaseParams(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songInfo := models.MusicInfo{
 		Group: "Muse",
@@ -72,8 +72,7 @@ func TestSongCreateHandlerBaseParams(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/add", SongCreateHandler).Methods("POST")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response models.MusicInfo
@@ -82,26 +81,181 @@ func TestSongCreateHandlerBaseParams(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, songInfo.Group, response.Group)
 	assert.Equal(t, songInfo.Song, response.Song)
-	assert.Equal(t, songInfo.ReleaseDate, response.ReleaseDate)
+	assert.Equal(t, songInfo.ReleaseDate.String(), response.ReleaseDate.String())
 	assert.Equal(t, songInfo.Text, response.Text)
 	assert.Equal(t, songInfo.Link, response.Link)
 	assert.NotEqual(t, 0, response.ID)
 }
 
+func TestSongCreateHandlerConflict(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	songInfo := models.MusicInfo{
+		Group: "Muse",
+		Song:  "Supermassive Black Hole",
+		Text:  "Ooh baby, don't you know I suffer?",
+	}
+	err := h.Songs.Create(&songInfo)
+	assert.NoError(t, err)
+
+	body, _ := json.Marshal(models.MusicInfo{Group: " muse ", Song: "SUPERMASSIVE BLACK HOLE", Text: "Ooh"})
+
+	// Проверяем метод
+	req, err := http.NewRequest("POST", "/songs/add", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+
+	router := NewRouter(h)
+	router.ServeHTTP(rec, req)
+
+	var response struct {
+		Error string `json:"error"`
+		ID    uint   `json:"id"`
+	}
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.Equal(t, fmt.Sprintf("/songs/%d", songInfo.ID), rec.Header().Get("Location"))
+}
+
+func TestSongCreateHandlerUpsert(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	songInfo := models.MusicInfo{
+		Group: "Muse",
+		Song:  "Supermassive Black Hole",
+		Text:  "Ooh baby, don't you know I suffer?",
+	}
+	err := h.Songs.Create(&songInfo)
+	assert.NoError(t, err)
+
+	router := NewRouter(h)
+
+	// Проверяем обновление существующей песни
+	body, _ := json.Marshal(models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh", Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw"})
+	req, err := http.NewRequest("POST", "/songs/add?upsert=true", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.Equal(t, "Ooh", response.Text)
+	assert.Equal(t, "https://www.youtube.com/watch?v=Xsp3_a-PMTw", response.Link)
+
+	// Проверяем создание новой песни
+	body, _ = json.Marshal(models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"})
+	req, err = http.NewRequest("POST", "/songs/add?upsert=true", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// Проверяем неверное значение параметра
+	req, err = http.NewRequest("POST", "/songs/add?upsert=maybe", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// enricherFunc позволяет использовать функцию в качестве enrichment.Enricher.
+type enricherFunc func(ctx context.Context, songInfo *models.MusicInfo) error
+
+func (f enricherFunc) Enrich(ctx context.Context, songInfo *models.MusicInfo) error {
+	return f(ctx, songInfo)
+}
+
+func TestSongCreateHandlerEnrichment(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	h.Enricher = enricherFunc(func(ctx context.Context, songInfo *models.MusicInfo) error {
+		songInfo.ReleaseDate = models.MustParseReleaseDate("16.07.2006")
+		songInfo.Text = "Ooh baby, don't you know I suffer?"
+		songInfo.Link = "https://www.youtube.com/watch?v=Xsp3_a-PMTw"
+		return nil
+	})
+
+	body, _ := json.Marshal(models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole"})
+
+	// Проверяем метод
+	req, err := http.NewRequest("POST", "/songs/add", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+
+	router := NewRouter(h)
+	router.ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "16.07.2006", response.ReleaseDate.String())
+	assert.Equal(t, "Ooh baby, don't you know I suffer?", response.Text)
+	assert.Equal(t, "https://www.youtube.com/watch?v=Xsp3_a-PMTw", response.Link)
+}
+
+func TestSongCreateHandlerEnrichmentError(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	errs := map[error]int{
+		enrichment.ErrBadRequest:  http.StatusBadRequest,
+		enrichment.ErrUnavailable: http.StatusBadGateway,
+		enrichment.ErrTimeout:     http.StatusGatewayTimeout,
+	}
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	for enrichErr, code := range errs {
+		h.Enricher = enricherFunc(func(ctx context.Context, songInfo *models.MusicInfo) error {
+			return enrichErr
+		})
+
+		body, _ := json.Marshal(models.MusicInfo{Group: "Muse", Song: "Supermassive Black Hole"})
+		req, err := http.NewRequest("POST", "/songs/add", bytes.NewBuffer(body))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, code, rec.Code)
+	}
+}
+
 func TestSongDetailHandler(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
-	err := database.DBSongCreate(&songInfo)
+	err := h.Songs.Create(&songInfo)
 	assert.NoError(t, err)
 
 	// Проверяем метод
@@ -110,15 +264,14 @@ func TestSongDetailHandler(t *testing.T) {
 
 	record := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/info", SongDetailHandler).Methods("GET")
+	router := NewRouter(h)
 	router.ServeHTTP(record, req)
 
 	var response models.MusicInfo
 	assert.Equal(t, http.StatusOK, record.Code)
 	err = json.Unmarshal(record.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, songInfo.ReleaseDate, response.ReleaseDate)
+	assert.Equal(t, songInfo.ReleaseDate.String(), response.ReleaseDate.String())
 	assert.Equal(t, songInfo.Text, response.Text)
 	assert.Equal(t, songInfo.Link, response.Link)
 }
@@ -126,17 +279,16 @@ func TestSongDetailHandler(t *testing.T) {
 func TestSongDetailHandlerNotFound(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
-	err := database.DBSongCreate(&songInfo)
+	err := h.Songs.Create(&songInfo)
 	assert.NoError(t, err)
 
 	// Проверяем метод
@@ -145,8 +297,7 @@ func TestSongDetailHandlerNotFound(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/info", SongDetailHandler).Methods("GET")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response map[string]string
@@ -156,20 +307,94 @@ func TestSongDetailHandlerNotFound(t *testing.T) {
 	assert.Equal(t, "", response["error"])
 }
 
+func TestSongTextHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := h.Songs.Create(&songInfo)
+	assert.NoError(t, err)
+
+	router := NewRouter(h)
+
+	// Проверяем постраничный вывод
+	req, err := http.NewRequest("GET", "/songs/info/text?group=Muse&song=Supermassive%20Black%20Hole&page=2&limit=1", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response models.SongText
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, []models.Verse{{Number: 2, Text: "Ooh\nYou set my soul alight\nOoh\nYou set my soul alight"}}, response.Verses)
+
+	// Проверяем получение куплета по номеру
+	req, err = http.NewRequest("GET", "/songs/info/text?group=Muse&song=Supermassive%20Black%20Hole&verse=3", nil)
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Проверяем номер страницы, при котором смещение переполняется
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/info/text?group=Muse&song=Supermassive%20Black%20Hole&page=922337203685477581&limit=100", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), CodeValidation)
+
+	// Проверяем, что песня без текста возвращает пустой список куплетов
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Muse", Song: "Untitled"}))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/info/text?group=Muse&song=Untitled", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"verses":[]`)
+}
+
+func TestSongDetailHandlerMissing(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	// Проверяем метод
+	req, err := http.NewRequest("GET", "/songs/info?group=Muse&song=Supermassive%20Black%20Hole", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+
+	router := NewRouter(h)
+	router.ServeHTTP(rec, req)
+
+	var response ErrorResponse
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeNotFound, response.Code)
+}
+
 func TestSongUpdateHandler(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
-	err := database.DBSongCreate(&songInfo)
+	err := h.Songs.Create(&songInfo)
 	assert.NoError(t, err)
 
 	// Проверяем метод
@@ -185,8 +410,7 @@ func TestSongUpdateHandler(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/info/update", SongUpdateHandler).Methods("PUT")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response models.MusicInfo
@@ -194,8 +418,10 @@ func TestSongUpdateHandler(t *testing.T) {
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, updateSong.Text, response.Text)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.Equal(t, songInfo.Link, response.Link)
 
-	result, err := database.DBSongDetail("Muse", "Supermassive Black Hole")
+	result, err := h.Songs.Detail("Muse", "Supermassive Black Hole")
 	assert.NoError(t, err)
 	assert.Equal(t, updateSong.Text, result.Text)
 }
@@ -203,8 +429,7 @@ func TestSongUpdateHandler(t *testing.T) {
 func TestSongUpdateHandlerNotFound(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	// Проверяем метод
 	newText := "I thought I was a fool for no-one\nOh baby I'm a fool for you\nYou're the queen of the superficial\nAnd how long before you tell the truth\n\nOooh...You set my soul alight\nOooh...You set my soul alight"
@@ -219,40 +444,39 @@ func TestSongUpdateHandlerNotFound(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/info/update", SongUpdateHandler).Methods("PUT")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response map[string]string
-	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
-	assert.Error(t, err)
-	assert.Equal(t, "", response["error"])
+	assert.NoError(t, err)
+	assert.Contains(t, response["error"], "запись не найдена")
+	assert.Equal(t, CodeNotFound, response["code"])
 }
 
 func TestGetSongsHandler(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	songsInfo := []models.MusicInfo{
 		{
 			Group:       "Muse",
 			Song:        "Supermassive Black Hole",
-			ReleaseDate: "16.07.2006",
+			ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 			Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 			Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 		}, {
 			Group:       "Queen",
 			Song:        "Bohemian Rhapsody",
-			ReleaseDate: "31.10.1975",
+			ReleaseDate: models.MustParseReleaseDate("31.10.1975"),
 			Text:        "Is this the real life? Is this just fantasy?\nCaught in a landslide, no escape from reality\nOpen your eyes, look up to the skies and see\nI'm just a poor boy, I need no sympathy\nBecause I'm easy come, easy go\nLittle high, little low\nAny way the wind blows doesn't really matter to me, to me\n\nMama, just killed a man\nPut a gun against his head, pulled my trigger, now he's dead\nMama, life had just begun\nBut now I've gone and thrown it all away\nMama, ooh, didn't mean to make you cry\nIf I'm not back again this time tomorrow\nCarry on, carry on as if nothing really matters",
 			Link:        "https://www.youtube.com/watch?v=vbvyNnw8Qjg",
 		},
 	}
 	for _, msg := range songsInfo {
-		err := database.DBSongCreate(&msg)
+		err := h.Songs.Create(&msg)
 		assert.NoError(t, err)
 	}
 
@@ -262,8 +486,7 @@ func TestGetSongsHandler(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs", GetSongsHandler).Methods("GET")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
 	var response []models.MusicInfo
@@ -277,18 +500,17 @@ func TestGetSongsHandler(t *testing.T) {
 func TestDeleteSongHandler(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	testData := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
-	result := database.DB.Create(&testData)
-	assert.NoError(t, result.Error)
+	err := h.Songs.Create(&testData)
+	assert.NoError(t, err)
 
 	// Проверяем метод
 	req, err := http.NewRequest("DELETE", "/songs/info/delete?group=Muse&song=Supermassive%20Black%20Hole", nil)
@@ -297,21 +519,19 @@ func TestDeleteSongHandler(t *testing.T) {
 	rec := httptest.NewRecorder()
 
 	router := mux.NewRouter()
-	router.HandleFunc("/s", SongDeleteHandler).Methods("DELETE")
+	router.HandleFunc("/s", h.SongDeleteHandler).Methods("DELETE")
 	router.ServeHTTP(rec, req)
 
 	assert.Equal(t, http.StatusNotFound, rec.Code)
-	var songDetail models.MusicInfo
-	result = database.DB.Where("\"group\" = ? AND \"song\" = ?", "Muse", "Supermassive Black Hole").First(&songDetail)
-	assert.Nil(t, result.Error)
-	assert.NoError(t, result.Error)
+	_, err = h.Songs.Detail("Muse", "Supermassive Black Hole")
+	assert.Nil(t, err)
+	assert.NoError(t, err)
 }
 
 func TestDeleteSongHandlerNotFound(t *testing.T) {
 
 	// Создаем тестовые данные
-	tx := database.SetupTestDB(t)
-	defer database.DropTableDB(t, tx, "music_infos")
+	h := NewHandler(database.NewMemoryRepository(), nil)
 
 	// Проверяем метод
 	req, err := http.NewRequest("DELETE", "/songs/info/delete?group=Muse&song=Supermassive Black Hole", nil)
@@ -319,14 +539,14 @@ func TestDeleteSongHandlerNotFound(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 
-	router := mux.NewRouter()
-	router.HandleFunc("/songs/info/delete", SongDeleteHandler).Methods("DELETE")
+	router := NewRouter(h)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 
 	var response map[string]string
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Contains(t, response["error"], "запись не найдена")
+	assert.Equal(t, CodeNotFound, response["code"])
 }
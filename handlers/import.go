@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"music-info/database"
+	"music-info/songfile"
+)
+
+// maxImportFileSize максимальный размер файла импорта песен.
+const maxImportFileSize = 100 << 20
+
+// importTimeout срок чтения файла импорта и отправки отчета. Таймауты сервера рассчитаны на обычные запросы
+// и прервали бы загрузку большого файла, поэтому для импорта сроки продлеваются.
+const importTimeout = 10 * time.Minute
+
+// SongImportHandler импортирует песни из файла CSV или JSON Lines.
+// @Summary Импортировать песни из файла
+// @Description Читает песни из CSV с заголовком group,song,releaseDate,text,link или из JSON Lines с объектом песни на каждой строке.
+// @Description Каждая запись проверяется так же, как при создании песни, но без обращения к внешнему сервису.
+// @Description Песни сохраняются пакетами по batch_size в транзакции; ошибка записи не отменяет остальные.
+// @Description Существующие песни с той же группой и названием пропускаются. В ответе — результат каждой записи с номером строки.
+// @Description На чтение файла и импорт отводится 10 минут независимо от таймаутов сервера
+// @Tags songs
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file body string true "Содержимое файла"
+// @Param format query string false "Формат файла. По умолчанию определяется по типу содержимого" Enums(csv, jsonl)
+// @Param dry_run query bool false "Только проверить записи, не сохраняя песни"
+// @Param batch_size query int false "Количество песен в одной транзакции, не более 5000" default(500)
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} songfile.Report "Результат импорта"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса или заголовок файла"
+// @Failure 413 {object} ErrorResponse "Файл слишком большой"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/import [post]
+func (h *Handler) SongImportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	opts, format, err := parseImportOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	if err := controller.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Ошибка при продлении срока чтения файла импорта: %v\n", err)
+	}
+	if err := controller.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Ошибка при продлении срока отправки отчета импорта: %v\n", err)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	reader, err := songfile.NewReader(body, format)
+	if tooLarge(body) {
+		sendError(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "Файл импорта слишком большой")
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка при чтении файла импорта: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	report, err := songfile.Import(h.Songs, reader, opts)
+	if err != nil {
+		log.Printf("Ошибка при импорте песен: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	// Превышение размера прерывает чтение файла, песни прочитанной части уже сохранены.
+	if tooLarge(body) {
+		sendError(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("Файл импорта слишком большой, импортировано песен: %d", report.Created))
+		return
+	}
+
+	log.Printf("Импорт песен %s: записей %d, создано %d, пропущено %d, с ошибкой %d\n",
+		format, report.Total, report.Created, report.Skipped, report.Failed)
+	json.NewEncoder(w).Encode(report)
+}
+
+// tooLarge проверяет, что чтение тела запроса было прервано из-за превышения размера.
+// Ограничитель http.MaxBytesReader возвращает ту же ошибку при каждом следующем чтении.
+func tooLarge(body io.Reader) bool {
+	var maxBytesErr *http.MaxBytesError
+	_, err := body.Read(nil)
+	return errors.As(err, &maxBytesErr)
+}
+
+// parseImportOptions возвращает параметры импорта и формат файла из параметров запроса и типа содержимого.
+func parseImportOptions(r *http.Request) (songfile.Options, songfile.Format, error) {
+	query := r.URL.Query()
+	opts := songfile.Options{Author: changeAuthor(r)}
+
+	var format songfile.Format
+	if value := query.Get("format"); value != "" {
+		var err error
+		if format, err = songfile.ParseFormat(value); err != nil {
+			return opts, "", database.NewValidationError(err)
+		}
+	} else if detected, ok := songfile.FormatByContentType(r.Header.Get("Content-Type")); ok {
+		format = detected
+	} else {
+		return opts, "", database.NewValidationError(errors.New("укажите формат файла в параметре 'format' или типе содержимого"))
+	}
+
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+			return opts, "", database.NewValidationError(errors.New("параметр 'dry_run' должен быть true или false"))
+		}
+	}
+
+	if value := query.Get("batch_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > songfile.MaxBatchSize {
+			return opts, "", database.NewValidationError(fmt.Errorf("параметр 'batch_size' должен быть от 1 до %d", songfile.MaxBatchSize))
+		}
+		opts.BatchSize = size
+	}
+
+	return opts, format, nil
+}
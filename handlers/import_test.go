@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"music-info/database"
+	"music-info/songfile"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongImportHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+	content := `{"group":"Muse","song":"Starlight","text":"Far away"}
+{"group":"Muse","song":"Supermassive Black Hole","text":"Ooh baby"}
+{"group":"Muse","song":"Hysteria"}
+`
+	serve := func(target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		h.SongImportHandler(rec, req)
+		return rec
+	}
+
+	// Проверяем метод
+	rec := serve("/songs/import?dry_run=true", "application/x-ndjson", content)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report songfile.Report
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, songfile.RowSkipped, report.Rows[1].Status)
+	assert.Equal(t, songInfo.ID, report.Rows[1].ID)
+	assert.Equal(t, songfile.RowResult{Line: 3, Status: songfile.RowFailed, Group: "Muse", Song: "Hysteria",
+		Error: "поле 'Text' обязательно для заполнения"}, report.Rows[2])
+	_, err := h.Songs.Detail("Muse", "Starlight")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	rec = serve("/songs/import?format=csv&batch_size=1", "text/plain", "group,song,text\nMuse,Uprising,Paranoia\n")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	report = songfile.Report{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Created)
+	created, err := h.Songs.Detail("Muse", "Uprising")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, report.Rows[0].ID)
+
+	rec = serve("/songs/import", "text/plain", content)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve("/songs/import?batch_size=0", "text/csv", "group,song\n")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve("/songs/import", "text/csv", "group,album\n")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), CodeValidation)
+}
+
+func TestSongImportHandlerReadTimeout(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	server := httptest.NewUnstartedServer(NewRouter(h))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// Файл передается дольше ReadTimeout сервера
+	reader, writer := io.Pipe()
+	go func() {
+		writer.Write([]byte("group,song,text\nMuse,Uprising,Paranoia\n"))
+		time.Sleep(300 * time.Millisecond)
+		writer.Write([]byte("Muse,Starlight,Far away\n"))
+		writer.Close()
+	}()
+
+	// Проверяем метод
+	resp, err := http.Post(server.URL+"/songs/import", "text/csv", reader)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var report songfile.Report
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, 2, report.Created)
+}
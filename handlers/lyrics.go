@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-info/database"
+	"music-info/lrc"
+	"music-info/models"
+)
+
+// maxLyricsSize максимальный размер файла LRC.
+const maxLyricsSize = 1 << 20
+
+// maxPlaybackTime максимальный момент воспроизведения в параметре at.
+const maxPlaybackTime = 24 * time.Hour
+
+// lrcContentType тип содержимого ответа в формате LRC.
+const lrcContentType = "text/plain; charset=utf-8"
+
+// SongLyricsHandler возвращает синхронизированный текст песни в формате LRC.
+// @Summary Получить синхронизированный текст песни
+// @Description Возвращает текст с отметками времени в формате LRC, по одной отметке на строку.
+// @Description Если в тексте нет тегов [ar:] и [ti:], они заполняются группой и названием песни
+// @Tags lyrics
+// @Produce plain
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {string} string "Текст в формате LRC"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Песня не найдена или у нее нет синхронизированного текста"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics.lrc [get]
+func (h *Handler) SongLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := songID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	songInfo, lyrics, err := h.songLyrics(id)
+	if err != nil {
+		log.Printf("Ошибка при получении синхронизированного текста: %v\n", err)
+		writeJSONError(w, err)
+		return
+	}
+
+	if lyrics.Artist == "" {
+		lyrics.Artist = songInfo.Group
+	}
+	if lyrics.Title == "" {
+		lyrics.Title = songInfo.Song
+	}
+
+	w.Header().Set("Content-Type", lrcContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"song-%d.lrc\"", id))
+	if err := lrc.Write(w, lyrics); err != nil {
+		log.Printf("Ошибка при записи LRC: %v\n", err)
+	}
+}
+
+// SongSaveLyricsHandler сохраняет синхронизированный текст песни.
+// @Summary Сохранить синхронизированный текст песни
+// @Description Принимает текст в формате LRC: отметки времени [мм:сс.xx], в том числе несколько на строке,
+// @Description и теги [ar:], [ti:], [al:], [au:], [by:], [offset:]. Отметки времени должны идти по возрастанию:
+// @Description первая отметка строки не раньше первой отметки предыдущей строки. Текст сохраняется по одной отметке на строку и заменяет прежний
+// @Tags lyrics
+// @Accept plain
+// @Produce plain
+// @Param id path int true "Идентификатор песни"
+// @Param lyrics body string true "Текст в формате LRC"
+// @Success 200 {string} string "Сохраненный текст в формате LRC"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или текст не прошел проверку"
+// @Failure 404 {object} ErrorResponse "Песня не найдена"
+// @Failure 413 {object} ErrorResponse "Текст слишком большой"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics.lrc [put]
+func (h *Handler) SongSaveLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxLyricsSize)
+	lyrics, err := lrc.Parse(body)
+	if tooLarge(body) {
+		sendError(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "Синхронизированный текст слишком большой")
+		return
+	}
+	if err == nil && len(lyrics.Lines) == 0 {
+		err = errors.New("в тексте нет строк с отметками времени")
+	}
+	if err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	stored := models.SyncedLyrics{SongID: id, LRC: lyrics.String()}
+	if err := h.Lyrics.SaveSongLyrics(&stored); err != nil {
+		log.Printf("Ошибка при сохранении синхронизированного текста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Синхронизированный текст песни id=%d сохранен, строк: %d\n", id, len(lyrics.Lines))
+	w.Header().Set("Content-Type", lrcContentType)
+	w.Write([]byte(stored.LRC))
+}
+
+// SongDeleteLyricsHandler удаляет синхронизированный текст песни.
+// @Summary Удалить синхронизированный текст песни
+// @Tags lyrics
+// @Param id path int true "Идентификатор песни"
+// @Success 204 "Текст удален"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "У песни нет синхронизированного текста"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics.lrc [delete]
+func (h *Handler) SongDeleteLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Lyrics.DeleteSongLyrics(id); err != nil {
+		log.Printf("Ошибка при удалении синхронизированного текста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Синхронизированный текст песни id=%d удален\n", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SongLyricsLineHandler возвращает строку синхронизированного текста, звучащую в момент воспроизведения.
+// @Summary Получить текущую строку текста
+// @Description Возвращает строку, звучащую в момент at с учетом смещения [offset:], и следующую строку.
+// @Description До начала первой строки line пуст, после начала последней пуст next
+// @Tags lyrics
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param at query string true "Момент воспроизведения: секунды (83.5), мм:сс.xx (01:23.50) или длительность (1m23.5s)"
+// @Success 200 {object} models.ActiveLyricsLine "Текущая и следующая строки"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или момент воспроизведения"
+// @Failure 404 {object} ErrorResponse "Песня не найдена или у нее нет синхронизированного текста"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics/line [get]
+func (h *Handler) SongLyricsLineHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	at, err := parsePlaybackTime(r.URL.Query().Get("at"))
+	if err != nil {
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	_, lyrics, err := h.songLyrics(id)
+	if err != nil {
+		log.Printf("Ошибка при получении синхронизированного текста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	active := models.ActiveLyricsLine{SongID: id, AtMs: at.Milliseconds()}
+	i, ok := lyrics.Active(at)
+	if ok {
+		active.Line = lyricsLine(lyrics, i)
+	}
+	if i+1 < len(lyrics.Lines) {
+		active.Next = lyricsLine(lyrics, i+1)
+	}
+
+	json.NewEncoder(w).Encode(active)
+}
+
+// songLyrics возвращает песню и ее разобранный синхронизированный текст.
+func (h *Handler) songLyrics(id uint) (*models.MusicInfo, lrc.Lyrics, error) {
+	songInfo, err := h.Songs.DetailByID(id)
+	if err != nil {
+		return nil, lrc.Lyrics{}, err
+	}
+	stored, err := h.Lyrics.SongLyrics(id)
+	if err != nil {
+		return nil, lrc.Lyrics{}, err
+	}
+
+	// Сохраняется только текст, прошедший проверку, поэтому ошибка означает повреждение данных
+	lyrics, err := lrc.Parse(strings.NewReader(stored.LRC))
+	if err != nil {
+		return nil, lrc.Lyrics{}, fmt.Errorf("сохраненный синхронизированный текст песни id=%d не разбирается: %v", id, err)
+	}
+
+	return songInfo, lyrics, nil
+}
+
+// lyricsLine возвращает строку текста с номером и временем начала в воспроизведении.
+func lyricsLine(lyrics lrc.Lyrics, i int) *models.LyricsLine {
+	start := lyrics.Start(i)
+	return &models.LyricsLine{
+		Number: i + 1,
+		Time:   lrc.FormatTimestamp(start),
+		TimeMs: start.Milliseconds(),
+		Text:   lyrics.Lines[i].Text,
+	}
+}
+
+// parsePlaybackTime разбирает момент воспроизведения: секунды, отметку времени мм:сс.xx или длительность Go.
+func parsePlaybackTime(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("параметр 'at' обязателен")
+	}
+
+	var at time.Duration
+	var err error
+	if strings.Contains(value, ":") {
+		at, err = lrc.ParseTimestamp(value)
+	} else if seconds, parseErr := strconv.ParseFloat(value, 64); parseErr == nil {
+		if math.IsNaN(seconds) || seconds > maxPlaybackTime.Seconds() {
+			return 0, fmt.Errorf("неверное значение параметра 'at' '%s': ожидаются секунды, мм:сс.xx или длительность", value)
+		}
+		at = time.Duration(seconds * float64(time.Second))
+	} else {
+		at, err = time.ParseDuration(value)
+	}
+	if err != nil || at < 0 {
+		return 0, fmt.Errorf("неверное значение параметра 'at' '%s': ожидаются секунды, мм:сс.xx или длительность", value)
+	}
+
+	return at, nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongLyricsHandlers(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	path := fmt.Sprintf("/songs/%d", songInfo.ID)
+	content := "[offset:+500]\n[00:10.50][01:10.00]Ooh baby, don't you know I suffer?\n[00:20.00]Ooh baby, can you hear me moan?\n"
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", path+"/lyrics.lrc", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", path+"/lyrics.lrc", strings.NewReader(content)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "[offset:+500]\n[00:10.50]Ooh baby, don't you know I suffer?\n[00:20.00]Ooh baby, can you hear me moan?\n[01:10.00]Ooh baby, don't you know I suffer?\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", path+"/lyrics.lrc", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "[ar:Muse]\n[ti:Supermassive Black Hole]\n[offset:+500]\n"))
+
+	line := func(at string) models.ActiveLyricsLine {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path+"/lyrics/line?at="+at, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var active models.ActiveLyricsLine
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&active))
+		return active
+	}
+
+	active := line("5")
+	assert.Nil(t, active.Line)
+	assert.Equal(t, &models.LyricsLine{Number: 1, Time: "00:10.00", TimeMs: 10000, Text: "Ooh baby, don't you know I suffer?"}, active.Next)
+
+	active = line("00:19.60")
+	assert.Equal(t, int64(19600), active.AtMs)
+	assert.Equal(t, 2, active.Line.Number)
+	assert.Equal(t, 3, active.Next.Number)
+
+	active = line("2m")
+	assert.Equal(t, 3, active.Line.Number)
+	assert.Nil(t, active.Next)
+
+	for _, at := range []string{"", "soon", "-1", "NaN", "00:75"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path+"/lyrics/line?at="+at, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, at)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", path+"/lyrics.lrc", strings.NewReader("[00:20.00]b\n[00:10.00]a\n")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "строка 2")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", path+"/lyrics.lrc", strings.NewReader("[ar:Muse]\n")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/songs/999/lyrics.lrc", strings.NewReader(content)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", path+"/lyrics.lrc", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", path+"/lyrics.lrc", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"music-info/database"
+	"music-info/models"
+)
+
+// maxLimit максимальное количество записей на странице.
+const maxLimit = 100
+
+// songListPage страница списка песен со ссылками на соседние страницы.
+type songListPage struct {
+	songs []models.MusicInfo
+	// next и prev параметры запроса соседних страниц, nil если страницы нет.
+	next url.Values
+	prev url.Values
+}
+
+// listSongs возвращает страницу списка песен. Если в запросе указан параметр page, используется
+// постраничный вывод по номеру страницы, иначе — по курсору из параметра cursor.
+func (h *Handler) listSongs(r *http.Request, filter database.SongFilter, order database.SongSort) (songListPage, error) {
+	query := r.URL.Query()
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		return songListPage{}, err
+	}
+
+	if query.Has("page") {
+		if query.Has("cursor") {
+			return songListPage{}, database.NewValidationError(errors.New("параметры 'page' и 'cursor' нельзя указывать одновременно"))
+		}
+
+		songs, err := h.Songs.List(filter, order, page, limit)
+		if err != nil {
+			return songListPage{}, err
+		}
+
+		result := songListPage{songs: songs}
+		if len(songs) == limit {
+			result.next = withParam(query, "page", strconv.Itoa(page+1))
+		}
+		if page > 1 {
+			result.prev = withParam(query, "page", strconv.Itoa(page-1))
+		}
+		return result, nil
+	}
+
+	var cursor *database.Cursor
+	if value := query.Get("cursor"); value != "" {
+		var err error
+		if cursor, err = database.DecodeCursor(value); err != nil {
+			return songListPage{}, err
+		}
+	}
+
+	songPage, err := h.Songs.ListAfter(filter, order, cursor, limit)
+	if err != nil {
+		return songListPage{}, err
+	}
+
+	result := songListPage{songs: songPage.Songs}
+	if songPage.Next != nil {
+		result.next = withParam(query, "cursor", songPage.Next.Encode())
+	}
+	if songPage.Prev != nil {
+		result.prev = withParam(query, "cursor", songPage.Prev.Encode())
+	}
+	return result, nil
+}
+
+// writeSongList отправляет клиенту страницу песен, удовлетворяющих фильтру, в порядке из параметра sort.
+// Ссылки на соседние страницы передаются в заголовке Link, общее количество — в X-Total-Count, если указан total=true.
+func (h *Handler) writeSongList(w http.ResponseWriter, r *http.Request, filter database.SongFilter) {
+	order, err := database.ParseSongSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	total, err := wantTotal(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.listSongs(r, filter, order)
+	if err != nil {
+		log.Printf("Ошибка при получении данных: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	if total {
+		count, err := h.Songs.Count(filter)
+		if err != nil {
+			log.Printf("Ошибка при подсчете записей: %v", err)
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("X-Total-Count", strconv.FormatInt(count, 10))
+	}
+	setLinkHeader(w, r, page)
+
+	log.Printf("Получено %d сообщений\n", len(page.songs))
+	json.NewEncoder(w).Encode(page.songs)
+}
+
+// withParam возвращает копию параметров запроса с замененным значением параметра.
+func withParam(query url.Values, name, value string) url.Values {
+	result := url.Values{}
+	for key, values := range query {
+		result[key] = append([]string(nil), values...)
+	}
+	result.Set(name, value)
+	return result
+}
+
+// setLinkHeader устанавливает заголовок Link (RFC 8288) со ссылками на следующую и предыдущую страницы.
+func setLinkHeader(w http.ResponseWriter, r *http.Request, page songListPage) {
+	var links []string
+	if page.next != nil {
+		links = append(links, fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, page.next.Encode()))
+	}
+	if page.prev != nil {
+		links = append(links, fmt.Sprintf("<%s?%s>; rel=\"prev\"", r.URL.Path, page.prev.Encode()))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// wantTotal проверяет, запросил ли клиент общее количество записей параметром total.
+func wantTotal(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("total")
+	if value == "" {
+		return false, nil
+	}
+	total, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, database.NewValidationError(errors.New("параметр 'total' должен быть true или false"))
+	}
+	return total, nil
+}
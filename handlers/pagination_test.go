@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// linkURL выделяет ссылку с указанным отношением из заголовка Link.
+func linkURL(header, rel string) string {
+	match := regexp.MustCompile(`<([^>]+)>; rel="` + rel + `"`).FindStringSubmatch(header)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// getSongs выполняет запрос списка песен и возвращает ответ и названия песен.
+func getSongs(t *testing.T, router *mux.Router, target string) (*httptest.ResponseRecorder, []string) {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	assert.Equal(t, http.StatusOK, rec.Code, target)
+
+	var response []models.MusicInfo
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	songs := []string{}
+	for _, songInfo := range response {
+		songs = append(songs, songInfo.Song)
+	}
+	return rec, songs
+}
+
+func TestGetSongsHandlerCursor(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Muse", Song: fmt.Sprintf("Song %d", i), Text: "Ooh"}))
+	}
+
+	router := NewRouter(h)
+
+	// Проверяем переход вперед
+	rec, songs := getSongs(t, router, "/songs?group=Muse&limit=2&total=true")
+	assert.Equal(t, []string{"Song 1", "Song 2"}, songs)
+	assert.Equal(t, "5", rec.Header().Get("X-Total-Count"))
+	assert.Empty(t, linkURL(rec.Header().Get("Link"), "prev"))
+	next := linkURL(rec.Header().Get("Link"), "next")
+	assert.Contains(t, next, "group=Muse")
+
+	// Вставка перед текущей позицией не сдвигает следующую страницу
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Muse", Song: "Song 0", Text: "Ooh"}))
+
+	rec, songs = getSongs(t, router, next)
+	assert.Equal(t, []string{"Song 3", "Song 4"}, songs)
+
+	rec, songs = getSongs(t, router, linkURL(rec.Header().Get("Link"), "next"))
+	assert.Equal(t, []string{"Song 5"}, songs)
+	assert.Empty(t, linkURL(rec.Header().Get("Link"), "next"))
+
+	// Проверяем переход назад
+	rec, songs = getSongs(t, router, linkURL(rec.Header().Get("Link"), "prev"))
+	assert.Equal(t, []string{"Song 3", "Song 4"}, songs)
+
+	rec, songs = getSongs(t, router, linkURL(rec.Header().Get("Link"), "prev"))
+	assert.Equal(t, []string{"Song 1", "Song 2"}, songs)
+
+	rec, songs = getSongs(t, router, linkURL(rec.Header().Get("Link"), "prev"))
+	assert.Equal(t, []string{"Song 0"}, songs)
+	assert.Empty(t, linkURL(rec.Header().Get("Link"), "prev"))
+	assert.NotEmpty(t, linkURL(rec.Header().Get("Link"), "next"))
+}
+
+func TestGetSongsHandlerPageLinks(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Muse", Song: fmt.Sprintf("Song %d", i), Text: "Ooh"}))
+	}
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	rec, songs := getSongs(t, router, "/songs?page=2&limit=1")
+	assert.Equal(t, []string{"Song 2"}, songs)
+	assert.Equal(t, "/songs?limit=1&page=3", linkURL(rec.Header().Get("Link"), "next"))
+	assert.Equal(t, "/songs?limit=1&page=1", linkURL(rec.Header().Get("Link"), "prev"))
+	assert.Empty(t, rec.Header().Get("X-Total-Count"))
+
+	_, songs = getSongs(t, router, "/songs?page=1&limit=1000")
+	assert.Equal(t, 3, len(songs))
+}
+
+func TestParsePaginationLimitCap(t *testing.T) {
+
+	// Проверяем метод
+	page, limit, err := parsePagination(httptest.NewRequest("GET", "/songs?limit=1000", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page)
+	assert.Equal(t, maxLimit, limit)
+}
+
+func TestParsePaginationOverflow(t *testing.T) {
+
+	// Проверяем метод
+	page, limit, err := parsePagination(httptest.NewRequest("GET", fmt.Sprintf("/songs?page=%d&limit=100", math.MaxInt/100), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, math.MaxInt/100, page)
+	assert.Equal(t, 100, limit)
+
+	for _, target := range []string{
+		"/songs?page=922337203685477581&limit=100",
+		fmt.Sprintf("/songs?page=%d", math.MaxInt),
+	} {
+		_, _, err = parsePagination(httptest.NewRequest("GET", target, nil))
+		assert.ErrorIs(t, err, database.ErrValidation)
+	}
+}
+
+func TestGetSongsHandlerPaginationInvalid(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	for _, target := range []string{"/songs?cursor=%21%21", "/songs?cursor=e30", "/songs?page=2&cursor=e30", "/songs?total=yes", "/songs?page=922337203685477581&limit=100"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+
+		var response ErrorResponse
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, CodeValidation, response.Code, target)
+	}
+}
+
+func TestGetSongsHandlerSort(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	for _, songInfo := range []models.MusicInfo{
+		{Group: "Muse", Song: "Uprising", ReleaseDate: models.MustParseReleaseDate("2009"), Text: "Ooh"},
+		{Group: "Muse", Song: "Starlight", ReleaseDate: models.MustParseReleaseDate("2006"), Text: "Ooh"},
+		{Group: "Muse", Song: "Madness", ReleaseDate: models.MustParseReleaseDate("2012"), Text: "Ooh"},
+	} {
+		assert.NoError(t, h.Songs.Create(&songInfo))
+	}
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	rec, songs := getSongs(t, router, "/songs?sort=-release_date&limit=2")
+	assert.Equal(t, []string{"Madness", "Uprising"}, songs)
+	next := linkURL(rec.Header().Get("Link"), "next")
+	assert.Contains(t, next, "sort=-release_date")
+
+	_, songs = getSongs(t, router, next)
+	assert.Equal(t, []string{"Starlight"}, songs)
+
+	_, songs = getSongs(t, router, "/songs?sort=song&page=1")
+	assert.Equal(t, []string{"Madness", "Starlight", "Uprising"}, songs)
+
+	// Курсор нельзя использовать с другим порядком сортировки
+	for _, target := range []string{"/songs?sort=text", strings.Replace(next, "sort=-release_date", "sort=song", 1)} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
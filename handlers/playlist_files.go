@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music-info/database"
+	"music-info/models"
+	"music-info/playlistfile"
+)
+
+// maxPlaylistFileTracks максимальное количество треков в выгружаемом файле плейлиста.
+const maxPlaylistFileTracks = 10000
+
+// maxPlaylistFileSize максимальный размер загружаемого файла плейлиста.
+const maxPlaylistFileSize = 5 << 20
+
+// PlaylistImportReport результат импорта файла плейлиста.
+// @Description Количество треков в файле и найденных песен, созданный плейлист и треки, для которых песня не найдена.
+type PlaylistImportReport struct {
+	Format  string `json:"format" example:"m3u8"`
+	Total   int    `json:"total" example:"3"`
+	Matched int    `json:"matched" example:"2"`
+	// Playlist созданный плейлист, пуст при dry_run=true.
+	Playlist  *models.Playlist `json:"playlist,omitempty"`
+	Unmatched []UnmatchedTrack `json:"unmatched"`
+}
+
+// UnmatchedTrack трек файла плейлиста, для которого не найдена песня.
+// @Description Трек с номером строки файла и причиной, по которой песня не найдена.
+type UnmatchedTrack struct {
+	Line     int    `json:"line" example:"5"`
+	Artist   string `json:"artist" example:"Muse"`
+	Title    string `json:"title" example:"Unknown Song"`
+	Location string `json:"location" example:"https://example.com/unknown.mp3"`
+	Reason   string `json:"reason" example:"песня не найдена"`
+}
+
+// parsePlaylistFormat возвращает формат файла плейлиста из параметра format. По умолчанию используется M3U8.
+func parsePlaylistFormat(r *http.Request) (playlistfile.Format, error) {
+	value := r.URL.Query().Get("format")
+	if value == "" {
+		return playlistfile.FormatM3U8, nil
+	}
+	format, err := playlistfile.ParseFormat(value)
+	if err != nil {
+		return "", database.NewValidationError(err)
+	}
+	return format, nil
+}
+
+// songTrack возвращает трек файла плейлиста для песни.
+func songTrack(songInfo *models.MusicInfo) playlistfile.Track {
+	return playlistfile.Track{Artist: songInfo.Group, Title: songInfo.Song, Location: songInfo.Link, Duration: -1}
+}
+
+// writePlaylistFile отправляет клиенту файл плейлиста. Количество треков без ссылки,
+// пропущенных в M3U8, передается в заголовке X-Skipped-Count.
+func writePlaylistFile(w http.ResponseWriter, format playlistfile.Format, name string, playlist playlistfile.Playlist) {
+	if format == playlistfile.FormatM3U8 {
+		skipped := 0
+		for _, track := range playlist.Tracks {
+			if track.Location == "" {
+				skipped++
+			}
+		}
+		w.Header().Set("X-Skipped-Count", strconv.Itoa(skipped))
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+	if err := playlistfile.Write(w, format, playlist); err != nil {
+		log.Printf("Ошибка при записи файла плейлиста: %v\n", err)
+	}
+}
+
+// SongsPlaylistFileHandler выгружает список песен как файл плейлиста.
+// @Summary Выгрузить список песен как плейлист
+// @Description Возвращает песни, удовлетворяющие фильтру, в формате расширенного M3U8 или XSPF в порядке из параметра sort.
+// @Description Принимает те же фильтры, что и GET /songs, выгружается не более 10000 песен. В M3U8 песни без ссылки пропускаются
+// @Tags playlists
+// @Produce audio/x-mpegurl
+// @Produce application/xspf+xml
+// @Param format query string false "Формат файла" Enums(m3u8, xspf) default(m3u8)
+// @Param group query string false "Подстрока названия группы"
+// @Param group_eq query string false "Точное название группы"
+// @Param song query string false "Подстрока названия песни"
+// @Param song_eq query string false "Точное название песни"
+// @Param text query string false "Подстрока текста песни"
+// @Param has_link query bool false "Наличие ссылки"
+// @Param artist_id query int false "Идентификатор исполнителя"
+// @Param tag query []string false "Тег в виде вид:имя или имя любого вида, например genre:Rock. При повторении песня должна иметь все теги" collectionFormat(multi)
+// @Param released_from query string false "Дата выпуска не раньше"
+// @Param released_to query string false "Дата выпуска не позже"
+// @Param created_from query string false "Время создания не раньше"
+// @Param created_to query string false "Время создания не позже"
+// @Param updated_from query string false "Время изменения не раньше"
+// @Param updated_to query string false "Время изменения не позже"
+// @Param sort query string false "Порядок: поля group, song, release_date, created_at, updated_at, id через запятую, минус означает обратный порядок" default(group,song)
+// @Success 200 {file} file "Файл плейлиста"
+// @Header 200 {integer} X-Skipped-Count "Количество песен без ссылки, пропущенных в M3U8"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/playlist [get]
+func (h *Handler) SongsPlaylistFileHandler(w http.ResponseWriter, r *http.Request) {
+	format, err := parsePlaylistFormat(r)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	filter, err := parseSongFilter(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	order, err := database.ParseSongSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+
+	var playlist playlistfile.Playlist
+	var cursor *database.Cursor
+	for len(playlist.Tracks) < maxPlaylistFileTracks {
+		page, err := h.Songs.ListAfter(filter, order, cursor, min(maxLimit, maxPlaylistFileTracks-len(playlist.Tracks)))
+		if err != nil {
+			log.Printf("Ошибка при получении данных: %v\n", err)
+			writeJSONError(w, err)
+			return
+		}
+		for i := range page.Songs {
+			playlist.Tracks = append(playlist.Tracks, songTrack(&page.Songs[i]))
+		}
+		if page.Next == nil {
+			break
+		}
+		cursor = page.Next
+	}
+
+	log.Printf("Выгружено песен в плейлист %s: %d\n", format, len(playlist.Tracks))
+	writePlaylistFile(w, format, "songs", playlist)
+}
+
+// PlaylistFileHandler выгружает плейлист как файл.
+// @Summary Выгрузить плейлист как файл
+// @Description Возвращает записи плейлиста в формате расширенного M3U8 или XSPF. Удаленные песни и, в M3U8, песни без ссылки пропускаются
+// @Tags playlists
+// @Produce audio/x-mpegurl
+// @Produce application/xspf+xml
+// @Param id path int true "Идентификатор плейлиста"
+// @Param format query string false "Формат файла" Enums(m3u8, xspf) default(m3u8)
+// @Success 200 {file} file "Файл плейлиста"
+// @Header 200 {integer} X-Skipped-Count "Количество песен без ссылки, пропущенных в M3U8"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/export [get]
+func (h *Handler) PlaylistFileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := playlistID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	format, err := parsePlaylistFormat(r)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+
+	stored, err := h.Playlists.PlaylistByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении плейлиста: %v\n", err)
+		writeJSONError(w, err)
+		return
+	}
+
+	playlist := playlistfile.Playlist{Title: stored.Name}
+	for _, entry := range stored.Entries {
+		if entry.Song != nil {
+			playlist.Tracks = append(playlist.Tracks, songTrack(entry.Song))
+		}
+	}
+
+	writePlaylistFile(w, format, "playlist-"+strconv.FormatUint(uint64(id), 10), playlist)
+}
+
+// PlaylistImportHandler импортирует файл плейлиста.
+// @Summary Импортировать файл плейлиста
+// @Description Читает плейлист в формате M3U8 или XSPF и ищет песни по исполнителю и названию трека без учета регистра.
+// @Description Формат берется из параметра format, типа содержимого или определяется по началу файла.
+// @Description Найденные песни добавляются в новый плейлист в порядке файла, если не указан dry_run=true
+// @Tags playlists
+// @Accept audio/x-mpegurl
+// @Accept application/xspf+xml
+// @Produce json
+// @Param file body string true "Содержимое файла плейлиста"
+// @Param format query string false "Формат файла" Enums(m3u8, xspf)
+// @Param name query string false "Название нового плейлиста. По умолчанию название из файла"
+// @Param dry_run query bool false "Только проверить файл, не создавая плейлист"
+// @Success 200 {object} PlaylistImportReport "Результат проверки при dry_run=true"
+// @Success 201 {object} PlaylistImportReport "Плейлист создан"
+// @Header 201 {string} Location "Адрес созданного плейлиста"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса или файл не удалось прочитать"
+// @Failure 413 {object} ErrorResponse "Файл слишком большой"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/import [post]
+func (h *Handler) PlaylistImportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	var format playlistfile.Format
+	if query.Has("format") {
+		var err error
+		if format, err = parsePlaylistFormat(r); err != nil {
+			writeError(w, err)
+			return
+		}
+	} else if detected, ok := playlistfile.FormatByContentType(r.Header.Get("Content-Type")); ok {
+		format = detected
+	}
+
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			writeError(w, database.NewValidationError(errors.New("параметр 'dry_run' должен быть true или false")))
+			return
+		}
+	}
+
+	body := bufio.NewReader(http.MaxBytesReader(w, r.Body, maxPlaylistFileSize))
+	if format == "" {
+		format = playlistfile.Sniff(body)
+	}
+	file, err := playlistfile.Read(body, format)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) || strings.Contains(err.Error(), "request body too large") {
+			sendError(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "Файл плейлиста слишком большой")
+			return
+		}
+		log.Printf("Ошибка при чтении файла плейлиста: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	report := PlaylistImportReport{Format: string(format), Total: len(file.Tracks), Unmatched: []UnmatchedTrack{}}
+	playlist := models.Playlist{Name: query.Get("name"), SongIDs: []uint{}}
+	if strings.TrimSpace(playlist.Name) == "" {
+		playlist.Name = file.Title
+	}
+	if strings.TrimSpace(playlist.Name) == "" {
+		playlist.Name = "Импорт"
+	}
+	for _, track := range file.Tracks {
+		songInfo, reason, err := h.matchTrack(track)
+		if err != nil {
+			log.Printf("Ошибка при поиске песни: %v\n", err)
+			writeError(w, err)
+			return
+		}
+		if songInfo == nil {
+			report.Unmatched = append(report.Unmatched, UnmatchedTrack{
+				Line: track.Line, Artist: track.Artist, Title: track.Title, Location: track.Location, Reason: reason,
+			})
+			continue
+		}
+		report.Matched++
+		playlist.SongIDs = append(playlist.SongIDs, songInfo.ID)
+	}
+
+	log.Printf("Импорт плейлиста %s: треков %d, найдено песен %d\n", format, report.Total, report.Matched)
+	if dryRun {
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	playlist.Normalize()
+	if err := playlist.Validate(); err != nil {
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+	if err := h.Playlists.CreatePlaylist(&playlist); err != nil {
+		log.Printf("Ошибка при создании плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+	if report.Playlist, err = h.Playlists.PlaylistByID(playlist.ID); err != nil {
+		log.Printf("Ошибка при получении плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/playlists/"+strconv.FormatUint(uint64(playlist.ID), 10))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// matchTrack ищет песню по исполнителю и названию трека так же, как проверяется уникальность песни.
+// Если песня не найдена, возвращает причину.
+func (h *Handler) matchTrack(track playlistfile.Track) (*models.MusicInfo, string, error) {
+	if track.Artist == "" || track.Title == "" {
+		return nil, "в треке не указаны исполнитель и название", nil
+	}
+
+	songs, err := h.Songs.List(database.SongFilter{GroupEq: track.Artist, SongEq: track.Title}, database.DefaultSongSort, 1, 1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(songs) == 0 {
+		return nil, "песня не найдена", nil
+	}
+
+	return &songs[0], "", nil
+}
+
+// writeJSONError отправляет ошибку в формате JSON обработчиком, который в случае успеха отвечает не JSON.
+func writeJSONError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	writeError(w, err)
+}
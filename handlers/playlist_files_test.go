@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongsPlaylistFileHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	createTestSong(t, h)
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}))
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Link: "https://example.com/queen.mp3"}))
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/playlist?group=muse", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "audio/x-mpegurl; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="songs.m3u8"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "1", rec.Header().Get("X-Skipped-Count"))
+	assert.Equal(t, "#EXTM3U\n#EXTINF:-1,Muse - Supermassive Black Hole\nhttps://www.youtube.com/watch?v=Xsp3_a-PMTw\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/playlist?format=xspf&sort=-group", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/xspf+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Empty(t, rec.Header().Get("X-Skipped-Count"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<creator>Muse</creator>")
+	assert.Contains(t, body, "<title>Starlight</title>")
+	assert.Less(t, strings.Index(body, "Queen"), strings.Index(body, "Muse"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/playlist?format=pls", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestPlaylistFileHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	starlight := models.MusicInfo{Group: "Muse", Song: "Starlight", Link: "https://example.com/starlight.mp3"}
+	assert.NoError(t, h.Songs.Create(&starlight))
+	playlist := models.Playlist{Name: "Вечер", SongIDs: []uint{starlight.ID, songInfo.ID}}
+	assert.NoError(t, h.Playlists.CreatePlaylist(&playlist))
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/playlists/%d/export", playlist.ID), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, fmt.Sprintf(`attachment; filename="playlist-%d.m3u8"`, playlist.ID), rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "#EXTM3U\n#PLAYLIST:Вечер\n"+
+		"#EXTINF:-1,Muse - Starlight\nhttps://example.com/starlight.mp3\n"+
+		"#EXTINF:-1,Muse - Supermassive Black Hole\nhttps://www.youtube.com/watch?v=Xsp3_a-PMTw\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/playlists/999/export", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPlaylistImportHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	file := "#EXTM3U\n#PLAYLIST:Из файла\n" +
+		"#EXTINF:211,muse - supermassive black hole\nhttps://example.com/1.mp3\n" +
+		"#EXTINF:180,Muse - Unknown Song\nhttps://example.com/2.mp3\n" +
+		"https://example.com/3.mp3\n"
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/playlists/import?dry_run=true", strings.NewReader(file)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report PlaylistImportReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "m3u8", report.Format)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Matched)
+	assert.Nil(t, report.Playlist)
+	assert.Equal(t, []UnmatchedTrack{
+		{Line: 5, Artist: "Muse", Title: "Unknown Song", Location: "https://example.com/2.mp3", Reason: "песня не найдена"},
+		{Line: 7, Location: "https://example.com/3.mp3", Reason: "в треке не указаны исполнитель и название"},
+	}, report.Unmatched)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/playlists/import", strings.NewReader(file)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	report = PlaylistImportReport{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	if assert.NotNil(t, report.Playlist) {
+		assert.Equal(t, "Из файла", report.Playlist.Name)
+		assert.Equal(t, fmt.Sprintf("/playlists/%d", report.Playlist.ID), rec.Header().Get("Location"))
+		if assert.Equal(t, 1, len(report.Playlist.Entries)) {
+			assert.Equal(t, songInfo.ID, *report.Playlist.Entries[0].SongID)
+		}
+	}
+
+	xspf := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <trackList>
+    <track><creator>Muse</creator><title>Supermassive Black Hole</title></track>
+  </trackList>
+</playlist>`
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/playlists/import?name=Мой", strings.NewReader(xspf)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	report = PlaylistImportReport{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "xspf", report.Format)
+	assert.Equal(t, 1, report.Matched)
+	if assert.NotNil(t, report.Playlist) {
+		assert.Equal(t, "Мой", report.Playlist.Name)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/playlists/import?format=xspf", strings.NewReader("#EXTM3U\n")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/playlists/import?dry_run=maybe", strings.NewReader(file)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
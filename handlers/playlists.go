@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+)
+
+// PlaylistEntryRequest тело запроса на добавление песни в плейлист.
+// @Description Песня и позиция, начиная с 1. Без позиции песня добавляется в конец плейлиста.
+type PlaylistEntryRequest struct {
+	SongID   uint `json:"songId" example:"1"`
+	Position int  `json:"position,omitempty" example:"2"`
+}
+
+// PlaylistMoveRequest тело запроса на перенос записи плейлиста.
+// @Description Новая позиция записи, начиная с 1.
+type PlaylistMoveRequest struct {
+	Position int `json:"position" example:"1"`
+}
+
+// playlistID возвращает идентификатор плейлиста из пути запроса.
+func playlistID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный идентификатор плейлиста")
+	}
+	return uint(id), nil
+}
+
+// playlistEntryID возвращает идентификаторы плейлиста и записи из пути запроса.
+func playlistEntryID(r *http.Request) (uint, uint, error) {
+	id, err := playlistID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	entryID, err := strconv.ParseUint(mux.Vars(r)["entryId"], 10, 64)
+	if err != nil || entryID == 0 {
+		return 0, 0, errors.New("неверный идентификатор записи плейлиста")
+	}
+	return id, uint(entryID), nil
+}
+
+// PlaylistCreateHandler создает плейлист.
+// @Summary Создать плейлист
+// @Description Создает плейлист. Если указаны songIds, плейлист заполняется этими песнями в том же порядке
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist body models.Playlist true "Название, описание и песни плейлиста"
+// @Success 201 {object} models.Playlist "Плейлист создан"
+// @Header 201 {string} Location "Адрес созданного плейлиста"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON, данные не прошли проверку или песни не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists [post]
+func (h *Handler) PlaylistCreateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var playlist models.Playlist
+	if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	playlist.Entries = nil
+	playlist.Normalize()
+	if err := playlist.Validate(); err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	if err := h.Playlists.CreatePlaylist(&playlist); err != nil {
+		log.Printf("Ошибка при создании плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Плейлист создан: id=%d, name=%s\n", playlist.ID, playlist.Name)
+	w.Header().Set("Location", "/playlists/"+strconv.FormatUint(uint64(playlist.ID), 10))
+	h.writePlaylist(w, playlist.ID, http.StatusCreated)
+}
+
+// PlaylistDetailHandler возвращает плейлист с записями.
+// @Summary Получить плейлист
+// @Description Возвращает плейлист с записями в порядке позиций и информацией о песнях.
+// @Description Записи удаленных песен сохраняют группу и название, но возвращаются без песни и с available=false
+// @Tags playlists
+// @Produce json
+// @Param id path int true "Идентификатор плейлиста"
+// @Success 200 {object} models.Playlist "Успешный ответ с плейлистом"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id} [get]
+func (h *Handler) PlaylistDetailHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := playlistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	h.writePlaylist(w, id, http.StatusOK)
+}
+
+// PlaylistInsertHandler добавляет песню в плейлист.
+// @Summary Добавить песню в плейлист
+// @Description Вставляет песню на указанную позицию, сдвигая следующие записи, или добавляет в конец, если позиция не указана
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор плейлиста"
+// @Param entry body PlaylistEntryRequest true "Песня и позиция"
+// @Success 201 {object} models.Playlist "Песня добавлена, ответ содержит обновлённый плейлист"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON, неверная позиция или песня не найдена"
+// @Failure 404 {object} ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/entries [post]
+func (h *Handler) PlaylistInsertHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := playlistID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	var request PlaylistEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+	if request.SongID == 0 {
+		writeError(w, database.NewValidationError(errors.New("поле 'songId' обязательно для заполнения")))
+		return
+	}
+
+	entry, err := h.Playlists.InsertPlaylistEntry(id, request.SongID, request.Position)
+	if err != nil {
+		log.Printf("Ошибка при добавлении песни в плейлист: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("В плейлист id=%d добавлена песня id=%d на позицию %d\n", id, request.SongID, entry.Position)
+	h.writePlaylist(w, id, http.StatusCreated)
+}
+
+// PlaylistMoveHandler переносит запись плейлиста.
+// @Summary Перенести запись плейлиста
+// @Description Переносит запись на новую позицию, сдвигая записи между старой и новой позициями
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор плейлиста"
+// @Param entryId path int true "Идентификатор записи"
+// @Param move body PlaylistMoveRequest true "Новая позиция"
+// @Success 200 {object} models.Playlist "Успешный ответ с обновлённым плейлистом"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или неверная позиция"
+// @Failure 404 {object} ErrorResponse "Плейлист или запись не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/entries/{entryId} [patch]
+func (h *Handler) PlaylistMoveHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, entryID, err := playlistEntryID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	var request PlaylistMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	if err := h.Playlists.MovePlaylistEntry(id, entryID, request.Position); err != nil {
+		log.Printf("Ошибка при переносе записи плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Запись id=%d плейлиста id=%d перенесена на позицию %d\n", entryID, id, request.Position)
+	h.writePlaylist(w, id, http.StatusOK)
+}
+
+// PlaylistRemoveHandler удаляет запись плейлиста.
+// @Summary Удалить запись плейлиста
+// @Description Удаляет запись и сдвигает следующие записи. Сама песня не удаляется
+// @Tags playlists
+// @Param id path int true "Идентификатор плейлиста"
+// @Param entryId path int true "Идентификатор записи"
+// @Success 204 "Запись удалена"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Плейлист или запись не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/entries/{entryId} [delete]
+func (h *Handler) PlaylistRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, entryID, err := playlistEntryID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Playlists.RemovePlaylistEntry(id, entryID); err != nil {
+		log.Printf("Ошибка при удалении записи плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Запись id=%d удалена из плейлиста id=%d\n", entryID, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePlaylist отправляет клиенту плейлист с записями.
+func (h *Handler) writePlaylist(w http.ResponseWriter, id uint, statusCode int) {
+	playlist, err := h.Playlists.PlaylistByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении плейлиста: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(playlist)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// servePlaylist выполняет запрос и разбирает плейлист из ответа.
+func servePlaylist(t *testing.T, router *mux.Router, method, target, body string, status int) models.Playlist {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+	assert.Equal(t, status, rec.Code, rec.Body.String())
+
+	var playlist models.Playlist
+	if rec.Code == http.StatusOK || rec.Code == http.StatusCreated {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &playlist))
+	}
+	return playlist
+}
+
+func TestPlaylistHandlers(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	starlight := models.MusicInfo{Group: "Muse", Song: "Starlight", Text: "Far away"}
+	assert.NoError(t, h.Songs.Create(&starlight))
+
+	// Проверяем методы
+	playlist := servePlaylist(t, router, "POST", "/playlists", fmt.Sprintf(`{"name":" Вечер ","songIds":[%d]}`, songInfo.ID), http.StatusCreated)
+	assert.Equal(t, "Вечер", playlist.Name)
+	assert.Equal(t, 1, len(playlist.Entries))
+	assert.Equal(t, "Supermassive Black Hole", playlist.Entries[0].Song.Song)
+	assert.True(t, playlist.Entries[0].Available)
+	target := fmt.Sprintf("/playlists/%d", playlist.ID)
+
+	playlist = servePlaylist(t, router, "POST", target+"/entries", fmt.Sprintf(`{"songId":%d,"position":1}`, starlight.ID), http.StatusCreated)
+	assert.Equal(t, 2, len(playlist.Entries))
+	assert.Equal(t, starlight.ID, *playlist.Entries[0].SongID)
+
+	moved := playlist.Entries[0].ID
+	playlist = servePlaylist(t, router, "PATCH", fmt.Sprintf("%s/entries/%d", target, moved), `{"position":2}`, http.StatusOK)
+	assert.Equal(t, moved, playlist.Entries[1].ID)
+	assert.Equal(t, 2, playlist.Entries[1].Position)
+
+	servePlaylist(t, router, "PATCH", fmt.Sprintf("%s/entries/%d", target, moved), `{"position":3}`, http.StatusBadRequest)
+	servePlaylist(t, router, "POST", target+"/entries", `{"songId":999}`, http.StatusBadRequest)
+	servePlaylist(t, router, "POST", target+"/entries", `{}`, http.StatusBadRequest)
+	servePlaylist(t, router, "POST", "/playlists", `{"name":""}`, http.StatusBadRequest)
+
+	// Удаленная песня остается в плейлисте недоступной
+	assert.NoError(t, h.Songs.DeleteByID(starlight.ID))
+	playlist = servePlaylist(t, router, "GET", target, "", http.StatusOK)
+	assert.False(t, playlist.Entries[1].Available)
+	assert.Equal(t, "Starlight", playlist.Entries[1].SongName)
+
+	servePlaylist(t, router, "DELETE", fmt.Sprintf("%s/entries/%d", target, moved), "", http.StatusNoContent)
+	playlist = servePlaylist(t, router, "GET", target, "", http.StatusOK)
+	assert.Equal(t, 1, len(playlist.Entries))
+
+	servePlaylist(t, router, "DELETE", fmt.Sprintf("%s/entries/%d", target, moved), "", http.StatusNotFound)
+	servePlaylist(t, router, "GET", "/playlists/999", "", http.StatusNotFound)
+}
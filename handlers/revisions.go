@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music-info/database"
+	"music-info/models"
+	"music-info/textdiff"
+
+	"github.com/gorilla/mux"
+)
+
+// userHeader заголовок с именем пользователя, которое записывается автором правки в историю песни.
+const userHeader = "X-User"
+
+// changeAuthor возвращает автора изменения из заголовка X-User.
+func changeAuthor(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(userHeader))
+}
+
+// revisionVersion возвращает номер версии из пути запроса.
+func revisionVersion(r *http.Request) (int, error) {
+	return parseVersion("version", mux.Vars(r)["version"])
+}
+
+// parseVersion разбирает номер версии песни.
+func parseVersion(name, value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("неверный номер версии '%s' в параметре '%s'", value, name)
+	}
+	return version, nil
+}
+
+// SongRevisionsHandler возвращает историю правок песни.
+// @Summary Получить историю правок песни
+// @Description Возвращает страницу версий песни от новых к старым: номер версии, автора из заголовка X-User, время и измененные поля.
+// @Description Версия записывается при каждом изменении полей песни, включая переименование исполнителя
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество версий на странице, не более 100" default(10)
+// @Success 200 {array} models.RevisionSummary "Версии песни"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или параметры постраничного вывода"
+// @Failure 404 {object} ErrorResponse "Песня не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions [get]
+func (h *Handler) SongRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	revisions, err := h.Revisions.SongRevisions(id, page, limit)
+	if err != nil {
+		log.Printf("Ошибка при получении истории правок: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// SongRevisionHandler возвращает версию песни.
+// @Summary Получить версию песни
+// @Description Возвращает состояние полей песни в указанной версии
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param version path int true "Номер версии"
+// @Success 200 {object} models.SongRevision "Версия песни"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или номер версии"
+// @Failure 404 {object} ErrorResponse "Песня или версия не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{version} [get]
+func (h *Handler) SongRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	version, err := revisionVersion(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	revision, err := h.Revisions.SongRevision(id, version)
+	if err != nil {
+		log.Printf("Ошибка при получении версии песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(revision)
+}
+
+// SongRevisionDiffHandler сравнивает тексты двух версий песни.
+// @Summary Сравнить тексты версий песни
+// @Description Возвращает построчное сравнение текста версии from с текстом версии to. Версии можно сравнивать в любом порядке.
+// @Description По умолчанию to — последняя версия, from — предыдущая перед to
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param from query int false "Номер старой версии"
+// @Param to query int false "Номер новой версии"
+// @Success 200 {object} models.TextDiff "Сравнение текстов"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или номера версий"
+// @Failure 404 {object} ErrorResponse "Песня или версия не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/diff [get]
+func (h *Handler) SongRevisionDiffHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	to := 0
+	if value := query.Get("to"); value != "" {
+		if to, err = parseVersion("to", value); err != nil {
+			writeError(w, database.NewValidationError(err))
+			return
+		}
+	} else {
+		latest, err := h.Revisions.SongRevisions(id, 1, 1)
+		if err != nil {
+			log.Printf("Ошибка при получении истории правок: %v\n", err)
+			writeError(w, err)
+			return
+		}
+		if len(latest) > 0 {
+			to = latest[0].Version
+		}
+	}
+	from := to - 1
+	if value := query.Get("from"); value != "" {
+		if from, err = parseVersion("from", value); err != nil {
+			writeError(w, database.NewValidationError(err))
+			return
+		}
+	}
+	if from < 1 {
+		writeError(w, database.NewValidationError(errors.New("у песни нет предыдущей версии, укажите параметр 'from'")))
+		return
+	}
+
+	older, err := h.Revisions.SongRevision(id, from)
+	if err != nil {
+		log.Printf("Ошибка при получении версии песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+	newer, err := h.Revisions.SongRevision(id, to)
+	if err != nil {
+		log.Printf("Ошибка при получении версии песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	diff := models.TextDiff{SongID: id, From: from, To: to, Lines: []models.DiffLine{}}
+	for _, edit := range textdiff.Lines(textdiff.Split(older.Text), textdiff.Split(newer.Text)) {
+		switch edit.Op {
+		case textdiff.Insert:
+			diff.Added++
+		case textdiff.Delete:
+			diff.Deleted++
+		}
+		diff.Lines = append(diff.Lines, models.DiffLine{
+			Op:        edit.Op.String(),
+			OldNumber: edit.OldLine,
+			NewNumber: edit.NewLine,
+			Text:      edit.Text,
+		})
+	}
+
+	json.NewEncoder(w).Encode(diff)
+}
+
+// SongRollbackHandler восстанавливает версию песни.
+// @Summary Откатить песню к версии
+// @Description Заменяет поля песни значениями из указанной версии. Откат записывается в историю новой версией,
+// @Description поэтому его тоже можно отменить. Если поля уже совпадают с версией, новая версия не создается
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param version path int true "Номер восстанавливаемой версии"
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} models.MusicInfo "Песня после отката"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор или номер версии"
+// @Failure 404 {object} ErrorResponse "Песня или версия не найдены"
+// @Failure 409 {object} ErrorResponse "Группа и название версии заняты другой песней"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{version}/rollback [post]
+func (h *Handler) SongRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	version, err := revisionVersion(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	revision, err := h.Revisions.SongRevision(id, version)
+	if err != nil {
+		log.Printf("Ошибка при получении версии песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	songInfo := revision.MusicInfo()
+	songInfo.ChangedBy = changeAuthor(r)
+	if err := h.Songs.ReplaceByID(id, songInfo); err != nil {
+		log.Printf("Ошибка при откате песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня id=%d восстановлена из версии %d\n", id, version)
+	h.writeSongByID(w, id)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongRevisionHandlers(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	path := fmt.Sprintf("/songs/%d", songInfo.ID)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-User", " editor ")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Проверяем метод
+	rec := serve("PUT", "/songs/info/update?group=Muse&song=Supermassive+Black+Hole",
+		`{"text": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nYou set my soul alight\nGlaciers melting"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve("GET", path+"/revisions", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var revisions []models.RevisionSummary
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&revisions))
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, 2, revisions[0].Version)
+	assert.Equal(t, "editor", revisions[0].Author)
+	assert.Equal(t, []string{"text"}, revisions[0].Fields)
+
+	rec = serve("GET", path+"/revisions/diff", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var diff models.TextDiff
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&diff))
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+	assert.Equal(t, 1, diff.Added)
+	assert.Equal(t, 1, diff.Deleted)
+	assert.Equal(t, models.DiffLine{Op: "delete", OldNumber: 4, Text: "Ooh"}, diff.Lines[3])
+	assert.Equal(t, models.DiffLine{Op: "equal", OldNumber: 5, NewNumber: 4, Text: "You set my soul alight"}, diff.Lines[4])
+	assert.Equal(t, models.DiffLine{Op: "insert", NewNumber: 5, Text: "Glaciers melting"}, diff.Lines[5])
+
+	rec = serve("GET", path+"/revisions/diff?from=2&to=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&diff))
+	assert.Equal(t, "insert", diff.Lines[3].Op)
+
+	rec = serve("POST", path+"/revisions/1/rollback", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var restored models.MusicInfo
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&restored))
+	assert.Equal(t, songInfo.Text, restored.Text)
+
+	rec = serve("GET", path+"/revisions/3", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var revision models.SongRevision
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&revision))
+	assert.Equal(t, songInfo.Text, revision.Text)
+	assert.Equal(t, []string{"text"}, revision.Fields)
+
+	// Откат к совпадающей версии не создает новую версию
+	rec = serve("POST", path+"/revisions/3/rollback", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve("GET", path+"/revisions/4", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve("GET", path+"/revisions/diff?from=0", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve("GET", path+"/revisions/diff?to=1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve("GET", path+"/revisions/diff?from=1&to=9", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve("POST", "/songs/999/revisions/1/rollback", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+)
+
+// NewRouter регистрирует все маршруты API с обработчиками h. Используется сервером и тестами,
+// поэтому тесты проверяют обработчики по тем же путям и методам, что и в работающем приложении.
+func NewRouter(h *Handler) *mux.Router {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/songs/add", h.SongCreateHandler).Methods("POST")
+	router.HandleFunc("/songs/import", h.SongImportHandler).Methods("POST")
+	router.HandleFunc("/songs/export", h.SongExportHandler).Methods("GET")
+	router.HandleFunc("/songs", h.GetSongsHandler).Methods("GET")
+	router.HandleFunc("/songs/search", h.SearchSongsHandler).Methods("GET")
+	router.HandleFunc("/songs/facets", h.TagFacetsHandler).Methods("GET")
+	router.HandleFunc("/songs/playlist", h.SongsPlaylistFileHandler).Methods("GET")
+	router.HandleFunc("/songs/trash", h.DeletedSongsHandler).Methods("GET")
+	router.HandleFunc("/songs/trash/{id:[0-9]+}", h.SongPurgeHandler).Methods("DELETE")
+	router.HandleFunc("/songs/{id:[0-9]+}", h.SongDetailByIDHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}", h.SongReplaceByIDHandler).Methods("PUT")
+	router.HandleFunc("/songs/{id:[0-9]+}", h.SongPatchByIDHandler).Methods("PATCH")
+	router.HandleFunc("/songs/{id:[0-9]+}", h.SongDeleteByIDHandler).Methods("DELETE")
+	router.HandleFunc("/songs/{id:[0-9]+}/text", h.SongTextByIDHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/restore", h.SongRestoreHandler).Methods("POST")
+	router.HandleFunc("/songs/{id:[0-9]+}/tags", h.SongTagsHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/tags", h.SongAddTagsHandler).Methods("POST")
+	router.HandleFunc("/songs/{id:[0-9]+}/tags/{tagId:[0-9]+}", h.SongRemoveTagHandler).Methods("DELETE")
+	router.HandleFunc("/songs/{id:[0-9]+}/lyrics.lrc", h.SongLyricsHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/lyrics.lrc", h.SongSaveLyricsHandler).Methods("PUT")
+	router.HandleFunc("/songs/{id:[0-9]+}/lyrics.lrc", h.SongDeleteLyricsHandler).Methods("DELETE")
+	router.HandleFunc("/songs/{id:[0-9]+}/lyrics/line", h.SongLyricsLineHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/revisions", h.SongRevisionsHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/revisions/diff", h.SongRevisionDiffHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/revisions/{version:[0-9]+}", h.SongRevisionHandler).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/revisions/{version:[0-9]+}/rollback", h.SongRollbackHandler).Methods("POST")
+
+	router.HandleFunc("/artists", h.ArtistCreateHandler).Methods("POST")
+	router.HandleFunc("/artists", h.GetArtistsHandler).Methods("GET")
+	router.HandleFunc("/artists/{id:[0-9]+}", h.ArtistDetailHandler).Methods("GET")
+	router.HandleFunc("/artists/{id:[0-9]+}", h.ArtistReplaceHandler).Methods("PUT")
+	router.HandleFunc("/artists/{id:[0-9]+}", h.ArtistDeleteHandler).Methods("DELETE")
+	router.HandleFunc("/artists/{id:[0-9]+}/songs", h.ArtistSongsHandler).Methods("GET")
+
+	router.HandleFunc("/albums", h.AlbumCreateHandler).Methods("POST")
+	router.HandleFunc("/albums/{id:[0-9]+}", h.AlbumDetailHandler).Methods("GET")
+	router.HandleFunc("/albums/{id:[0-9]+}/tracks", h.AlbumTracksHandler).Methods("PUT")
+
+	router.HandleFunc("/playlists", h.PlaylistCreateHandler).Methods("POST")
+	router.HandleFunc("/playlists/import", h.PlaylistImportHandler).Methods("POST")
+	router.HandleFunc("/playlists/{id:[0-9]+}", h.PlaylistDetailHandler).Methods("GET")
+	router.HandleFunc("/playlists/{id:[0-9]+}/export", h.PlaylistFileHandler).Methods("GET")
+	router.HandleFunc("/playlists/{id:[0-9]+}/entries", h.PlaylistInsertHandler).Methods("POST")
+	router.HandleFunc("/playlists/{id:[0-9]+}/entries/{entryId:[0-9]+}", h.PlaylistMoveHandler).Methods("PATCH")
+	router.HandleFunc("/playlists/{id:[0-9]+}/entries/{entryId:[0-9]+}", h.PlaylistRemoveHandler).Methods("DELETE")
+
+	// Устаревшие маршруты с поиском песни по группе и названию
+	router.HandleFunc("/songs/info", Deprecated(h.SongDetailHandler)).Methods("GET")
+	router.HandleFunc("/songs/info/text", Deprecated(h.SongTextHandler)).Methods("GET")
+	router.HandleFunc("/songs/info/update", Deprecated(h.SongUpdateHandler)).Methods("PUT")
+	router.HandleFunc("/songs/info/delete", Deprecated(h.SongDeleteHandler)).Methods("DELETE")
+
+	return router
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"music-info/database"
+)
+
+// SearchSongsHandler ищет песни по строке текста, названию или группе.
+// @Summary Полнотекстовый поиск песен
+// @Description Ищет песни по группе, названию и тексту с учетом морфологии русского и английского языков.
+// @Description Запрос поддерживает синтаксис websearch: "точная фраза", OR и исключение слов через -. Результаты упорядочены по релевантности
+// @Tags songs
+// @Produce json
+// @Param q query string true "Поисковый запрос, например строка из текста песни"
+// @Param lang query string false "Язык морфологии: ru или en. По умолчанию используются оба" Enums(ru, en)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество результатов на странице" default(10)
+// @Success 200 {array} models.SearchResult "Найденные песни с фрагментами текста"
+// @Failure 400 {object} ErrorResponse "Пустой запрос, неподдерживаемый язык или неверные параметры постраничного вывода"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/search [get]
+func (h *Handler) SearchSongsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, database.NewValidationError(errors.New("параметр 'q' обязателен для заполнения")))
+		return
+	}
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	results, err := h.Songs.Search(query, r.URL.Query().Get("lang"), page, limit)
+	if err != nil {
+		log.Printf("Ошибка при поиске песен: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Найдено %d песен по запросу: %s\n", len(results), query)
+	json.NewEncoder(w).Encode(results)
+}
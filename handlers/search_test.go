@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchSongsHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/search?q=soul&lang=en", nil))
+
+	var results []models.SearchResult
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, songInfo.ID, results[0].ID)
+	assert.Equal(t, 2, results[0].Verse)
+	assert.Contains(t, results[0].Snippet, "<b>soul</b>")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/search?q=rhapsody", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestSearchSongsHandlerInvalid(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	for _, target := range []string{"/songs/search", "/songs/search?q=%20", "/songs/search?q=soul&lang=de"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+
+		var response ErrorResponse
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, CodeValidation, response.Code, target)
+	}
+}
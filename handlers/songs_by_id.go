@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+)
+
+// songID возвращает идентификатор песни из пути запроса.
+func songID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный идентификатор песни")
+	}
+	return uint(id), nil
+}
+
+// Deprecated помечает устаревший маршрут заголовком Deprecation.
+func Deprecated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		next(w, r)
+	}
+}
+
+// SongDetailByIDHandler возвращает информацию о песне по идентификатору.
+// @Summary Получить песню
+// @Description Возвращает информацию о песне, включая идентификатор и даты создания и изменения
+// @Tags songs
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} models.MusicInfo "Успешный ответ с информацией о песне"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [get]
+func (h *Handler) SongDetailByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	songInfo, err := h.Songs.DetailByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня найдена: id=%d\n", id)
+	json.NewEncoder(w).Encode(songInfo)
+}
+
+// SongTextByIDHandler возвращает текст песни, разбитый на куплеты.
+// @Summary Получить куплеты песни
+// @Description Возвращает страницу куплетов песни и общее количество куплетов. Параметр verse позволяет получить один куплет по номеру
+// @Tags songs
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param verse query int false "Номер куплета, начиная с 1"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество куплетов на странице" default(10)
+// @Success 200 {object} models.SongText "Успешный ответ с куплетами песни"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Запись или куплет не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/text [get]
+func (h *Handler) SongTextByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	songInfo, err := h.Songs.DetailByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	writeSongText(w, r, songInfo)
+}
+
+// SongReplaceByIDHandler заменяет информацию о песне.
+// @Summary Заменить информацию о песне
+// @Description Заменяет все поля песни переданными данными. Незаполненные поля releaseDate и link очищаются
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param songInfo body models.MusicInfo true "Новые данные песни"
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} models.MusicInfo "Успешный ответ с обновлённой информацией о песне"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или данные не прошли проверку"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 409 {object} ErrorResponse "Песня с новыми группой и названием уже существует"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [put]
+func (h *Handler) SongReplaceByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	var songInfo models.MusicInfo
+	if err := json.NewDecoder(r.Body).Decode(&songInfo); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	if err := songInfo.Validate(); err != nil {
+		log.Printf("Ошибка валидации: %v\n", err)
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+	songInfo.ChangedBy = changeAuthor(r)
+
+	if err := h.Songs.ReplaceByID(id, &songInfo); err != nil {
+		log.Printf("Ошибка при обновлении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	h.writeSongByID(w, id)
+}
+
+// SongPatchByIDHandler частично обновляет информацию о песне документом JSON Merge Patch (RFC 7396).
+// @Summary Частично обновить информацию о песне
+// @Description Применяет к песне документ JSON Merge Patch: отсутствующие поля не изменяются, поля со значением null или "" очищаются.
+// @Description Поля group и song в документе переименовывают песню. Результат должен проходить проверку обязательных полей
+// @Tags songs
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param patch body models.MusicInfo true "Документ JSON Merge Patch"
+// @Param X-User header string false "Автор изменения для истории правок"
+// @Success 200 {object} models.MusicInfo "Успешный ответ с обновлённой информацией о песне"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или результат не прошел проверку"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 409 {object} ErrorResponse "Песня с новыми группой и названием уже существует"
+// @Failure 415 {object} ErrorResponse "Неподдерживаемый тип содержимого"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [patch]
+func (h *Handler) SongPatchByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != "" && contentType != "application/merge-patch+json" && contentType != "application/json" {
+		sendError(w, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Ожидается тип содержимого application/merge-patch+json")
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(patch) {
+		log.Printf("Ошибка при чтении документа merge patch: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	author := changeAuthor(r)
+	err = h.Songs.PatchByID(id, func(songInfo *models.MusicInfo) error {
+		if err := songInfo.ApplyMergePatch(patch); err != nil {
+			return database.NewValidationError(err)
+		}
+		if err := songInfo.Validate(); err != nil {
+			return database.NewValidationError(err)
+		}
+		songInfo.ChangedBy = author
+		return nil
+	})
+	if err != nil {
+		log.Printf("Ошибка при изменении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	h.writeSongByID(w, id)
+}
+
+// SongDeleteByIDHandler удаляет песню по идентификатору.
+// @Summary Удалить песню
+// @Description Переносит песню в корзину. Ее можно восстановить через POST /songs/{id}/restore до окончательного удаления
+// @Tags songs
+// @Param id path int true "Идентификатор песни"
+// @Success 204 "Запись успешно удалена"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [delete]
+func (h *Handler) SongDeleteByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Songs.DeleteByID(id); err != nil {
+		log.Printf("Ошибка при удалении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня удалена: id=%d\n", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSongByID отправляет клиенту актуальную информацию о песне после изменения.
+func (h *Handler) writeSongByID(w http.ResponseWriter, id uint) {
+	songInfo, err := h.Songs.DetailByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня обновлена: id=%d\n", id)
+	json.NewEncoder(w).Encode(songInfo)
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestSong сохраняет тестовую песню в хранилище.
+func createTestSong(t *testing.T, h *Handler) models.MusicInfo {
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	err := h.Songs.Create(&songInfo)
+	assert.NoError(t, err)
+	return songInfo
+}
+
+func TestSongDetailByIDHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем метод
+	req, err := http.NewRequest("GET", fmt.Sprintf("/songs/%d", songInfo.ID), nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.False(t, response.CreatedAt.IsZero())
+	assert.False(t, response.UpdatedAt.IsZero())
+	assert.Equal(t, songInfo.Song, response.Song)
+}
+
+func TestSongDetailByIDHandlerNotFound(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+
+	// Проверяем метод
+	req, err := http.NewRequest("GET", "/songs/42", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSongTextByIDHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем метод
+	req, err := http.NewRequest("GET", fmt.Sprintf("/songs/%d/text?verse=2", songInfo.ID), nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	var response models.SongText
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.SongID)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, []models.Verse{{Number: 2, Text: "Ooh\nYou set my soul alight"}}, response.Verses)
+}
+
+func TestSongReplaceByIDHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	body, _ := json.Marshal(models.MusicInfo{
+		Group: "Muse",
+		Song:  "Supermassive Black Hole",
+		Text:  "I thought I was a fool for no-one",
+	})
+
+	// Проверяем метод
+	req, err := http.NewRequest("PUT", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.Equal(t, "I thought I was a fool for no-one", response.Text)
+	assert.Equal(t, "", response.Link)
+	assert.True(t, response.ReleaseDate.IsZero())
+
+	// Проверяем валидацию
+	req, err = http.NewRequest("PUT", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(`{"group":"Muse"}`))
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSongPatchByIDHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем метод
+	req, err := http.NewRequest("PATCH", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(`{"link":"https://example.com/muse"}`))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/muse", response.Link)
+	assert.Equal(t, songInfo.Text, response.Text)
+
+	// Проверяем отсутствующую запись
+	req, err = http.NewRequest("PATCH", "/songs/42", bytes.NewBufferString(`{"link":"https://example.com/muse"}`))
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSongPatchByIDHandlerMergePatch(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем очистку полей и переименование
+	req, err := http.NewRequest("PATCH", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(`{"song":"Uprising","link":null,"releaseDate":""}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	var response models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, songInfo.ID, response.ID)
+	assert.Equal(t, "Muse", response.Group)
+	assert.Equal(t, "Uprising", response.Song)
+	assert.Equal(t, "", response.Link)
+	assert.True(t, response.ReleaseDate.IsZero())
+	assert.Equal(t, songInfo.Text, response.Text)
+
+	// Проверяем, что результат проверяется через Validate
+	req, err = http.NewRequest("PATCH", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(`{"text":null}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	rec = httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), CodeValidation)
+
+	// Проверяем неподдерживаемый тип содержимого
+	req, err = http.NewRequest("PATCH", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(`<song/>`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/xml")
+
+	rec = httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestSongPatchByIDHandlerConcurrent(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+	patches := []string{`{"link":"https://example.com/muse"}`, `{"releaseDate":"2006"}`, `{"text":"Ooh"}`}
+
+	// Проверяем, что одновременные изменения разных полей не теряются
+	var wg sync.WaitGroup
+	for _, patch := range patches {
+		wg.Add(1)
+		go func(patch string) {
+			defer wg.Done()
+			req := httptest.NewRequest("PATCH", fmt.Sprintf("/songs/%d", songInfo.ID), bytes.NewBufferString(patch))
+			rec := httptest.NewRecorder()
+			NewRouter(h).ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}(patch)
+	}
+	wg.Wait()
+
+	result, err := h.Songs.DetailByID(songInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/muse", result.Link)
+	assert.Equal(t, "2006", result.ReleaseDate.String())
+	assert.Equal(t, "Ooh", result.Text)
+}
+
+func TestSongPatchByIDHandlerConflict(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	other := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia is in bloom"}
+	err := h.Songs.Create(&other)
+	assert.NoError(t, err)
+
+	// Проверяем метод
+	req, err := http.NewRequest("PATCH", fmt.Sprintf("/songs/%d", other.ID), bytes.NewBufferString(`{"song":"Supermassive Black Hole"}`))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"id":%d`, songInfo.ID))
+}
+
+func TestSongDeleteByIDHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	songInfo := createTestSong(t, h)
+
+	// Проверяем метод
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("/songs/%d", songInfo.ID), nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	_, err = h.Songs.DetailByID(songInfo.ID)
+	assert.Error(t, err)
+
+	// Повторное удаление
+	rec = httptest.NewRecorder()
+	NewRouter(h).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDeprecated(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	createTestSong(t, h)
+
+	router := NewRouter(h)
+
+	// Проверяем метод
+	req, err := http.NewRequest("GET", "/songs/info?group=Muse&song=Supermassive%20Black%20Hole", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/gorilla/mux"
+)
+
+// tagID возвращает идентификатор тега из пути запроса.
+func tagID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["tagId"], 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный идентификатор тега")
+	}
+	return uint(id), nil
+}
+
+// SongTagsHandler возвращает теги песни.
+// @Summary Получить теги песни
+// @Description Возвращает жанры, настроения и произвольные теги песни в порядке вида и имени
+// @Tags tags
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {array} models.Tag "Успешный ответ с тегами песни"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/tags [get]
+func (h *Handler) SongTagsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	h.writeSongTags(w, id)
+}
+
+// SongAddTagsHandler добавляет теги песне.
+// @Summary Добавить теги песне
+// @Description Добавляет песне теги. Отсутствующие теги создаются, тег без вида считается произвольным (tag).
+// @Description Имена сравниваются без учета регистра, теги, которые уже есть у песни, пропускаются
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Param tags body []models.Tag true "Добавляемые теги: вид и имя"
+// @Success 200 {array} models.Tag "Успешный ответ со всеми тегами песни"
+// @Failure 400 {object} ErrorResponse "Неверный формат JSON или теги не прошли проверку"
+// @Failure 404 {object} ErrorResponse "Запись не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/tags [post]
+func (h *Handler) SongAddTagsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	var tags []models.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tags); err != nil {
+		log.Printf("Ошибка при декодировании JSON: %v\n", err)
+		sendError(w, http.StatusBadRequest, CodeBadRequest, "Неверный формат JSON")
+		return
+	}
+
+	for i := range tags {
+		tags[i].ID = 0
+		tags[i].Normalize()
+		if err := tags[i].Validate(); err != nil {
+			log.Printf("Ошибка валидации: %v\n", err)
+			writeError(w, database.NewValidationError(err))
+			return
+		}
+	}
+
+	if err := h.Tags.AddSongTags(id, tags); err != nil {
+		log.Printf("Ошибка при добавлении тегов: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песне id=%d добавлено тегов: %d\n", id, len(tags))
+	h.writeSongTags(w, id)
+}
+
+// SongRemoveTagHandler убирает тег у песни.
+// @Summary Убрать тег у песни
+// @Tags tags
+// @Param id path int true "Идентификатор песни"
+// @Param tagId path int true "Идентификатор тега"
+// @Success 204 "Тег убран"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "У песни нет такого тега"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/tags/{tagId} [delete]
+func (h *Handler) SongRemoveTagHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	tag, err := tagID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Tags.RemoveSongTag(id, tag); err != nil {
+		log.Printf("Ошибка при удалении тега: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("У песни id=%d убран тег id=%d\n", id, tag)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TagFacetsHandler возвращает количество песен по тегам.
+// @Summary Получить количество песен по тегам
+// @Description Возвращает теги песен, удовлетворяющих фильтру, с количеством песен для каждого тега.
+// @Description Принимает те же фильтры, что и GET /songs. Теги упорядочены по виду и убыванию количества
+// @Tags tags
+// @Produce json
+// @Param kind query string false "Вид тегов" Enums(genre, mood, tag)
+// @Param group query string false "Подстрока названия группы"
+// @Param song query string false "Подстрока названия песни"
+// @Param artist_id query int false "Идентификатор исполнителя"
+// @Param tag query []string false "Тег в виде вид:имя или имя любого вида" collectionFormat(multi)
+// @Success 200 {array} models.TagFacet "Успешный ответ с количеством песен по тегам"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/facets [get]
+func (h *Handler) TagFacetsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	kind, err := models.ParseTagKind(query.Get("kind"))
+	if err != nil {
+		writeError(w, database.NewValidationError(err))
+		return
+	}
+
+	filter, err := parseSongFilter(query)
+	if err != nil {
+		log.Printf("Ошибка в параметрах фильтра: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	facets, err := h.Tags.TagFacets(filter, kind)
+	if err != nil {
+		log.Printf("Ошибка при подсчете песен по тегам: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(facets)
+}
+
+// writeSongTags отправляет клиенту теги песни.
+func (h *Handler) writeSongTags(w http.ResponseWriter, id uint) {
+	tags, err := h.Tags.SongTags(id)
+	if err != nil {
+		log.Printf("Ошибка при получении тегов песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(tags)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongTagsHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	target := fmt.Sprintf("/songs/%d/tags", songInfo.ID)
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", target, bytes.NewBufferString(`[{"kind":"genre","name":" Rock "},{"name":"space"}]`)))
+
+	var tags []models.Tag
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tags))
+	assert.Equal(t, 2, len(tags))
+	assert.Equal(t, models.Tag{ID: tags[0].ID, Kind: models.TagKindGenre, Name: "Rock"}, tags[0])
+	assert.Equal(t, models.TagKindTag, tags[1].Kind)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", target, bytes.NewBufferString(`[{"kind":"style","name":"Rock"}]`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", fmt.Sprintf("%s/%d", target, tags[1].ID), nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tags))
+	assert.Equal(t, 1, len(tags))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", fmt.Sprintf("%s/%d", target, 999), nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/999/tags", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTagFacetsHandler(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	queen := models.MusicInfo{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	assert.NoError(t, h.Songs.Create(&queen))
+	assert.NoError(t, h.Tags.AddSongTags(songInfo.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}, {Kind: models.TagKindMood, Name: "Dark"}}))
+	assert.NoError(t, h.Tags.AddSongTags(queen.ID, []models.Tag{{Kind: models.TagKindGenre, Name: "Rock"}}))
+
+	// Проверяем метод
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/facets", nil))
+
+	var facets []models.TagFacet
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &facets))
+	assert.Equal(t, 2, len(facets))
+	assert.Equal(t, "Rock", facets[0].Name)
+	assert.Equal(t, int64(2), facets[0].Count)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/facets?kind=genre&group=que", nil))
+	facets = nil
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &facets))
+	assert.Equal(t, 1, len(facets))
+	assert.Equal(t, models.TagKindGenre, facets[0].Kind)
+	assert.Equal(t, int64(1), facets[0].Count)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs/facets?kind=style", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs?tag=genre:rock&tag=dark", nil))
+
+	var songs []models.MusicInfo
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &songs))
+	assert.Equal(t, 1, len(songs))
+	assert.Equal(t, songInfo.ID, songs[0].ID)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/songs?tag=mood:", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// DeletedSongsHandler возвращает удаленные песни.
+// @Summary Получить корзину
+// @Description Возвращает страницу удаленных песен, начиная с удаленных последними. Время удаления передается в поле DeletedAt.
+// @Description Песни хранятся в корзине в течение срока TRASH_RETENTION, затем удаляются окончательно
+// @Tags trash
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице, не более 100" default(10)
+// @Success 200 {array} models.MusicInfo "Удаленные песни"
+// @Failure 400 {object} ErrorResponse "Неверные параметры постраничного вывода"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/trash [get]
+func (h *Handler) DeletedSongsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	songs, err := h.Trash.DeletedSongs(page, limit)
+	if err != nil {
+		log.Printf("Ошибка при получении корзины: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(songs)
+}
+
+// SongRestoreHandler восстанавливает удаленную песню.
+// @Summary Восстановить песню из корзины
+// @Description Возвращает удаленную песню в список песен. Исполнитель песни определяется заново по полю group
+// @Tags trash
+// @Produce json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} models.MusicInfo "Восстановленная песня"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Песни нет в корзине"
+// @Failure 409 {object} ErrorResponse "Группа и название песни заняты другой песней"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/restore [post]
+func (h *Handler) SongRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Trash.RestoreSong(id); err != nil {
+		log.Printf("Ошибка при восстановлении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	songInfo, err := h.Songs.DetailByID(id)
+	if err != nil {
+		log.Printf("Ошибка при получении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня восстановлена: id=%d\n", id)
+	json.NewEncoder(w).Encode(songInfo)
+}
+
+// SongPurgeHandler окончательно удаляет песню из корзины.
+// @Summary Удалить песню окончательно
+// @Description Удаляет песню из корзины без возможности восстановления вместе с тегами, синхронизированным текстом,
+// @Description историей правок и треками релизов. Записи плейлистов остаются без песни. Песню нужно сначала удалить через DELETE /songs/{id}
+// @Tags trash
+// @Param id path int true "Идентификатор песни"
+// @Success 204 "Песня удалена окончательно"
+// @Failure 400 {object} ErrorResponse "Неверный идентификатор"
+// @Failure 404 {object} ErrorResponse "Песни нет в корзине"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/trash/{id} [delete]
+func (h *Handler) SongPurgeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := songID(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Trash.PurgeSong(id); err != nil {
+		log.Printf("Ошибка при окончательном удалении песни: %v\n", err)
+		writeError(w, err)
+		return
+	}
+
+	log.Printf("Песня удалена окончательно: id=%d\n", id)
+	w.WriteHeader(http.StatusNoContent)
+}
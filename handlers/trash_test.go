@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongTrashHandlers(t *testing.T) {
+
+	// Создаем тестовые данные
+	h := NewHandler(database.NewMemoryRepository(), nil)
+	router := NewRouter(h)
+	songInfo := createTestSong(t, h)
+	path := fmt.Sprintf("/songs/%d", songInfo.ID)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	// Проверяем метод
+	rec := serve("POST", path+"/restore")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve("DELETE", fmt.Sprintf("/songs/trash/%d", songInfo.ID))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve("DELETE", path)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = serve("GET", "/songs/trash")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var songs []models.MusicInfo
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&songs))
+	assert.Equal(t, 1, len(songs))
+	assert.Equal(t, songInfo.ID, songs[0].ID)
+	assert.True(t, songs[0].DeletedAt.Valid)
+
+	rec = serve("POST", path+"/restore")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var restored models.MusicInfo
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&restored))
+	assert.Equal(t, songInfo.Song, restored.Song)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	rec = serve("GET", "/songs/trash")
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	rec = serve("DELETE", path)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.NoError(t, h.Songs.Create(&models.MusicInfo{Group: songInfo.Group, Song: songInfo.Song, Text: "New"}))
+	rec = serve("POST", path+"/restore")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve("DELETE", fmt.Sprintf("/songs/trash/%d", songInfo.ID))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serve("POST", path+"/restore")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
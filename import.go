@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"music-info/database"
+	"music-info/songfile"
+)
+
+// errImportFailed означает, что часть записей не импортирована. Отчет при этом уже выведен.
+var errImportFailed = errors.New("часть записей не импортирована")
+
+// runImport выполняет команду import: читает песни из файла CSV или JSON Lines и сохраняет их в хранилище.
+// Файл "-" означает стандартный ввод. Отчет в формате JSON выводится в stdout.
+func runImport(repo database.SongRepository, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatName := flags.String("format", "", "формат файла: csv или jsonl, по умолчанию определяется по расширению")
+	dryRun := flags.Bool("dry-run", false, "только проверить записи, не сохраняя песни")
+	batchSize := flags.Int("batch-size", songfile.DefaultBatchSize, "количество песен в одной транзакции")
+	author := flags.String("author", "", "автор, записываемый в историю правок созданных песен")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Использование: music-info import [флаги] ФАЙЛ")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("укажите один файл импорта")
+	}
+	if *batchSize < 1 || *batchSize > songfile.MaxBatchSize {
+		return fmt.Errorf("флаг -batch-size должен быть от 1 до %d", songfile.MaxBatchSize)
+	}
+
+	name := flags.Arg(0)
+	format, ok := songfile.FormatByExtension(name)
+	if *formatName != "" {
+		var err error
+		if format, err = songfile.ParseFormat(*formatName); err != nil {
+			return err
+		}
+	} else if !ok {
+		return fmt.Errorf("не удалось определить формат файла %s по расширению, укажите флаг -format", name)
+	}
+
+	input := stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	reader, err := songfile.NewReader(input, format)
+	if err != nil {
+		return err
+	}
+	report, err := songfile.Import(repo, reader, songfile.Options{BatchSize: *batchSize, DryRun: *dryRun, Author: *author})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	log.Printf("Импорт песен %s: записей %d, создано %d, пропущено %d, с ошибкой %d\n",
+		name, report.Total, report.Created, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		return errImportFailed
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/songfile"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunImport(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	name := filepath.Join(t.TempDir(), "catalog.csv")
+	content := "group,song,text\nMuse,Starlight,Far away\nMuse,Uprising,Paranoia\n"
+	assert.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+
+	// Проверяем метод
+	var out bytes.Buffer
+	assert.NoError(t, runImport(repo, []string{"-dry-run", name}, nil, &out))
+	var report songfile.Report
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Created)
+
+	out.Reset()
+	assert.NoError(t, runImport(repo, []string{"-batch-size", "1", name}, nil, &out))
+	count, err := repo.Count(database.SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	out.Reset()
+	stdin := strings.NewReader(`{"group":"Muse","song":"Hysteria"}` + "\n")
+	assert.ErrorIs(t, runImport(repo, []string{"-format", "jsonl", "-"}, stdin, &out), errImportFailed)
+	report = songfile.Report{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 1, report.Failed)
+
+	assert.Error(t, runImport(repo, []string{"catalog.txt"}, nil, &out))
+	assert.Error(t, runImport(repo, []string{"-batch-size", "0", name}, nil, &out))
+	assert.Error(t, runImport(repo, nil, nil, &out))
+}
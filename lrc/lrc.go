@@ -0,0 +1,110 @@
+// Package lrc разбирает и записывает тексты песен с отметками времени в формате LRC.
+package lrc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line строка текста с временем начала.
+type Line struct {
+	// Time время начала строки без учета смещения.
+	Time time.Duration
+	Text string
+}
+
+// Lyrics синхронизированный текст песни.
+type Lyrics struct {
+	// Artist исполнитель из тега [ar:].
+	Artist string
+	// Title название из тега [ti:].
+	Title string
+	// Album альбом из тега [al:].
+	Album string
+	// Author автор текста из тега [au:].
+	Author string
+	// By автор файла из тега [by:].
+	By string
+	// Offset смещение из тега [offset:] в миллисекундах. Положительное смещение показывает строки раньше.
+	Offset time.Duration
+	// Lines строки в порядке времени. Строка файла с несколькими отметками времени
+	// разворачивается в несколько строк.
+	Lines []Line
+}
+
+// ParseTimestamp разбирает отметку времени без скобок: мм:сс, мм:сс.x, мм:сс.xx или мм:сс.xxx.
+// Дробная часть может отделяться двоеточием. Минуты могут быть больше 59.
+func ParseTimestamp(value string) (time.Duration, error) {
+	minutes, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("неверная отметка времени '%s': ожидается мм:сс.xx", value)
+	}
+	seconds, fraction, _ := strings.Cut(rest, ".")
+	if fraction == "" && strings.Contains(seconds, ":") {
+		seconds, fraction, _ = strings.Cut(seconds, ":")
+	}
+
+	m, err := parseDigits(minutes, 1, 4)
+	if err != nil {
+		return 0, fmt.Errorf("неверная отметка времени '%s': ожидается мм:сс.xx", value)
+	}
+	s, err := parseDigits(seconds, 1, 2)
+	if err != nil || s > 59 {
+		return 0, fmt.Errorf("неверная отметка времени '%s': секунд должно быть от 0 до 59", value)
+	}
+	ms := 0
+	if fraction != "" || strings.HasSuffix(rest, ".") {
+		f, err := parseDigits(fraction, 1, 3)
+		if err != nil {
+			return 0, fmt.Errorf("неверная отметка времени '%s': ожидается до трех цифр долей секунды", value)
+		}
+		for digits := len(fraction); digits < 3; digits++ {
+			f *= 10
+		}
+		ms = f
+	}
+
+	return time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+// parseDigits разбирает число из min..max десятичных цифр без знака.
+func parseDigits(value string, min, max int) (int, error) {
+	if len(value) < min || len(value) > max {
+		return 0, strconv.ErrSyntax
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	return strconv.Atoi(value)
+}
+
+// FormatTimestamp записывает время в виде мм:сс.xx с точностью до сотых секунды. Отрицательное время записывается как 00:00.00.
+func FormatTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	centiseconds := d.Milliseconds() / 10
+	return fmt.Sprintf("%02d:%02d.%02d", centiseconds/6000, centiseconds/100%60, centiseconds%100)
+}
+
+// Active возвращает индекс строки, звучащей в момент воспроизведения at с учетом смещения.
+// Возвращает false, если первая строка еще не началась.
+func (l Lyrics) Active(at time.Duration) (int, bool) {
+	at += l.Offset
+	i := sort.Search(len(l.Lines), func(i int) bool { return l.Lines[i].Time > at })
+	return i - 1, i > 0
+}
+
+// Start возвращает время начала строки в воспроизведении с учетом смещения.
+func (l Lyrics) Start(i int) time.Duration {
+	start := l.Lines[i].Time - l.Offset
+	if start < 0 {
+		return 0
+	}
+	return start
+}
@@ -0,0 +1,71 @@
+package lrc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+
+	// Создаем тестовые данные
+	valid := map[string]time.Duration{
+		"00:12":     12 * time.Second,
+		"01:02.5":   62*time.Second + 500*time.Millisecond,
+		"01:02.50":  62*time.Second + 500*time.Millisecond,
+		"01:02.345": 62*time.Second + 345*time.Millisecond,
+		"01:02:34":  62*time.Second + 340*time.Millisecond,
+		"123:00.00": 123 * time.Minute,
+	}
+
+	// Проверяем метод
+	for value, expected := range valid {
+		parsed, err := ParseTimestamp(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, parsed, value)
+	}
+
+	for _, value := range []string{"", "12", "00:60.00", "aa:00", "00:1x", "00:01.", "00:01.1234", "-1:00"} {
+		_, err := ParseTimestamp(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+
+	// Проверяем метод
+	assert.Equal(t, "00:00.00", FormatTimestamp(-time.Second))
+	assert.Equal(t, "01:02.34", FormatTimestamp(62*time.Second+345*time.Millisecond))
+	assert.Equal(t, "123:00.00", FormatTimestamp(123*time.Minute))
+}
+
+func TestLyricsActive(t *testing.T) {
+
+	// Создаем тестовые данные
+	lyrics := Lyrics{
+		Offset: 500 * time.Millisecond,
+		Lines: []Line{
+			{Time: 10 * time.Second, Text: "first"},
+			{Time: 20 * time.Second, Text: "second"},
+			{Time: 30 * time.Second, Text: "third"},
+		},
+	}
+
+	// Проверяем метод
+	_, ok := lyrics.Active(9 * time.Second)
+	assert.False(t, ok)
+
+	i, ok := lyrics.Active(9*time.Second + 500*time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, 0, i)
+
+	i, ok = lyrics.Active(25 * time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 1, i)
+
+	i, _ = lyrics.Active(time.Hour)
+	assert.Equal(t, 2, i)
+
+	assert.Equal(t, 19*time.Second+500*time.Millisecond, lyrics.Start(1))
+}
@@ -0,0 +1,138 @@
+package lrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineLength максимальная длина строки файла LRC.
+const maxLineLength = 64 * 1024
+
+// Parse читает текст в формате LRC. Строка состоит из одной или нескольких отметок времени [мм:сс.xx]
+// и текста; теги [ar:], [ti:], [al:], [au:], [by:] и [offset:] занимают отдельные строки, прочие теги пропускаются.
+// Отметки времени проверяются на монотонность: внутри строки они должны возрастать, а первая отметка
+// строки не может быть раньше первой отметки предыдущей строки. Ошибка содержит номер строки файла.
+func Parse(r io.Reader) (Lyrics, error) {
+	var lyrics Lyrics
+	var previous time.Duration
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	for number := 1; scanner.Scan(); number++ {
+		text := scanner.Text()
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		times, rest, tag, err := splitLine(text)
+		if err != nil {
+			return Lyrics{}, fmt.Errorf("строка %d: %v", number, err)
+		}
+		if tag != "" {
+			if len(times) > 0 || rest != "" {
+				return Lyrics{}, fmt.Errorf("строка %d: тег [%s] должен занимать отдельную строку", number, tag)
+			}
+			if err := lyrics.setTag(tag); err != nil {
+				return Lyrics{}, fmt.Errorf("строка %d: %v", number, err)
+			}
+			continue
+		}
+		if len(times) == 0 {
+			return Lyrics{}, fmt.Errorf("строка %d: ожидается отметка времени [мм:сс.xx] или тег", number)
+		}
+
+		if times[0] < previous {
+			return Lyrics{}, fmt.Errorf("строка %d: отметка времени %s раньше предыдущей %s",
+				number, FormatTimestamp(times[0]), FormatTimestamp(previous))
+		}
+		for i := 1; i < len(times); i++ {
+			if times[i] <= times[i-1] {
+				return Lyrics{}, fmt.Errorf("строка %d: отметки времени строки должны возрастать: %s после %s",
+					number, FormatTimestamp(times[i]), FormatTimestamp(times[i-1]))
+			}
+		}
+		previous = times[0]
+
+		for _, t := range times {
+			lyrics.Lines = append(lyrics.Lines, Line{Time: t, Text: rest})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Lyrics{}, fmt.Errorf("ошибка чтения LRC: %v", err)
+	}
+
+	// Повторяющиеся строки с несколькими отметками времени встают на свои места
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool { return lyrics.Lines[i].Time < lyrics.Lines[j].Time })
+
+	return lyrics, nil
+}
+
+// splitLine разбирает ведущие скобки строки: отметки времени или один тег вида ключ:значение.
+// Возвращает отметки времени, текст после них и содержимое тега.
+func splitLine(text string) ([]time.Duration, string, string, error) {
+	var times []time.Duration
+	for strings.HasPrefix(text, "[") {
+		end := strings.IndexByte(text, ']')
+		if end < 0 {
+			return nil, "", "", fmt.Errorf("не закрыта скобка в '%s'", text)
+		}
+		content := text[1:end]
+
+		// Скобки после отметок времени, не являющиеся отметкой, считаются частью текста
+		key, _, ok := strings.Cut(content, ":")
+		_, err := parseDigits(key, 1, 4)
+		if len(times) > 0 && (!ok || err != nil) {
+			break
+		}
+		if !ok {
+			return nil, "", "", fmt.Errorf("неверная отметка времени или тег [%s]", content)
+		}
+		if err != nil {
+			return nil, strings.TrimSpace(text[end+1:]), content, nil
+		}
+
+		t, err := ParseTimestamp(content)
+		if err != nil {
+			return nil, "", "", err
+		}
+		times = append(times, t)
+		text = text[end+1:]
+	}
+
+	return times, strings.TrimSpace(text), "", nil
+}
+
+// setTag сохраняет значение тега. Неизвестные теги пропускаются.
+func (l *Lyrics) setTag(tag string) error {
+	key, value, _ := strings.Cut(tag, ":")
+	value = strings.TrimSpace(value)
+
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "ar":
+		l.Artist = value
+	case "ti":
+		l.Title = value
+	case "al":
+		l.Album = value
+	case "au":
+		l.Author = value
+	case "by":
+		l.By = value
+	case "offset":
+		ms, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+		if err != nil {
+			return fmt.Errorf("неверное смещение [offset:%s]: ожидается целое число миллисекунд", value)
+		}
+		l.Offset = time.Duration(ms) * time.Millisecond
+	}
+	return nil
+}
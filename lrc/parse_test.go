@@ -0,0 +1,58 @@
+package lrc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+
+	// Создаем тестовые данные
+	content := "\uFEFF[ar:Muse]\r\n" +
+		"[ti: Supermassive Black Hole ]\r\n" +
+		"[length:03:31]\r\n" +
+		"[offset:+250]\r\n" +
+		"\r\n" +
+		"[00:10.50][01:10.00]Ooh baby, don't you know I suffer?\r\n" +
+		"[00:20.00]Ooh baby, can you hear me moan?\r\n" +
+		"[00:30.00]\r\n" +
+		"[01:20.00][Chorus] You set my soul alight\r\n"
+
+	// Проверяем метод
+	lyrics, err := Parse(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", lyrics.Artist)
+	assert.Equal(t, "Supermassive Black Hole", lyrics.Title)
+	assert.Equal(t, 250*time.Millisecond, lyrics.Offset)
+	assert.Equal(t, []Line{
+		{Time: 10*time.Second + 500*time.Millisecond, Text: "Ooh baby, don't you know I suffer?"},
+		{Time: 20 * time.Second, Text: "Ooh baby, can you hear me moan?"},
+		{Time: 30 * time.Second, Text: ""},
+		{Time: 70 * time.Second, Text: "Ooh baby, don't you know I suffer?"},
+		{Time: 80 * time.Second, Text: "[Chorus] You set my soul alight"},
+	}, lyrics.Lines)
+}
+
+func TestParseErrors(t *testing.T) {
+
+	// Создаем тестовые данные
+	cases := map[string]string{
+		"[00:20.00]second\n[00:10.00]first\n": "строка 2: отметка времени 00:10.00 раньше предыдущей 00:20.00",
+		"[00:20.00][00:10.00]twice\n":         "строка 1: отметки времени строки должны возрастать: 00:10.00 после 00:20.00",
+		"[ar:Muse]\nplain text\n":             "строка 2: ожидается отметка времени [мм:сс.xx] или тег",
+		"[offset:soon]\n":                     "строка 1: неверное смещение [offset:soon]: ожидается целое число миллисекунд",
+		"[00:10.00 text\n":                    "строка 1: не закрыта скобка в '[00:10.00 text'",
+		"[00:61.00]text\n":                    "строка 1: неверная отметка времени '00:61.00': секунд должно быть от 0 до 59",
+		"[ar:Muse] text\n":                    "строка 1: тег [ar:Muse] должен занимать отдельную строку",
+		"[chorus]\n":                          "строка 1: неверная отметка времени или тег [chorus]",
+	}
+
+	// Проверяем метод
+	for content, expected := range cases {
+		_, err := Parse(strings.NewReader(content))
+		assert.EqualError(t, err, expected, content)
+	}
+}
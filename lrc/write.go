@@ -0,0 +1,47 @@
+package lrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Write записывает текст в формате LRC: сначала заполненные теги, затем строки в порядке времени,
+// по одной отметке времени на строку.
+func Write(w io.Writer, lyrics Lyrics) error {
+	buf := bufio.NewWriter(w)
+
+	for _, tag := range []struct{ key, value string }{
+		{"ar", lyrics.Artist},
+		{"ti", lyrics.Title},
+		{"al", lyrics.Album},
+		{"au", lyrics.Author},
+		{"by", lyrics.By},
+	} {
+		if value := singleLine(tag.value); value != "" {
+			fmt.Fprintf(buf, "[%s:%s]\n", tag.key, value)
+		}
+	}
+	if lyrics.Offset != 0 {
+		fmt.Fprintf(buf, "[offset:%+d]\n", lyrics.Offset.Milliseconds())
+	}
+
+	for _, line := range lyrics.Lines {
+		fmt.Fprintf(buf, "[%s]%s\n", FormatTimestamp(line.Time), singleLine(line.Text))
+	}
+
+	return buf.Flush()
+}
+
+// String возвращает текст в формате LRC.
+func (l Lyrics) String() string {
+	var b strings.Builder
+	Write(&b, l)
+	return b.String()
+}
+
+// singleLine заменяет переводы строк пробелами, чтобы значение не нарушило построчный формат.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
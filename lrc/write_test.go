@@ -0,0 +1,36 @@
+package lrc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+
+	// Создаем тестовые данные
+	lyrics := Lyrics{
+		Artist: "Muse",
+		Title:  "Supermassive\nBlack Hole",
+		Offset: -120 * time.Millisecond,
+		Lines: []Line{
+			{Time: 10*time.Second + 505*time.Millisecond, Text: "Ooh baby"},
+			{Time: 70 * time.Second, Text: ""},
+		},
+	}
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, lyrics))
+	assert.Equal(t, "[ar:Muse]\n[ti:Supermassive Black Hole]\n[offset:-120]\n[00:10.50]Ooh baby\n[01:10.00]\n", buf.String())
+	assert.Equal(t, buf.String(), lyrics.String())
+
+	// Записанный текст читается обратно
+	parsed, err := Parse(strings.NewReader(buf.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, lyrics.Offset, parsed.Offset)
+	assert.Equal(t, 2, len(parsed.Lines))
+}
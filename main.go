@@ -11,55 +11,169 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"music-info/config"
 	"music-info/database"
+	"music-info/enrichment"
 	"music-info/handlers"
+	"music-info/models"
+	"music-info/retention"
 
 	_ "music-info/docs"
 
-	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 // initServer инициализирует и возвращает HTTP-сервер.
 func initServer(config config.Config) *http.Server {
-	// Инициализация логгера
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+	// Формат вывода даты выпуска
+	if err := models.SetReleaseDateFormat(config.ReleaseDateFormat); err != nil {
+		log.Fatalf("неверное значение RELEASE_DATE_FORMAT: %v", err)
+	}
 
-	// Загружаем переменные окружения из .env файла
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Ошибка загрузки .env файла: %v", err)
+	// Инициализация базы данных. Без автоматических миграций схема обновляется командой migrate
+	openDatabase(config, config.Database.AutoMigrate)
+
+	// Подключение внешнего сервиса информации о песнях
+	var enricher enrichment.Enricher
+	if config.InfoServiceURL != "" {
+		enricher = enrichment.NewClient(config.InfoServiceURL, config.InfoServiceTimeout)
 	}
 
-	// Инициализация базы данных
-	database.InitDB(config.DSN)
+	h := handlers.NewHandler(database.NewGormRepository(database.DB), enricher)
 
 	// Настройка маршрутизатора
-	router := mux.NewRouter()
-
-	// Регистрируем обработчики
-	router.HandleFunc("/songs/add", handlers.SongCreateHandler).Methods("POST")
-	router.HandleFunc("/songs", handlers.GetSongsHandler).Methods("GET")
-	router.HandleFunc("/songs/info", handlers.SongDetailHandler).Methods("GET")
-	router.HandleFunc("/songs/info/update", handlers.SongUpdateHandler).Methods("PUT")
-	router.HandleFunc("/songs/info/delete", handlers.SongDeleteHandler).Methods("DELETE")
+	router := handlers.NewRouter(h)
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	// Создаем HTTP-сервер
 	return &http.Server{
-		Addr:    ":" + config.Port,
-		Handler: router,
+		Addr:              ":" + config.Port,
+		Handler:           handlers.CORS(config.CORSOrigins)(router),
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+}
+
+// openDatabase открывает базу данных с настройками журнала и пула соединений, при migrate применяя миграции.
+func openDatabase(config config.Config, migrate bool) {
+	if migrate {
+		database.InitDB(config.DSN())
+	} else {
+		database.OpenDB(config.DSN())
+	}
+
+	if err := database.SetLogLevel(database.DB, config.LogLevel); err != nil {
+		log.Fatalf("Ошибка настройки журнала базы данных: %v", err)
+	}
+	err := database.ConfigurePool(database.DB, database.PoolConfig{
+		MaxOpenConns:    config.Database.MaxOpenConns,
+		MaxIdleConns:    config.Database.MaxIdleConns,
+		ConnMaxLifetime: config.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: config.Database.ConnMaxIdleTime,
+	})
+	if err != nil {
+		log.Fatalf("Ошибка настройки пула соединений: %v", err)
 	}
 }
 
 func main() {
-	config := config.LoadConfig()
+	// Инициализация логгера
+	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+
+	config, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	// Команда config выводит итоговую конфигурацию, в том числе с ошибками
+	if len(args) > 0 && args[0] == "config" {
+		if printErr := runConfig(config, args[1:], os.Stdout); printErr != nil && !errors.Is(printErr, flag.ErrHelp) {
+			log.Printf("Ошибка команды config: %v\n", printErr)
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Printf("Ошибки конфигурации:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Ошибки конфигурации:\n%v", err)
+	}
+
+	// Команда import загружает песни из файла и завершается, не запуская сервер
+	if len(args) > 0 && args[0] == "import" {
+		openDatabase(config, config.Database.AutoMigrate)
+		err := runImport(database.NewGormRepository(database.DB), args[1:], os.Stdin, os.Stdout)
+		closeDatabase()
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Printf("Ошибка импорта: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Команда migrate управляет версиями схемы базы данных и завершается, не запуская сервер
+	if len(args) > 0 && args[0] == "migrate" {
+		openDatabase(config, false)
+		migrator, err := database.NewMigrator(database.DB)
+		if err == nil {
+			err = runMigrate(migrator, args[1:], os.Stdout)
+		}
+		closeDatabase()
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Printf("Ошибка миграции: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 {
+		log.Fatalf("неизвестная команда '%s': ожидается import, migrate или config", args[0])
+	}
+
 	server := initServer(config)
+	defer closeDatabase()
+
+	// Очистка корзины включается только заданным сроком хранения
+	var workers []worker
+	if config.TrashRetention > 0 {
+		workers = append(workers, retention.NewJob(database.NewGormRepository(database.DB), config.TrashRetention, config.TrashPurgeInterval))
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatalf("Ошибка запуска сервера: %v", err)
+	}
+
+	// SIGINT и SIGTERM останавливают сервер после завершения начатых запросов
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	log.Printf("Сервер запущен на http://localhost%s\n", server.Addr)
-	log.Fatal(server.ListenAndServe())
+	if err := serve(ctx, server, listener, config.ShutdownTimeout, workers...); err != nil {
+		log.Printf("Сервер остановлен с ошибкой: %v\n", err)
+		closeDatabase()
+		os.Exit(1)
+	}
+	log.Println("Сервер остановлен")
+}
+
+// closeDatabase закрывает пул соединений с базой данных.
+func closeDatabase() {
+	if err := database.Close(); err != nil {
+		log.Printf("Ошибка закрытия соединений с базой данных: %v\n", err)
+	}
 }
@@ -22,15 +22,22 @@ func InitConfig(t *testing.T) config.Config {
 		t.Fatalf("Ошибка загрузки .env файла: %v", err)
 	}
 
-	conf := config.Config{
-		Port: "8080",
-		DSN: "host=" + os.Getenv("DB_HOST") +
-			" user=" + os.Getenv("DB_USER") +
-			" dbname=testdb_test" +
-			" password=" + os.Getenv("DB_PASSWORD") +
-			" port=" + os.Getenv("DB_PORT") +
-			" sslmode=" + os.Getenv("DB_SSLMODE") +
-			" TimeZone=UTC",
+	if os.Getenv("DB_HOST") == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("в CI тестовая база данных должна быть настроена переменной DB_HOST")
+		}
+		t.Skip("тестовая база данных не настроена")
+	}
+
+	conf := config.Default()
+	conf.Database = config.Database{
+		Host:        os.Getenv("DB_HOST"),
+		Port:        os.Getenv("DB_PORT"),
+		User:        os.Getenv("DB_USER"),
+		Password:    os.Getenv("DB_PASSWORD"),
+		Name:        "testdb_test",
+		SSLMode:     os.Getenv("DB_SSLMODE"),
+		AutoMigrate: true,
 	}
 
 	return conf
@@ -60,7 +67,7 @@ func TestServerEndpoints(t *testing.T) {
 	songInfo := models.MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
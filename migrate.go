@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"music-info/database"
+)
+
+// schemaMigrator операции над версиями схемы, нужные команде migrate.
+type schemaMigrator interface {
+	Status() ([]database.MigrationStatus, error)
+	Up() ([]database.Migration, error)
+	Down(steps int) ([]database.Migration, error)
+	To(version int64) ([]database.Migration, error)
+}
+
+// runMigrate выполняет команду migrate: up применяет все новые миграции, down [N] откатывает N последних
+// (по умолчанию одну), status выводит состояние миграций, to ВЕРСИЯ приводит схему к указанной версии.
+func runMigrate(migrator schemaMigrator, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Использование: music-info migrate up | down [N] | status | to ВЕРСИЯ")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("укажите действие migrate")
+	}
+
+	action, rest := flags.Arg(0), flags.Args()[1:]
+	var done []database.Migration
+	var err error
+	switch {
+	case action == "status" && len(rest) == 0:
+		return printMigrationStatus(migrator, stdout)
+	case action == "up" && len(rest) == 0:
+		done, err = migrator.Up()
+	case action == "down" && len(rest) <= 1:
+		steps := 1
+		if len(rest) == 1 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil || steps < 1 {
+				return fmt.Errorf("неверное количество откатываемых миграций '%s'", rest[0])
+			}
+		}
+		done, err = migrator.Down(steps)
+	case action == "to" && len(rest) == 1:
+		version, parseErr := strconv.ParseInt(rest[0], 10, 64)
+		if parseErr != nil || version < 0 {
+			return fmt.Errorf("неверная версия миграции '%s'", rest[0])
+		}
+		done, err = migrator.To(version)
+	default:
+		flags.Usage()
+		return fmt.Errorf("неизвестное действие migrate '%s'", action)
+	}
+
+	// Выполненные миграции выводятся и при ошибке, чтобы было видно, на какой версии остановилась схема
+	for _, migration := range done {
+		fmt.Fprintf(stdout, "%04d_%s\n", migration.Version, migration.Name)
+	}
+	if err == nil && len(done) == 0 {
+		fmt.Fprintln(stdout, "Схема не изменилась")
+	}
+	return err
+}
+
+// printMigrationStatus выводит таблицу миграций с отметкой времени применения.
+func printMigrationStatus(migrator schemaMigrator, stdout io.Writer) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ВЕРСИЯ\tНАЗВАНИЕ\tПРИМЕНЕНА")
+	for _, status := range statuses {
+		applied := "нет"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		if status.Missing {
+			applied += " (нет скрипта)"
+		}
+		fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return writer.Flush()
+}
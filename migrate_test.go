@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"music-info/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMigrator мигратор в памяти: применяет миграции, только меняя текущую версию.
+type fakeMigrator struct {
+	migrations []database.Migration
+	current    int
+}
+
+func (m *fakeMigrator) Status() ([]database.MigrationStatus, error) {
+	appliedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	statuses := make([]database.MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = database.MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if i < m.current {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+func (m *fakeMigrator) Up() ([]database.Migration, error) {
+	done := m.migrations[m.current:]
+	m.current = len(m.migrations)
+	return done, nil
+}
+
+func (m *fakeMigrator) Down(steps int) ([]database.Migration, error) {
+	var done []database.Migration
+	for ; steps > 0 && m.current > 0; steps-- {
+		m.current--
+		done = append(done, m.migrations[m.current])
+	}
+	return done, nil
+}
+
+func (m *fakeMigrator) To(version int64) ([]database.Migration, error) {
+	var done []database.Migration
+	for m.current > 0 && m.migrations[m.current-1].Version > version {
+		m.current--
+		done = append(done, m.migrations[m.current])
+	}
+	for m.current < len(m.migrations) && m.migrations[m.current].Version <= version {
+		done = append(done, m.migrations[m.current])
+		m.current++
+	}
+	return done, nil
+}
+
+func TestRunMigrate(t *testing.T) {
+
+	// Создаем тестовые данные
+	migrator := &fakeMigrator{migrations: []database.Migration{
+		{Version: 1, Name: "baseline"},
+		{Version: 2, Name: "add_index"},
+		{Version: 3, Name: "drop_legacy"},
+	}}
+
+	// Проверяем метод
+	var out bytes.Buffer
+	assert.NoError(t, runMigrate(migrator, []string{"up"}, &out))
+	assert.Equal(t, "0001_baseline\n0002_add_index\n0003_drop_legacy\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runMigrate(migrator, []string{"up"}, &out))
+	assert.Equal(t, "Схема не изменилась\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runMigrate(migrator, []string{"down"}, &out))
+	assert.Equal(t, "0003_drop_legacy\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runMigrate(migrator, []string{"down", "2"}, &out))
+	assert.Equal(t, "0002_add_index\n0001_baseline\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runMigrate(migrator, []string{"to", "2"}, &out))
+	assert.Equal(t, "0001_baseline\n0002_add_index\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runMigrate(migrator, []string{"status"}, &out))
+	assert.Equal(t, "ВЕРСИЯ  НАЗВАНИЕ     ПРИМЕНЕНА\n"+
+		"0001    baseline     2024-05-01T12:00:00Z\n"+
+		"0002    add_index    2024-05-01T12:00:00Z\n"+
+		"0003    drop_legacy  нет\n", out.String())
+
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "0"}, {"down", "x"}, {"to"}, {"to", "-1"}, {"up", "1"}} {
+		assert.Error(t, runMigrate(migrator, args, &out), args)
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AlbumType тип релиза.
+type AlbumType string
+
+const (
+	AlbumTypeLP          AlbumType = "LP"
+	AlbumTypeEP          AlbumType = "EP"
+	AlbumTypeSingle      AlbumType = "single"
+	AlbumTypeCompilation AlbumType = "compilation"
+)
+
+// albumTypes допустимые типы релиза.
+var albumTypes = map[AlbumType]bool{
+	AlbumTypeLP:          true,
+	AlbumTypeEP:          true,
+	AlbumTypeSingle:      true,
+	AlbumTypeCompilation: true,
+}
+
+// Album альбом или другой релиз исполнителя.
+// @Description Релиз исполнителя с типом, датой выпуска, обложкой и списком треков в порядке дисков и номеров.
+type Album struct {
+	gorm.Model
+	Title       string      `json:"title" gorm:"not null" example:"Black Holes and Revelations"`
+	ArtistID    uint        `json:"artistId" gorm:"not null;index" example:"1"`
+	Artist      *Artist     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" swaggerignore:"true"`
+	ReleaseDate ReleaseDate `json:"releaseDate" gorm:"embedded;embeddedPrefix:release_" swaggertype:"string" example:"03.07.2006"`
+	Type        AlbumType   `json:"type" gorm:"type:varchar(11);not null" enums:"LP,EP,single,compilation" example:"LP"`
+	CoverURL    string      `json:"coverUrl" example:"https://example.com/covers/bhar.jpg"`
+	// Tracks треки релиза в порядке дисков и номеров.
+	Tracks []AlbumTrack `json:"tracks" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// AlbumTrack трек релиза: песня на указанной позиции.
+// Позиция трека — пара номера диска и номера трека на диске, песня входит в релиз не более одного раза.
+// @Description Песня релиза с номером диска и номером трека на диске.
+type AlbumTrack struct {
+	AlbumID uint `json:"-" gorm:"primaryKey;autoIncrement:false;uniqueIndex:idx_album_tracks_album_song,priority:1"`
+	Disc    int  `json:"disc" gorm:"primaryKey;autoIncrement:false" example:"1"`
+	Track   int  `json:"track" gorm:"primaryKey;autoIncrement:false" example:"1"`
+	SongID  uint `json:"songId" gorm:"not null;index;uniqueIndex:idx_album_tracks_album_song,priority:2" example:"1"`
+	// Song песня трека, заполняется при получении релиза.
+	Song *MusicInfo `json:"song,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" readonly:"true"`
+}
+
+// Normalize убирает пробелы по краям названия и адреса обложки и нумерует треки.
+func (a *Album) Normalize() {
+	a.Title = strings.TrimSpace(a.Title)
+	a.CoverURL = strings.TrimSpace(a.CoverURL)
+	a.Tracks = NumberTracks(a.Tracks)
+}
+
+// Validate проверяет заполнение полей релиза и его треков
+func (a *Album) Validate() error {
+	if a.Title == "" {
+		return errors.New("поле 'Title' обязательно для заполнения")
+	}
+	if a.ArtistID == 0 {
+		return errors.New("поле 'ArtistID' обязательно для заполнения")
+	}
+	if !albumTypes[a.Type] {
+		return fmt.Errorf("поле 'Type' должно содержать одно из значений: %s, %s, %s, %s",
+			AlbumTypeLP, AlbumTypeEP, AlbumTypeSingle, AlbumTypeCompilation)
+	}
+	if a.CoverURL != "" {
+		cover, err := url.Parse(a.CoverURL)
+		if err != nil || (cover.Scheme != "http" && cover.Scheme != "https") || cover.Host == "" {
+			return errors.New("поле 'CoverURL' должно содержать адрес http или https")
+		}
+	}
+	return ValidateTracks(a.Tracks)
+}
+
+// NumberTracks заполняет незаданные позиции треков. Трек без номера диска относится к первому диску,
+// трек без номера получает номер, следующий за номером предыдущего трека того же диска.
+// Переданные клиентом песни не сохраняются. Пустой список возвращается как [], а не nil.
+func NumberTracks(tracks []AlbumTrack) []AlbumTrack {
+	result := make([]AlbumTrack, 0, len(tracks))
+	last := make(map[int]int)
+	for _, track := range tracks {
+		if track.Disc == 0 {
+			track.Disc = 1
+		}
+		if track.Track == 0 {
+			track.Track = last[track.Disc] + 1
+		}
+		last[track.Disc] = track.Track
+		track.Song = nil
+		result = append(result, track)
+	}
+	return result
+}
+
+// ValidateTracks проверяет, что у треков заданы песни и положительные номера,
+// а позиции и песни не повторяются.
+func ValidateTracks(tracks []AlbumTrack) error {
+	positions := make(map[[2]int]bool)
+	songs := make(map[uint]bool)
+	for i, track := range tracks {
+		if track.SongID == 0 {
+			return fmt.Errorf("трек %d: поле 'SongID' обязательно для заполнения", i+1)
+		}
+		if track.Disc < 1 || track.Track < 1 {
+			return fmt.Errorf("трек %d: номера диска и трека должны быть положительными", i+1)
+		}
+		position := [2]int{track.Disc, track.Track}
+		if positions[position] {
+			return fmt.Errorf("трек %d: позиция диск %d, трек %d уже занята", i+1, track.Disc, track.Track)
+		}
+		if songs[track.SongID] {
+			return fmt.Errorf("трек %d: песня id=%d уже есть в релизе", i+1, track.SongID)
+		}
+		positions[position] = true
+		songs[track.SongID] = true
+	}
+	return nil
+}
+
+// SortTracks упорядочивает треки по номеру диска и номеру трека.
+func SortTracks(tracks []AlbumTrack) {
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].Disc != tracks[j].Disc {
+			return tracks[i].Disc < tracks[j].Disc
+		}
+		return tracks[i].Track < tracks[j].Track
+	})
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumValidation(t *testing.T) {
+
+	// Создаем тестовые данные
+	album := Album{
+		Title:    " Black Holes and Revelations ",
+		ArtistID: 1,
+		Type:     AlbumTypeLP,
+		CoverURL: "https://example.com/bhar.jpg",
+		Tracks:   []AlbumTrack{{SongID: 3}, {SongID: 1}, {SongID: 2, Disc: 2}, {SongID: 4, Track: 5}},
+	}
+	album.Normalize()
+
+	// Проверяем метод
+	assert.Equal(t, "Black Holes and Revelations", album.Title)
+	assert.Equal(t, []AlbumTrack{
+		{SongID: 3, Disc: 1, Track: 1},
+		{SongID: 1, Disc: 1, Track: 2},
+		{SongID: 2, Disc: 2, Track: 1},
+		{SongID: 4, Disc: 1, Track: 5},
+	}, album.Tracks)
+	assert.NoError(t, album.Validate())
+
+	invalid := album
+	invalid.Type = "album"
+	assert.ErrorContains(t, invalid.Validate(), "Type")
+
+	invalid = album
+	invalid.CoverURL = "ftp://example.com/bhar.jpg"
+	assert.ErrorContains(t, invalid.Validate(), "CoverURL")
+
+	invalid = album
+	invalid.ArtistID = 0
+	assert.ErrorContains(t, invalid.Validate(), "ArtistID")
+
+	assert.ErrorContains(t, ValidateTracks([]AlbumTrack{{SongID: 1, Disc: 1, Track: 1}, {SongID: 2, Disc: 1, Track: 1}}), "уже занята")
+	assert.ErrorContains(t, ValidateTracks([]AlbumTrack{{SongID: 1, Disc: 1, Track: 1}, {SongID: 1, Disc: 1, Track: 2}}), "уже есть в релизе")
+	assert.ErrorContains(t, ValidateTracks([]AlbumTrack{{SongID: 1, Disc: 1, Track: -1}}), "положительными")
+	assert.Equal(t, []AlbumTrack{}, NumberTracks(nil))
+
+	SortTracks(album.Tracks)
+	assert.Equal(t, []uint{3, 1, 4, 2}, []uint{album.Tracks[0].SongID, album.Tracks[1].SongID, album.Tracks[2].SongID, album.Tracks[3].SongID})
+}
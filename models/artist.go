@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Artist исполнитель: группа или сольный артист.
+// @Description Исполнитель с псевдонимами, страной, годом основания и составом. Песни ссылаются на исполнителя через artistId.
+type Artist struct {
+	gorm.Model
+	Name string `json:"name" gorm:"not null;uniqueIndex:idx_artists_name,expression:lower(btrim(name)),where:deleted_at IS NULL" example:"Muse"`
+	// Aliases другие написания имени. Песни с группой, совпадающей с псевдонимом, относятся к этому исполнителю.
+	Aliases []string `json:"aliases" gorm:"serializer:json;type:jsonb" example:"Muse UK"`
+	// Country код страны ISO 3166-1 alpha-2.
+	Country    string   `json:"country" example:"GB"`
+	FormedYear int      `json:"formedYear" example:"1994"`
+	Members    []string `json:"members" gorm:"serializer:json;type:jsonb" example:"Matthew Bellamy"`
+	// ChangedBy автор изменения, записываемый в историю правок песен при переименовании исполнителя. В таблице не хранится.
+	ChangedBy string `json:"-" gorm:"-" swaggerignore:"true"`
+}
+
+// countryPattern формат кода страны ISO 3166-1 alpha-2.
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Normalize убирает пробелы по краям, пустые псевдонимы и участников и приводит код страны к верхнему регистру.
+// Пустые списки сохраняются как [], а не null.
+func (a *Artist) Normalize() {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Aliases = normalizeList(a.Aliases)
+	a.Members = normalizeList(a.Members)
+}
+
+// normalizeList убирает пробелы по краям и пустые строки.
+func normalizeList(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// Validate проверяет заполнение полей исполнителя
+func (a *Artist) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return errors.New("поле 'Name' обязательно для заполнения")
+	}
+	if a.Country != "" && !countryPattern.MatchString(a.Country) {
+		return errors.New("поле 'Country' должно содержать код страны из двух латинских букв")
+	}
+	if a.FormedYear != 0 && (a.FormedYear < 1000 || a.FormedYear > time.Now().Year()) {
+		return errors.New("поле 'FormedYear' должно содержать год не позже текущего")
+	}
+	return nil
+}
+
+// HasName проверяет, что имя или один из псевдонимов исполнителя совпадает с названием группы
+// без учета регистра и пробелов по краям.
+func (a *Artist) HasName(group string) bool {
+	if NormalizeKey(a.Name) == NormalizeKey(group) {
+		return true
+	}
+	for _, alias := range a.Aliases {
+		if NormalizeKey(alias) == NormalizeKey(group) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtistValidation(t *testing.T) {
+
+	// Создаем тестовые данные
+	artist := Artist{Name: " Muse ", Aliases: []string{" Muse UK", ""}, Country: "gb", FormedYear: 1994}
+	artist.Normalize()
+
+	// Проверяем метод
+	assert.Equal(t, "Muse", artist.Name)
+	assert.Equal(t, []string{"Muse UK"}, artist.Aliases)
+	assert.Equal(t, []string{}, artist.Members)
+	assert.Equal(t, "GB", artist.Country)
+	assert.NoError(t, artist.Validate())
+
+	assert.True(t, artist.HasName("muse uk "))
+	assert.False(t, artist.HasName("Queen"))
+
+	invalid := artist
+	invalid.Name = " "
+	assert.ErrorContains(t, invalid.Validate(), "поле 'Name' обязательно для заполнения")
+
+	invalid = artist
+	invalid.Country = "GBR"
+	assert.ErrorContains(t, invalid.Validate(), "Country")
+
+	invalid = artist
+	invalid.FormedYear = 3000
+	assert.ErrorContains(t, invalid.Validate(), "FormedYear")
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DatePrecision точность даты выпуска.
+type DatePrecision string
+
+const (
+	PrecisionYear  DatePrecision = "year"
+	PrecisionMonth DatePrecision = "month"
+	PrecisionDay   DatePrecision = "day"
+)
+
+// DateFormat формат вывода даты выпуска.
+type DateFormat string
+
+const (
+	// DateFormatRU формат DD.MM.YYYY, MM.YYYY и YYYY.
+	DateFormatRU DateFormat = "ru"
+	// DateFormatISO формат ISO-8601: YYYY-MM-DD, YYYY-MM и YYYY.
+	DateFormatISO DateFormat = "iso"
+)
+
+// dateLayouts шаблоны вывода даты для каждого формата и точности.
+var dateLayouts = map[DateFormat]map[DatePrecision]string{
+	DateFormatRU:  {PrecisionDay: "02.01.2006", PrecisionMonth: "01.2006", PrecisionYear: "2006"},
+	DateFormatISO: {PrecisionDay: "2006-01-02", PrecisionMonth: "2006-01", PrecisionYear: "2006"},
+}
+
+// releaseDateFormat формат, в котором даты выпуска передаются клиенту.
+var releaseDateFormat = DateFormatRU
+
+// SetReleaseDateFormat задает формат, в котором даты выпуска передаются клиенту.
+func SetReleaseDateFormat(format string) error {
+	if _, ok := dateLayouts[DateFormat(format)]; !ok {
+		return fmt.Errorf("неизвестный формат даты: %s", format)
+	}
+	releaseDateFormat = DateFormat(format)
+	return nil
+}
+
+// releaseDateInputs поддерживаемые форматы ввода даты выпуска.
+var releaseDateInputs = []struct {
+	pattern   *regexp.Regexp
+	layout    string
+	precision DatePrecision
+}{
+	{regexp.MustCompile(`^\d{2}\.\d{2}\.\d{4}$`), "02.01.2006", PrecisionDay},
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`), "2006-01-02", PrecisionDay},
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`), time.RFC3339, PrecisionDay},
+	{regexp.MustCompile(`^\d{2}\.\d{4}$`), "01.2006", PrecisionMonth},
+	{regexp.MustCompile(`^\d{4}-\d{2}$`), "2006-01", PrecisionMonth},
+	{regexp.MustCompile(`^\d{4}$`), "2006", PrecisionYear},
+}
+
+// ReleaseDate дата выпуска песни с указанием точности.
+// Хранится в столбцах release_date (тип date) и release_precision.
+// Для дат с точностью до года или месяца в Date хранится первый день периода.
+type ReleaseDate struct {
+	Date      *time.Time    `gorm:"column:date;type:date"`
+	Precision DatePrecision `gorm:"column:precision;type:varchar(5)"`
+}
+
+// ParseReleaseDate разбирает дату выпуска в форматах DD.MM.YYYY, MM.YYYY, YYYY
+// и ISO-8601 (YYYY-MM-DD, YYYY-MM-DDThh:mm:ssZ, YYYY-MM). Пустая строка означает отсутствие даты.
+func ParseReleaseDate(value string) (ReleaseDate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ReleaseDate{}, nil
+	}
+
+	for _, input := range releaseDateInputs {
+		if !input.pattern.MatchString(value) {
+			continue
+		}
+		parsed, err := time.Parse(input.layout, value)
+		if err != nil {
+			return ReleaseDate{}, fmt.Errorf("неверная дата выпуска '%s': %v", value, err)
+		}
+		date := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
+		return ReleaseDate{Date: &date, Precision: input.precision}, nil
+	}
+
+	return ReleaseDate{}, fmt.Errorf("неверная дата выпуска '%s': ожидается DD.MM.YYYY, MM.YYYY, YYYY или ISO-8601", value)
+}
+
+// MustParseReleaseDate разбирает дату выпуска и паникует при ошибке.
+func MustParseReleaseDate(value string) ReleaseDate {
+	date, err := ParseReleaseDate(value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// IsZero проверяет, что дата выпуска не указана.
+func (d ReleaseDate) IsZero() bool {
+	return d.Date == nil
+}
+
+// Equal проверяет совпадение дат выпуска с учетом точности.
+func (d ReleaseDate) Equal(other ReleaseDate) bool {
+	if d.IsZero() || other.IsZero() {
+		return d.IsZero() == other.IsZero()
+	}
+	return d.Date.Equal(*other.Date) && d.Precision == other.Precision
+}
+
+// Before сравнивает даты выпуска. Отсутствующая дата считается более поздней, как NULL при сортировке в PostgreSQL.
+func (d ReleaseDate) Before(other ReleaseDate) bool {
+	if d.IsZero() {
+		return false
+	}
+	if other.IsZero() {
+		return true
+	}
+	return d.Date.Before(*other.Date)
+}
+
+// PeriodEnd возвращает последний день периода, обозначенного датой: года, месяца или самого дня.
+func (d ReleaseDate) PeriodEnd() time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Date.AddDate(1, 0, -1)
+	case PrecisionMonth:
+		return d.Date.AddDate(0, 1, -1)
+	}
+	return *d.Date
+}
+
+// Format возвращает дату выпуска в указанном формате с учетом точности.
+func (d ReleaseDate) Format(format DateFormat) string {
+	if d.IsZero() {
+		return ""
+	}
+	precision := d.Precision
+	if precision == "" {
+		precision = PrecisionDay
+	}
+	return d.Date.Format(dateLayouts[format][precision])
+}
+
+// String возвращает дату выпуска в формате, заданном SetReleaseDateFormat.
+func (d ReleaseDate) String() string {
+	return d.Format(releaseDateFormat)
+}
+
+// MarshalJSON передает дату выпуска строкой в формате, заданном SetReleaseDateFormat.
+func (d ReleaseDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON принимает дату выпуска строкой в любом из поддерживаемых форматов или null.
+func (d *ReleaseDate) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("дата выпуска должна быть строкой: %v", err)
+	}
+	if value == nil {
+		*d = ReleaseDate{}
+		return nil
+	}
+
+	parsed, err := ParseReleaseDate(*value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// LegacyReleaseDate строка даты выпуска, которую не удалось разобрать при переводе столбца release_date в тип date.
+// Значение выводится в JSON, но не принимается от клиента: оно появляется только при миграции
+// и очищается хранилищем при записи непустой даты выпуска.
+type LegacyReleaseDate string
+
+// UnmarshalJSON пропускает значение из запроса: поле доступно только для чтения.
+func (d *LegacyReleaseDate) UnmarshalJSON(data []byte) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReleaseDate(t *testing.T) {
+	// Создаем тестовые данные
+	tests := []struct {
+		input     string
+		precision DatePrecision
+		expected  string
+	}{
+		{"16.07.2006", PrecisionDay, "2006-07-16"},
+		{"2006-07-16", PrecisionDay, "2006-07-16"},
+		{"2006-07-16T10:00:00Z", PrecisionDay, "2006-07-16"},
+		{"07.2006", PrecisionMonth, "2006-07"},
+		{"2006-07", PrecisionMonth, "2006-07"},
+		{"2006", PrecisionYear, "2006"},
+	}
+
+	// Проверяем метод
+	for _, test := range tests {
+		date, err := ParseReleaseDate(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.precision, date.Precision, test.input)
+		assert.Equal(t, test.expected, date.Format(DateFormatISO), test.input)
+	}
+
+	date, err := ParseReleaseDate("")
+	assert.NoError(t, err)
+	assert.True(t, date.IsZero())
+}
+
+func TestParseReleaseDateInvalid(t *testing.T) {
+	// Проверяем метод
+	for _, input := range []string{"вчера", "31.02.2006", "2006-13", "16/07/2006"} {
+		_, err := ParseReleaseDate(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestReleaseDateJSON(t *testing.T) {
+	// Создаем тестовые данные
+	songInfo := MusicInfo{Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: MustParseReleaseDate("2006-07-16")}
+
+	// Проверяем метод
+	data, err := json.Marshal(songInfo)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"releaseDate":"16.07.2006"`)
+
+	var decoded MusicInfo
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, songInfo.ReleaseDate.Equal(decoded.ReleaseDate))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"releaseDate":null}`), &decoded))
+	assert.True(t, decoded.ReleaseDate.IsZero())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"releaseDate":"вчера"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"releaseDate":2006}`), &decoded))
+
+	data, err = json.Marshal(MusicInfo{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"releaseDate":""`)
+}
+
+func TestLegacyReleaseDateJSON(t *testing.T) {
+	// Создаем тестовые данные
+	songInfo := MusicInfo{Group: "Muse", Song: "Uprising", ReleaseDateLegacy: "осень 2009"}
+
+	// Проверяем метод
+	data, err := json.Marshal(songInfo)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"releaseDateLegacy":"осень 2009"`)
+
+	var decoded MusicInfo
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Empty(t, decoded.ReleaseDateLegacy)
+
+	data, err = json.Marshal(MusicInfo{})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "releaseDateLegacy")
+}
+
+func TestSetReleaseDateFormat(t *testing.T) {
+	// Создаем тестовые данные
+	date := MustParseReleaseDate("07.2006")
+	defer SetReleaseDateFormat(string(DateFormatRU))
+
+	// Проверяем метод
+	assert.Equal(t, "07.2006", date.String())
+
+	assert.NoError(t, SetReleaseDateFormat("iso"))
+	assert.Equal(t, "2006-07", date.String())
+
+	assert.Error(t, SetReleaseDateFormat("us"))
+	assert.Equal(t, "2006-07", date.String())
+}
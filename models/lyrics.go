@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// SyncedLyrics текст песни с отметками времени в формате LRC. Хранится отдельно от текста песни
+// в разобранном и заново записанном виде.
+type SyncedLyrics struct {
+	SongID    uint       `json:"songId" gorm:"primaryKey;autoIncrement:false"`
+	Song      *MusicInfo `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	LRC       string     `json:"lrc" gorm:"column:lrc;not null"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// LyricsLine строка синхронизированного текста.
+// @Description Строка текста с номером, начиная с 1, и временем начала в воспроизведении с учетом смещения.
+type LyricsLine struct {
+	Number int    `json:"number" example:"12"`
+	Time   string `json:"time" example:"01:23.50"`
+	TimeMs int64  `json:"timeMs" example:"83500"`
+	Text   string `json:"text" example:"You set my soul alight"`
+}
+
+// ActiveLyricsLine строка синхронизированного текста, звучащая в момент воспроизведения.
+// @Description Текущая строка, пустая до начала первой строки, и следующая строка, пустая после начала последней.
+type ActiveLyricsLine struct {
+	SongID uint        `json:"songId" example:"1"`
+	AtMs   int64       `json:"atMs" example:"84000"`
+	Line   *LyricsLine `json:"line"`
+	Next   *LyricsLine `json:"next"`
+}
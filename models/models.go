@@ -11,23 +11,112 @@ import (
 // @Description Информация о песне, включая группу, название песни, дату создания, текст, ссылку.
 type MusicInfo struct {
 	gorm.Model
-	Group       string `json:"group" gorm:"not null"`
-	Song        string `json:"song" gorm:"not null"`
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text" gorm:"not null"`
-	Link        string `json:"link"`
+	Group       string      `json:"group" gorm:"not null;uniqueIndex:idx_music_infos_group_song,expression:lower(btrim(\"group\")),where:deleted_at IS NULL;index:idx_music_infos_group_song_order,priority:1"`
+	Song        string      `json:"song" gorm:"not null;uniqueIndex:idx_music_infos_group_song,expression:lower(btrim(song)),where:deleted_at IS NULL;index:idx_music_infos_group_song_order,priority:2"`
+	ReleaseDate ReleaseDate `json:"releaseDate" gorm:"embedded;embeddedPrefix:release_" swaggertype:"string" example:"16.07.2006"`
+	Text        string      `json:"text" gorm:"not null"`
+	Link        string      `json:"link"`
+	// ReleaseDateLegacy нераспознанная дата выпуска из строкового столбца прежних версий.
+	ReleaseDateLegacy LegacyReleaseDate `json:"releaseDateLegacy,omitempty" gorm:"column:release_date_legacy;->" swaggertype:"string" readonly:"true" example:"осень 2006"`
+	// ArtistID исполнитель, определяемый по полю Group при сохранении песни.
+	ArtistID *uint   `json:"artistId,omitempty" gorm:"index" readonly:"true" example:"1"`
+	Artist   *Artist `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" swaggerignore:"true"`
+	// Tags теги песни, хранятся в таблице song_tags и возвращаются отдельным запросом.
+	Tags []Tag `json:"-" gorm:"many2many:song_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" swaggerignore:"true"`
+	// ChangedBy автор изменения, записываемый в историю правок. В таблице песен не хранится.
+	ChangedBy string `json:"-" gorm:"-" swaggerignore:"true"`
 }
 
-// Validate проверяет заполнение полей
-func (m *MusicInfo) Validate() error {
+// NormalizeKey приводит название группы или песни к виду, в котором проверяется уникальность:
+// без пробелов по краям и в нижнем регистре. Соответствует выражению lower(btrim(...)) уникального индекса.
+func NormalizeKey(s string) string {
+	return strings.ToLower(strings.Trim(s, " "))
+}
+
+// SameKey проверяет, что песня совпадает с указанной парой группы и названия без учета регистра и пробелов по краям
+func (m *MusicInfo) SameKey(group, song string) bool {
+	return NormalizeKey(m.Group) == NormalizeKey(group) && NormalizeKey(m.Song) == NormalizeKey(song)
+}
+
+// ValidateKey проверяет заполнение полей, по которым определяется песня
+func (m *MusicInfo) ValidateKey() error {
 	if strings.TrimSpace(m.Group) == "" {
 		return errors.New("поле 'Group' обязательно для заполнения")
 	}
 	if strings.TrimSpace(m.Song) == "" {
 		return errors.New("поле 'Song' обязательно для заполнения")
 	}
+	return nil
+}
+
+// Validate проверяет заполнение полей
+func (m *MusicInfo) Validate() error {
+	if err := m.ValidateKey(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(m.Text) == "" {
 		return errors.New("поле 'Text' обязательно для заполнения")
 	}
 	return nil
 }
+
+// NeedsEnrichment проверяет, что часть полей не заполнена и может быть получена из внешнего сервиса
+func (m *MusicInfo) NeedsEnrichment() bool {
+	return m.ReleaseDate.IsZero() || m.Text == "" || m.Link == ""
+}
+
+// Verse куплет песни.
+// @Description Куплет песни с порядковым номером, начиная с 1.
+type Verse struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+// SongText страница куплетов песни.
+// @Description Страница куплетов песни и общее количество куплетов.
+type SongText struct {
+	SongID uint    `json:"songId"`
+	Group  string  `json:"group"`
+	Song   string  `json:"song"`
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+	Total  int     `json:"total"`
+	Verses []Verse `json:"verses"`
+}
+
+// SearchResult песня, найденная полнотекстовым поиском.
+// @Description Найденная песня с релевантностью, фрагментами текста с выделенными совпадениями и номером куплета.
+type SearchResult struct {
+	ID    uint    `json:"id" example:"1"`
+	Group string  `json:"group" example:"Muse"`
+	Song  string  `json:"song" example:"Supermassive Black Hole"`
+	Rank  float64 `json:"rank" example:"0.6079"`
+	// Snippet фрагменты текста с экранированным HTML, совпадения выделены тегами <b> и </b>.
+	Snippet string `json:"snippet" example:"You set my <b>soul</b> alight"`
+	// Verse номер первого куплета с совпадением, 0 если совпали только группа или название.
+	Verse int `json:"verse" example:"2"`
+}
+
+// Verses разбивает текст песни на куплеты по пустым строкам.
+func (m *MusicInfo) Verses() []Verse {
+	var verses []Verse
+	var lines []string
+
+	flush := func() {
+		if len(lines) > 0 {
+			verses = append(verses, Verse{Number: len(verses) + 1, Text: strings.Join(lines, "\n")})
+			lines = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(m.Text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	flush()
+
+	return verses
+}
@@ -10,7 +10,7 @@ func TestMessageValidation(t *testing.T) {
 	TestInfo := MusicInfo{
 		Group:       "Muse",
 		Song:        "Supermassive Black Hole",
-		ReleaseDate: "16.07.2006",
+		ReleaseDate: MustParseReleaseDate("16.07.2006"),
 		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
 	}
@@ -39,3 +39,26 @@ func TestMessageValidation(t *testing.T) {
 	err = musicInfo.Validate()
 	assert.NoError(t, err)
 }
+
+func TestMusicInfoVerses(t *testing.T) {
+	musicInfo := MusicInfo{
+		Text: "Ooh baby, don't you know I suffer?\r\nOoh baby, can you hear me moan?\n\n\nOoh\nYou set my soul alight\n  \nOoh\nYou set my soul alight\n",
+	}
+
+	verses := musicInfo.Verses()
+	assert.Equal(t, 3, len(verses))
+	assert.Equal(t, Verse{Number: 1, Text: "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"}, verses[0])
+	assert.Equal(t, Verse{Number: 3, Text: "Ooh\nYou set my soul alight"}, verses[2])
+
+	// Проверка на пустой текст
+	empty := MusicInfo{}
+	assert.Empty(t, empty.Verses())
+}
+
+func TestMusicInfoSameKey(t *testing.T) {
+	musicInfo := MusicInfo{Group: "Muse", Song: "Supermassive Black Hole"}
+
+	assert.Equal(t, "supermassive black hole", NormalizeKey("  Supermassive Black Hole "))
+	assert.True(t, musicInfo.SameKey(" MUSE", "supermassive black hole "))
+	assert.False(t, musicInfo.SameKey("Muse", "Uprising"))
+}
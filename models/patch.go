@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// patchableFields поля песни, которые можно изменить документом JSON Merge Patch.
+var patchableFields = []string{"group", "song", "releaseDate", "text", "link"}
+
+// ApplyMergePatch применяет к песне документ JSON Merge Patch (RFC 7396).
+// Отсутствующие в документе поля не изменяются, поля со значением null или "" очищаются.
+// Проверка результата выполняется отдельно через Validate.
+func (m *MusicInfo) ApplyMergePatch(patch []byte) error {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return fmt.Errorf("неверный формат JSON: %v", err)
+	}
+
+	patchObj, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("документ merge patch должен быть JSON-объектом")
+	}
+
+	target := map[string]interface{}{
+		"group":       m.Group,
+		"song":        m.Song,
+		"releaseDate": m.ReleaseDate.String(),
+		"text":        m.Text,
+		"link":        m.Link,
+	}
+	for key := range patchObj {
+		if _, ok := target[key]; !ok {
+			return fmt.Errorf("поле '%s' нельзя изменить", key)
+		}
+	}
+
+	merged := mergePatch(target, patchObj).(map[string]interface{})
+
+	values := make(map[string]string, len(patchableFields))
+	for _, key := range patchableFields {
+		switch value := merged[key].(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = value
+		default:
+			return fmt.Errorf("поле '%s' должно быть строкой или null", key)
+		}
+	}
+
+	releaseDate, err := ParseReleaseDate(values["releaseDate"])
+	if err != nil {
+		return err
+	}
+
+	m.Group = values["group"]
+	m.Song = values["song"]
+	m.ReleaseDate = releaseDate
+	m.Text = values["text"]
+	m.Link = values["link"]
+
+	return nil
+}
+
+// mergePatch реализует алгоритм MergePatch из RFC 7396.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+
+	return targetObj
+}
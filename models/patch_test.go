@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	TestInfo := MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, don't you know I suffer?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+
+	// Проверка изменения и очистки полей
+	musicInfo := TestInfo
+	err := musicInfo.ApplyMergePatch([]byte(`{"song":"Uprising","link":null,"releaseDate":""}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", musicInfo.Group)
+	assert.Equal(t, "Uprising", musicInfo.Song)
+	assert.True(t, musicInfo.ReleaseDate.IsZero())
+	assert.Equal(t, TestInfo.Text, musicInfo.Text)
+	assert.Equal(t, "", musicInfo.Link)
+
+	// Проверка пустого документа
+	musicInfo = TestInfo
+	err = musicInfo.ApplyMergePatch([]byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, TestInfo, musicInfo)
+
+	// Проверка ошибок
+	musicInfo = TestInfo
+	assert.Error(t, musicInfo.ApplyMergePatch([]byte(`{"song":`)))
+	assert.Error(t, musicInfo.ApplyMergePatch([]byte(`["song"]`)))
+	assert.Error(t, musicInfo.ApplyMergePatch([]byte(`{"ID":5}`)))
+	assert.Error(t, musicInfo.ApplyMergePatch([]byte(`{"text":{"verse":1}}`)))
+	assert.Equal(t, TestInfo, musicInfo)
+}
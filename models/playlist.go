@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Playlist плейлист слушателя.
+// @Description Плейлист с упорядоченными записями. Одна песня может входить в плейлист несколько раз.
+type Playlist struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"not null" example:"Вечер"`
+	Description string `json:"description" example:"Спокойная музыка на вечер"`
+	// SongIDs песни, которыми заполняется плейлист при создании.
+	SongIDs []uint `json:"songIds,omitempty" gorm:"-" example:"1"`
+	// Entries записи плейлиста в порядке позиций.
+	Entries []PlaylistEntry `json:"entries" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" readonly:"true"`
+}
+
+// PlaylistEntry запись плейлиста.
+// Группа и название песни сохраняются при добавлении, чтобы запись удаленной песни оставалась понятной.
+// @Description Запись плейлиста: позиция, начиная с 1, и песня. Если песня удалена, song пуст, а available равно false.
+type PlaylistEntry struct {
+	ID         uint       `json:"id" gorm:"primaryKey" example:"1"`
+	PlaylistID uint       `json:"-" gorm:"not null;index:idx_playlist_entries_position,priority:1"`
+	Position   int        `json:"position" gorm:"not null;index:idx_playlist_entries_position,priority:2" example:"1"`
+	SongID     *uint      `json:"songId" gorm:"index" example:"1"`
+	Song       *MusicInfo `json:"song" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Group      string     `json:"group" example:"Muse"`
+	SongName   string     `json:"songName" gorm:"column:song_name" example:"Uprising"`
+	AddedAt    time.Time  `json:"addedAt" gorm:"autoCreateTime"`
+	// Available признак того, что песня записи не удалена.
+	Available bool `json:"available" gorm:"-"`
+}
+
+// Normalize убирает пробелы по краям названия и описания.
+func (p *Playlist) Normalize() {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Description = strings.TrimSpace(p.Description)
+}
+
+// Validate проверяет заполнение полей плейлиста
+func (p *Playlist) Validate() error {
+	if p.Name == "" {
+		return errors.New("поле 'Name' обязательно для заполнения")
+	}
+	if len([]rune(p.Name)) > 200 {
+		return errors.New("поле 'Name' должно содержать не более 200 символов")
+	}
+	for _, id := range p.SongIDs {
+		if id == 0 {
+			return errors.New("поле 'SongIDs' должно содержать идентификаторы песен")
+		}
+	}
+	return nil
+}
+
+// NewPlaylistEntry создает запись плейлиста для песни на указанной позиции.
+func NewPlaylistEntry(songInfo *MusicInfo, position int) PlaylistEntry {
+	id := songInfo.ID
+	return PlaylistEntry{Position: position, SongID: &id, Group: songInfo.Group, SongName: songInfo.Song}
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// SongRevision версия песни в истории правок. Каждое изменение полей песни сохраняет
+// ее новое состояние целиком с номером версии, автором и списком измененных полей.
+// @Description Версия песни: состояние полей после изменения, автор, время и измененные поля.
+type SongRevision struct {
+	ID      uint       `json:"-" gorm:"primaryKey"`
+	SongID  uint       `json:"songId" gorm:"not null;uniqueIndex:idx_song_revisions_song_version,priority:1" example:"1"`
+	Song    *MusicInfo `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" swaggerignore:"true"`
+	Version int        `json:"version" gorm:"not null;uniqueIndex:idx_song_revisions_song_version,priority:2" example:"3"`
+	// Author значение заголовка X-User запроса, изменившего песню.
+	Author    string    `json:"author" gorm:"not null;default:''" example:"editor"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Fields поля, измененные относительно предыдущей версии. У первой версии — все заполненные поля.
+	Fields []string `json:"fields" gorm:"serializer:json;type:jsonb" example:"text"`
+
+	Group       string      `json:"group" gorm:"not null" example:"Muse"`
+	SongName    string      `json:"song" gorm:"column:song_name;not null" example:"Supermassive Black Hole"`
+	ReleaseDate ReleaseDate `json:"releaseDate" gorm:"embedded;embeddedPrefix:release_" swaggertype:"string" example:"16.07.2006"`
+	Text        string      `json:"text" gorm:"not null"`
+	Link        string      `json:"link"`
+}
+
+// NewSongRevision создает версию с текущим состоянием песни. Номер версии и измененные поля заполняются при сохранении.
+func NewSongRevision(songInfo *MusicInfo) SongRevision {
+	return SongRevision{
+		SongID:      songInfo.ID,
+		Author:      songInfo.ChangedBy,
+		Group:       songInfo.Group,
+		SongName:    songInfo.Song,
+		ReleaseDate: songInfo.ReleaseDate,
+		Text:        songInfo.Text,
+		Link:        songInfo.Link,
+	}
+}
+
+// ChangedFields возвращает поля, которыми версия отличается от предыдущей. Без предыдущей версии
+// возвращает заполненные поля.
+func (r *SongRevision) ChangedFields(previous *SongRevision) []string {
+	if previous == nil {
+		previous = &SongRevision{}
+	}
+
+	fields := []string{}
+	if r.Group != previous.Group {
+		fields = append(fields, "group")
+	}
+	if r.SongName != previous.SongName {
+		fields = append(fields, "song")
+	}
+	if !r.ReleaseDate.Equal(previous.ReleaseDate) {
+		fields = append(fields, "releaseDate")
+	}
+	if r.Text != previous.Text {
+		fields = append(fields, "text")
+	}
+	if r.Link != previous.Link {
+		fields = append(fields, "link")
+	}
+	return fields
+}
+
+// MusicInfo возвращает поля песни из версии для восстановления.
+func (r *SongRevision) MusicInfo() *MusicInfo {
+	return &MusicInfo{
+		Group:       r.Group,
+		Song:        r.SongName,
+		ReleaseDate: r.ReleaseDate,
+		Text:        r.Text,
+		Link:        r.Link,
+	}
+}
+
+// RevisionSummary версия песни без значений полей для списка истории правок.
+// @Description Номер версии, автор, время изменения и измененные поля.
+type RevisionSummary struct {
+	Version   int       `json:"version" example:"3"`
+	Author    string    `json:"author" example:"editor"`
+	CreatedAt time.Time `json:"createdAt"`
+	Fields    []string  `json:"fields" gorm:"serializer:json" example:"text"`
+}
+
+// DiffLine строка сравнения текстов двух версий.
+// @Description Строка текста с операцией: equal — строка есть в обеих версиях, delete — только в старой, insert — только в новой.
+// @Description Номера строк начинаются с 1, у добавленной строки нет номера в старой версии, у удаленной — в новой.
+type DiffLine struct {
+	Op        string `json:"op" enums:"equal,delete,insert" example:"insert"`
+	OldNumber int    `json:"oldNumber,omitempty" example:"4"`
+	NewNumber int    `json:"newNumber,omitempty" example:"4"`
+	Text      string `json:"text" example:"You set my soul alight"`
+}
+
+// TextDiff построчное сравнение текстов двух версий песни.
+// @Description Построчное сравнение текста версии from с текстом версии to и количество добавленных и удаленных строк.
+type TextDiff struct {
+	SongID  uint       `json:"songId" example:"1"`
+	From    int        `json:"from" example:"2"`
+	To      int        `json:"to" example:"3"`
+	Added   int        `json:"added" example:"1"`
+	Deleted int        `json:"deleted" example:"1"`
+	Lines   []DiffLine `json:"lines"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongRevisionChangedFields(t *testing.T) {
+
+	// Создаем тестовые данные
+	songInfo := MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia", ChangedBy: "editor"}
+	first := NewSongRevision(&songInfo)
+
+	songInfo.Text = "Paranoia is in bloom"
+	songInfo.ReleaseDate = MustParseReleaseDate("2009")
+	second := NewSongRevision(&songInfo)
+
+	// Проверяем метод
+	assert.Equal(t, "editor", first.Author)
+	assert.Equal(t, []string{"group", "song", "text"}, first.ChangedFields(nil))
+	assert.Equal(t, []string{"releaseDate", "text"}, second.ChangedFields(&first))
+	assert.Empty(t, second.ChangedFields(&second))
+
+	restored := second.MusicInfo()
+	assert.Equal(t, "Uprising", restored.Song)
+	assert.True(t, restored.ReleaseDate.Equal(songInfo.ReleaseDate))
+	assert.Equal(t, songInfo.Text, restored.Text)
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagKind вид тега.
+type TagKind string
+
+const (
+	TagKindGenre TagKind = "genre"
+	TagKindMood  TagKind = "mood"
+	TagKindTag   TagKind = "tag"
+)
+
+// tagKinds допустимые виды тегов.
+var tagKinds = map[TagKind]bool{
+	TagKindGenre: true,
+	TagKindMood:  true,
+	TagKindTag:   true,
+}
+
+// Tag жанр, настроение или произвольный тег песни.
+// Имена тегов одного вида уникальны без учета регистра и пробелов по краям.
+// @Description Тег песни: жанр (genre), настроение (mood) или произвольная метка (tag).
+type Tag struct {
+	ID   uint    `json:"id" gorm:"primaryKey" readonly:"true" example:"1"`
+	Kind TagKind `json:"kind" gorm:"type:varchar(5);not null;uniqueIndex:idx_tags_kind_name,priority:1" enums:"genre,mood,tag" example:"genre"`
+	Name string  `json:"name" gorm:"not null;uniqueIndex:idx_tags_kind_name,expression:lower(btrim(name)),priority:2" example:"Rock"`
+}
+
+// TagFacet тег с количеством песен.
+// @Description Тег и количество песен с ним среди песен, удовлетворяющих фильтру.
+type TagFacet struct {
+	Tag
+	Count int64 `json:"count" example:"120"`
+}
+
+// Normalize убирает пробелы по краям имени. Тег без вида считается произвольным.
+func (t *Tag) Normalize() {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Kind = TagKind(strings.ToLower(strings.TrimSpace(string(t.Kind))))
+	if t.Kind == "" {
+		t.Kind = TagKindTag
+	}
+}
+
+// Validate проверяет заполнение полей тега
+func (t *Tag) Validate() error {
+	if !tagKinds[t.Kind] {
+		return fmt.Errorf("поле 'Kind' должно содержать одно из значений: %s, %s, %s", TagKindGenre, TagKindMood, TagKindTag)
+	}
+	if t.Name == "" {
+		return errors.New("поле 'Name' обязательно для заполнения")
+	}
+	if len([]rune(t.Name)) > 64 {
+		return errors.New("поле 'Name' должно содержать не более 64 символов")
+	}
+	return nil
+}
+
+// Matches проверяет, что тег соответствует ссылке на тег.
+func (t *Tag) Matches(ref TagRef) bool {
+	return (ref.Kind == "" || ref.Kind == t.Kind) && NormalizeKey(t.Name) == NormalizeKey(ref.Name)
+}
+
+// TagRef ссылка на тег в фильтре: имя и, если задан, вид тега.
+type TagRef struct {
+	// Kind вид тега. Пустой вид соответствует тегу с таким именем любого вида.
+	Kind TagKind
+	Name string
+}
+
+// ParseTagRef разбирает ссылку на тег в виде "вид:имя" или "имя".
+// Если часть до двоеточия не является видом тега, вся строка считается именем.
+func ParseTagRef(value string) (TagRef, error) {
+	ref := TagRef{Name: strings.TrimSpace(value)}
+	if kind, name, ok := strings.Cut(value, ":"); ok && tagKinds[TagKind(strings.ToLower(strings.TrimSpace(kind)))] {
+		ref = TagRef{Kind: TagKind(strings.ToLower(strings.TrimSpace(kind))), Name: strings.TrimSpace(name)}
+	}
+	if ref.Name == "" {
+		return TagRef{}, fmt.Errorf("пустое имя тега '%s'", value)
+	}
+	return ref, nil
+}
+
+// ParseTagKind разбирает вид тега. Пустая строка означает любой вид.
+func ParseTagKind(value string) (TagKind, error) {
+	kind := TagKind(strings.ToLower(strings.TrimSpace(value)))
+	if kind != "" && !tagKinds[kind] {
+		return "", fmt.Errorf("неизвестный вид тега '%s': ожидается %s, %s или %s", value, TagKindGenre, TagKindMood, TagKindTag)
+	}
+	return kind, nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagRef(t *testing.T) {
+
+	// Проверяем метод
+	ref, err := ParseTagRef("Genre: Rock ")
+	assert.NoError(t, err)
+	assert.Equal(t, TagRef{Kind: TagKindGenre, Name: "Rock"}, ref)
+
+	ref, err = ParseTagRef("sci-fi: space")
+	assert.NoError(t, err)
+	assert.Equal(t, TagRef{Name: "sci-fi: space"}, ref)
+
+	_, err = ParseTagRef("mood:")
+	assert.Error(t, err)
+
+	kind, err := ParseTagKind("MOOD")
+	assert.NoError(t, err)
+	assert.Equal(t, TagKindMood, kind)
+	_, err = ParseTagKind("style")
+	assert.Error(t, err)
+}
+
+func TestTagValidation(t *testing.T) {
+
+	// Создаем тестовые данные
+	tag := Tag{Name: " Rock "}
+	tag.Normalize()
+
+	// Проверяем метод
+	assert.Equal(t, Tag{Kind: TagKindTag, Name: "Rock"}, tag)
+	assert.NoError(t, tag.Validate())
+	assert.True(t, tag.Matches(TagRef{Name: "rock"}))
+	assert.False(t, tag.Matches(TagRef{Kind: TagKindGenre, Name: "rock"}))
+
+	invalid := Tag{Kind: "style", Name: "Rock"}
+	assert.ErrorContains(t, invalid.Validate(), "Kind")
+
+	invalid = Tag{Kind: TagKindMood}
+	assert.ErrorContains(t, invalid.Validate(), "поле 'Name' обязательно для заполнения")
+}
@@ -0,0 +1,122 @@
+package playlistfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxLineLength максимальная длина строки M3U.
+const maxLineLength = 64 * 1024
+
+// WriteM3U записывает плейлист в формате расширенного M3U8. Для каждого трека записывается директива
+// #EXTINF с длительностью и строкой "исполнитель - название". Треки без адреса пропускаются: в M3U
+// адрес обязателен.
+func WriteM3U(w io.Writer, playlist Playlist) error {
+	buf := bufio.NewWriter(w)
+
+	buf.WriteString("#EXTM3U\n")
+	if playlist.Title != "" {
+		fmt.Fprintf(buf, "#PLAYLIST:%s\n", singleLine(playlist.Title))
+	}
+	for _, track := range playlist.Tracks {
+		location := singleLine(track.Location)
+		if location == "" {
+			continue
+		}
+		duration := track.Duration
+		if duration < 0 {
+			duration = -1
+		}
+		fmt.Fprintf(buf, "#EXTINF:%d,%s\n%s\n", duration, extinfTitle(track), location)
+	}
+
+	return buf.Flush()
+}
+
+// extinfTitle возвращает название трека для директивы #EXTINF.
+func extinfTitle(track Track) string {
+	artist, title := singleLine(track.Artist), singleLine(track.Title)
+	if artist == "" {
+		return title
+	}
+	return artist + " - " + title
+}
+
+// ReadM3U читает плейлист в формате M3U или M3U8. Директива #EXTINF относится к следующей за ней строке адреса,
+// строки адресов без #EXTINF читаются как треки без исполнителя и названия. Прочие директивы и комментарии пропускаются.
+func ReadM3U(r io.Reader) (Playlist, error) {
+	var playlist Playlist
+	var pending *Track
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = string(bytes.TrimPrefix([]byte(text), utf8BOM))
+		}
+		text = strings.TrimSpace(text)
+
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			track := parseExtinf(strings.TrimPrefix(text, "#EXTINF:"))
+			track.Line = line
+			pending = &track
+		case strings.HasPrefix(text, "#PLAYLIST:"):
+			playlist.Title = strings.TrimSpace(strings.TrimPrefix(text, "#PLAYLIST:"))
+		case strings.HasPrefix(text, "#"):
+		default:
+			track := Track{Line: line, Duration: -1}
+			if pending != nil {
+				track = *pending
+				pending = nil
+			}
+			track.Location = text
+			playlist.Tracks = append(playlist.Tracks, track)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Playlist{}, fmt.Errorf("ошибка чтения M3U: %v", err)
+	}
+
+	return playlist, nil
+}
+
+// parseExtinf разбирает значение директивы #EXTINF: длительность, необязательные атрибуты key="value"
+// и после первой запятой вне кавычек — название в виде "исполнитель - название" или только название.
+func parseExtinf(value string) Track {
+	track := Track{Duration: -1}
+
+	comma := -1
+	quoted := false
+	for i, r := range value {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ',' && !quoted {
+			comma = i
+			break
+		}
+	}
+	if comma < 0 {
+		return track
+	}
+
+	durationPart, _, _ := strings.Cut(strings.TrimSpace(value[:comma]), " ")
+	if duration, err := strconv.ParseFloat(durationPart, 64); err == nil && duration >= 0 {
+		track.Duration = int(duration)
+	}
+
+	name := strings.TrimSpace(value[comma+1:])
+	if artist, title, ok := strings.Cut(name, " - "); ok {
+		track.Artist, track.Title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	} else {
+		track.Title = name
+	}
+
+	return track
+}
@@ -0,0 +1,62 @@
+package playlistfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteM3U(t *testing.T) {
+
+	// Создаем тестовые данные
+	playlist := Playlist{
+		Title: "Muse\nлучшее",
+		Tracks: []Track{
+			{Artist: "Muse", Title: "Uprising", Location: "https://example.com/uprising", Duration: -1},
+			{Artist: "Muse", Title: "Starlight", Duration: 240},
+			{Title: "Hysteria", Location: "https://example.com/hysteria", Duration: 227},
+		},
+	}
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	assert.NoError(t, WriteM3U(&buf, playlist))
+	assert.Equal(t, "#EXTM3U\n#PLAYLIST:Muse лучшее\n"+
+		"#EXTINF:-1,Muse - Uprising\nhttps://example.com/uprising\n"+
+		"#EXTINF:227,Hysteria\nhttps://example.com/hysteria\n", buf.String())
+}
+
+func TestReadM3U(t *testing.T) {
+
+	// Создаем тестовые данные
+	content := "\xEF\xBB\xBF#EXTM3U\r\n" +
+		"#PLAYLIST:Вечер\r\n" +
+		"#EXTINF:123 tvg-name=\"a, b\",Muse - Supermassive Black Hole\r\n" +
+		"https://example.com/sbh\r\n" +
+		"\r\n" +
+		"# комментарий\r\n" +
+		"/music/unknown.mp3\r\n" +
+		"#EXTINF:-1,Без исполнителя\r\n" +
+		"https://example.com/x\r\n"
+
+	// Проверяем метод
+	playlist, err := ReadM3U(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, "Вечер", playlist.Title)
+	assert.Equal(t, []Track{
+		{Line: 3, Artist: "Muse", Title: "Supermassive Black Hole", Location: "https://example.com/sbh", Duration: 123},
+		{Line: 7, Location: "/music/unknown.mp3", Duration: -1},
+		{Line: 8, Title: "Без исполнителя", Location: "https://example.com/x", Duration: -1},
+	}, playlist.Tracks)
+
+	// Записанный плейлист читается обратно
+	var buf bytes.Buffer
+	assert.NoError(t, WriteM3U(&buf, playlist))
+	again, err := Read(&buf, "")
+	assert.NoError(t, err)
+	assert.Equal(t, playlist.Title, again.Title)
+	assert.Equal(t, len(playlist.Tracks), len(again.Tracks))
+	assert.Equal(t, "Muse", again.Tracks[0].Artist)
+}
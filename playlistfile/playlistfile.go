@@ -0,0 +1,108 @@
+// Package playlistfile читает и записывает плейлисты в форматах расширенного M3U8 и XSPF.
+package playlistfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format формат файла плейлиста.
+type Format string
+
+const (
+	// FormatM3U8 расширенный M3U в кодировке UTF-8.
+	FormatM3U8 Format = "m3u8"
+	// FormatXSPF XML Shareable Playlist Format.
+	FormatXSPF Format = "xspf"
+)
+
+// Track трек плейлиста.
+type Track struct {
+	// Line номер строки файла, с которой начинается трек. Заполняется при чтении.
+	Line     int
+	Artist   string
+	Title    string
+	Location string
+	// Duration длительность в секундах, -1 если неизвестна.
+	Duration int
+}
+
+// Playlist плейлист: название и треки по порядку.
+type Playlist struct {
+	Title  string
+	Tracks []Track
+}
+
+// ParseFormat разбирает название формата: m3u8, m3u или xspf.
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "m3u8", "m3u":
+		return FormatM3U8, nil
+	case "xspf":
+		return FormatXSPF, nil
+	}
+	return "", fmt.Errorf("неизвестный формат плейлиста '%s': ожидается m3u8 или xspf", value)
+}
+
+// FormatByContentType определяет формат по типу содержимого. Возвращает false для неизвестного типа.
+func FormatByContentType(contentType string) (Format, bool) {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "audio/x-mpegurl", "audio/mpegurl", "application/vnd.apple.mpegurl", "application/x-mpegurl":
+		return FormatM3U8, true
+	case "application/xspf+xml":
+		return FormatXSPF, true
+	}
+	return "", false
+}
+
+// ContentType возвращает тип содержимого файла в формате.
+func (f Format) ContentType() string {
+	if f == FormatXSPF {
+		return "application/xspf+xml; charset=utf-8"
+	}
+	return "audio/x-mpegurl; charset=utf-8"
+}
+
+// Write записывает плейлист в указанном формате.
+func Write(w io.Writer, format Format, playlist Playlist) error {
+	if format == FormatXSPF {
+		return WriteXSPF(w, playlist)
+	}
+	return WriteM3U(w, playlist)
+}
+
+// Read читает плейлист. Если формат не указан, он определяется по первому значащему символу:
+// XSPF начинается с '<', остальное читается как M3U.
+func Read(r io.Reader, format Format) (Playlist, error) {
+	if format == "" {
+		reader := bufio.NewReader(r)
+		format = Sniff(reader)
+		r = reader
+	}
+	if format == FormatXSPF {
+		return ReadXSPF(r)
+	}
+	return ReadM3U(r)
+}
+
+// Sniff определяет формат по началу содержимого, не извлекая его из reader.
+func Sniff(reader *bufio.Reader) Format {
+	head, _ := reader.Peek(512)
+	head = bytes.TrimPrefix(head, utf8BOM)
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) {
+		return FormatXSPF
+	}
+	return FormatM3U8
+}
+
+// utf8BOM метка порядка байтов UTF-8, которую добавляют некоторые редакторы.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// singleLine заменяет переводы строк пробелами, чтобы значение не нарушило построчный формат.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
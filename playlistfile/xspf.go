@@ -0,0 +1,109 @@
+package playlistfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xspfNamespace пространство имен XSPF версии 1.
+const xspfNamespace = "http://xspf.org/ns/0/"
+
+// xspfPlaylist корневой элемент XSPF.
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack трек XSPF. Длительность указывается в миллисекундах.
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Duration int64  `xml:"duration,omitempty"`
+}
+
+// WriteXSPF записывает плейлист в формате XSPF. Треки без адреса записываются без элемента location.
+func WriteXSPF(w io.Writer, playlist Playlist) error {
+	doc := xspfPlaylist{Version: "1", Title: playlist.Title}
+	for _, track := range playlist.Tracks {
+		item := xspfTrack{Location: track.Location, Creator: track.Artist, Title: track.Title}
+		if track.Duration > 0 {
+			item.Duration = int64(track.Duration) * 1000
+		}
+		doc.Tracks = append(doc.Tracks, item)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadXSPF читает плейлист в формате XSPF. Номер строки трека — строка его открывающего элемента.
+func ReadXSPF(r io.Reader) (Playlist, error) {
+	var playlist Playlist
+	var root bool
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Playlist{}, fmt.Errorf("ошибка чтения XSPF: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case !root:
+			if start.Name.Local != "playlist" || (start.Name.Space != "" && start.Name.Space != xspfNamespace) {
+				return Playlist{}, fmt.Errorf("ошибка чтения XSPF: корневой элемент %s не является playlist", start.Name.Local)
+			}
+			root = true
+		case start.Name.Local == "title" && playlist.Title == "" && len(playlist.Tracks) == 0:
+			var title string
+			if err := decoder.DecodeElement(&title, &start); err != nil {
+				return Playlist{}, fmt.Errorf("ошибка чтения XSPF: %v", err)
+			}
+			playlist.Title = strings.TrimSpace(title)
+		case start.Name.Local == "track":
+			line, _ := decoder.InputPos()
+			var item xspfTrack
+			if err := decoder.DecodeElement(&item, &start); err != nil {
+				return Playlist{}, fmt.Errorf("ошибка чтения XSPF: %v", err)
+			}
+			track := Track{
+				Line:     line,
+				Artist:   strings.TrimSpace(item.Creator),
+				Title:    strings.TrimSpace(item.Title),
+				Location: strings.TrimSpace(item.Location),
+				Duration: -1,
+			}
+			if item.Duration > 0 {
+				track.Duration = int(item.Duration / 1000)
+			}
+			playlist.Tracks = append(playlist.Tracks, track)
+		}
+	}
+
+	if !root {
+		return Playlist{}, fmt.Errorf("ошибка чтения XSPF: не найден элемент playlist")
+	}
+
+	return playlist, nil
+}
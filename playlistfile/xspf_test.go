@@ -0,0 +1,49 @@
+package playlistfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXSPFRoundTrip(t *testing.T) {
+
+	// Создаем тестовые данные
+	playlist := Playlist{
+		Title: "Muse & Queen",
+		Tracks: []Track{
+			{Artist: "Muse", Title: "Uprising", Location: "https://example.com/uprising?a=1&b=2", Duration: 305},
+			{Artist: "Queen", Title: "Bohemian Rhapsody", Duration: -1},
+		},
+	}
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	assert.NoError(t, WriteXSPF(&buf, playlist))
+	assert.Contains(t, buf.String(), `<playlist xmlns="http://xspf.org/ns/0/" version="1">`)
+	assert.Contains(t, buf.String(), "<location>https://example.com/uprising?a=1&amp;b=2</location>")
+	assert.Contains(t, buf.String(), "<duration>305000</duration>")
+
+	result, err := Read(&buf, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse & Queen", result.Title)
+	assert.Equal(t, 2, len(result.Tracks))
+	assert.Equal(t, Track{Line: 5, Artist: "Muse", Title: "Uprising", Location: "https://example.com/uprising?a=1&b=2", Duration: 305}, result.Tracks[0])
+	assert.Equal(t, "", result.Tracks[1].Location)
+	assert.Equal(t, -1, result.Tracks[1].Duration)
+}
+
+func TestReadXSPFInvalid(t *testing.T) {
+
+	// Проверяем метод
+	_, err := ReadXSPF(strings.NewReader("<html></html>"))
+	assert.Error(t, err)
+
+	_, err = ReadXSPF(strings.NewReader(`<playlist version="1"><trackList><track>`))
+	assert.Error(t, err)
+
+	_, err = ReadXSPF(strings.NewReader(""))
+	assert.Error(t, err)
+}
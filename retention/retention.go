@@ -0,0 +1,52 @@
+// Package retention периодически очищает корзину удаленных песен.
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"music-info/database"
+)
+
+// Job задание очистки корзины: окончательно удаляет песни, пролежавшие в корзине дольше срока хранения.
+type Job struct {
+	trash     database.TrashRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewJob создает задание, удаляющее песни старше retention с периодом interval.
+func NewJob(trash database.TrashRepository, retention, interval time.Duration) *Job {
+	return &Job{trash: trash, retention: retention, interval: interval}
+}
+
+// Purge окончательно удаляет песни, удаленные раньше now минус срок хранения, и возвращает их количество.
+func (j *Job) Purge(now time.Time) (int64, error) {
+	return j.trash.PurgeDeletedSongs(now.Add(-j.retention))
+}
+
+// Run очищает корзину с заданным периодом, пока не будет отменен ctx. Первая очистка выполняется
+// через период после запуска, чтобы перезапуск сервера не удалял песни сразу после старта.
+// Ошибка очистки записывается в журнал и не останавливает задание.
+func (j *Job) Run(ctx context.Context) {
+	log.Printf("Очистка корзины: срок хранения %s, период %s\n", j.retention, j.interval)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Очистка корзины остановлена")
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := j.Purge(time.Now())
+		if err != nil {
+			log.Printf("Ошибка при очистке корзины: %v\n", err)
+		} else if purged > 0 {
+			log.Printf("Из корзины окончательно удалено песен: %d\n", purged)
+		}
+	}
+}
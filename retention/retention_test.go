@@ -0,0 +1,70 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobPurge(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	assert.NoError(t, repo.Create(&songInfo))
+	assert.NoError(t, repo.DeleteByID(songInfo.ID))
+	job := NewJob(repo, time.Hour, time.Minute)
+
+	// Проверяем метод
+	purged, err := job.Purge(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = job.Purge(time.Now().Add(2 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	songs, err := repo.DeletedSongs(1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, songs)
+}
+
+func TestJobRun(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	songInfo := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	assert.NoError(t, repo.Create(&songInfo))
+	assert.NoError(t, repo.DeleteByID(songInfo.ID))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// Проверяем метод
+	go func() {
+		NewJob(repo, 0, 200*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	// При запуске корзина не очищается, первая очистка выполняется через период
+	time.Sleep(50 * time.Millisecond)
+	songs, err := repo.DeletedSongs(1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, songs, 1)
+
+	assert.Eventually(t, func() bool {
+		songs, err := repo.DeletedSongs(1, 10)
+		return err == nil && len(songs) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("задание не остановилось после отмены контекста")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// worker фоновая задача, которая работает до отмены переданного контекста.
+type worker interface {
+	Run(ctx context.Context)
+}
+
+// serve обслуживает запросы listener до отмены ctx, запустив фоновые задачи workers.
+// После отмены сервер перестает принимать соединения и дожидается начатых запросов, затем задачи получают
+// отмену своего контекста. На все это отводится drain, по истечении которого оставшиеся соединения закрываются.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, drain time.Duration, workers ...worker) error {
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Run(workersCtx)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// Сервер остановился сам, без сигнала: фоновые задачи останавливаются так же, как при сигнале
+		log.Printf("Ошибка сервера: %v\n", err)
+	case <-ctx.Done():
+		log.Printf("Остановка сервера, ожидание начатых запросов до %s\n", drain)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Printf("Не все запросы завершились за %s: %v\n", drain, shutdownErr)
+		server.Close()
+		err = errors.Join(err, shutdownErr)
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		log.Println("Не все фоновые задачи остановились за отведенное время")
+		err = errors.Join(err, shutdownCtx.Err())
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingWorker фоновая задача, которая отмечает свою остановку.
+type blockingWorker struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (w *blockingWorker) Run(ctx context.Context) {
+	close(w.started)
+	<-ctx.Done()
+	close(w.stopped)
+}
+
+// stuckWorker фоновая задача, которая не реагирует на отмену контекста.
+type stuckWorker struct{}
+
+func (stuckWorker) Run(ctx context.Context) {
+	select {}
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+
+	// Создаем тестовые данные
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	requestStarted := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})}
+	w := &blockingWorker{started: make(chan struct{}), stopped: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, server, listener, 5*time.Second, w)
+	}()
+	<-w.started
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+	<-requestStarted
+
+	// Проверяем метод
+	cancel()
+	res := <-response
+	assert.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	assert.NoError(t, <-served)
+
+	select {
+	case <-w.stopped:
+	default:
+		t.Error("фоновая задача не остановлена")
+	}
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestServeDrainTimeout(t *testing.T) {
+
+	// Создаем тестовые данные
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := &http.Server{Handler: http.NotFoundHandler()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Проверяем метод
+	err = serve(ctx, server, listener, 50*time.Millisecond, stuckWorker{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServeListenerError(t *testing.T) {
+
+	// Создаем тестовые данные
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	listener.Close()
+	w := &blockingWorker{started: make(chan struct{}), stopped: make(chan struct{})}
+
+	// Проверяем метод
+	err = serve(context.Background(), &http.Server{}, listener, time.Second, w)
+	assert.Error(t, err)
+	<-w.stopped
+}
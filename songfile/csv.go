@@ -0,0 +1,119 @@
+package songfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"music-info/models"
+)
+
+// csvReader читает песни из CSV. Первая строка — заголовок с названиями полей из Columns в любом порядке,
+// обязательны столбцы group и song. Столбцы выгрузки id, artistId, createdAt и updatedAt пропускаются. Текст песни может занимать несколько строк в кавычках.
+type csvReader struct {
+	reader *csv.Reader
+	// columns индекс столбца для каждого поля, -1 если столбца нет.
+	columns map[string]int
+}
+
+// newCSVReader создает читателя CSV и разбирает заголовок.
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(skipBOM(r))
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("файл CSV пуст: ожидается заголовок")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка CSV: %v", err)
+	}
+
+	columns := make(map[string]int, len(Columns))
+	for _, name := range Columns {
+		columns[name] = -1
+	}
+	for i, name := range header {
+		if exportOnly(name) {
+			continue
+		}
+		field, ok := csvField(name)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный столбец CSV '%s': ожидаются %s", name, strings.Join(Columns, ", "))
+		}
+		if columns[field] >= 0 {
+			return nil, fmt.Errorf("столбец CSV '%s' указан дважды", name)
+		}
+		columns[field] = i
+	}
+	if columns["group"] < 0 || columns["song"] < 0 {
+		return nil, errors.New("в заголовке CSV должны быть столбцы group и song")
+	}
+	reader.FieldsPerRecord = len(header)
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+// exportOnly проверяет, что столбец заполняется хранилищем и записывается только при выгрузке.
+func exportOnly(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "id", "artistid", "createdat", "updatedat":
+		return true
+	}
+	return false
+}
+
+// csvField возвращает поле песни для названия столбца без учета регистра. Дата выпуска принимается
+// также как release_date.
+func csvField(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "release_date" {
+		name = "releasedate"
+	}
+	for _, field := range Columns {
+		if strings.ToLower(field) == name {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// Read возвращает следующую песню. Строка с неверным количеством столбцов или неверной датой выпуска
+// возвращается как запись с ошибкой, нарушение кавычек прерывает чтение.
+func (c *csvReader) Read() (Record, error) {
+	values, err := c.reader.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+		return Record{Line: parseErr.StartLine, Err: fmt.Errorf("ожидается столбцов: %d", c.reader.FieldsPerRecord)}, nil
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("ошибка чтения CSV: %v", err)
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	record := Record{Line: line}
+	value := func(field string) string {
+		if i := c.columns[field]; i >= 0 {
+			return values[i]
+		}
+		return ""
+	}
+
+	record.Song = models.MusicInfo{
+		Group: value("group"),
+		Song:  value("song"),
+		Text:  value("text"),
+		Link:  value("link"),
+	}
+	if releaseDate := strings.TrimSpace(value("releaseDate")); releaseDate != "" {
+		record.Song.ReleaseDate, record.Err = models.ParseReleaseDate(releaseDate)
+	}
+
+	return record, nil
+}
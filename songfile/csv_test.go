@@ -0,0 +1,84 @@
+package songfile
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readAll читает все записи файла.
+func readAll(t *testing.T, reader Reader) []Record {
+	var records []Record
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if !assert.NoError(t, err) {
+			return records
+		}
+		records = append(records, record)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+
+	// Создаем тестовые данные
+	content := "\xEF\xBB\xBFSong,Group,release_date,Text\r\n" +
+		"Supermassive Black Hole,Muse,16.07.2006,\"Ooh baby\r\n\r\nYou set my soul alight\"\r\n" +
+		"Starlight,Muse,32.13.2006,Far away\r\n" +
+		"Uprising,Muse\r\n" +
+		"Hysteria,Muse,,\"It's bugging me\"\r\n"
+
+	// Проверяем метод
+	reader, err := NewReader(strings.NewReader(content), FormatCSV)
+	assert.NoError(t, err)
+	records := readAll(t, reader)
+	if !assert.Equal(t, 4, len(records)) {
+		return
+	}
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby\n\nYou set my soul alight",
+	}, records[0].Song)
+
+	assert.Equal(t, 5, records[1].Line)
+	assert.Error(t, records[1].Err)
+
+	assert.Equal(t, 6, records[2].Line)
+	assert.EqualError(t, records[2].Err, "ожидается столбцов: 4")
+
+	assert.Equal(t, 7, records[3].Line)
+	assert.NoError(t, records[3].Err)
+	assert.True(t, records[3].Song.ReleaseDate.IsZero())
+}
+
+func TestCSVReaderErrors(t *testing.T) {
+
+	// Проверяем метод
+	_, err := NewReader(strings.NewReader(""), FormatCSV)
+	assert.EqualError(t, err, "файл CSV пуст: ожидается заголовок")
+
+	_, err = NewReader(strings.NewReader("group,song,album\n"), FormatCSV)
+	assert.ErrorContains(t, err, "неизвестный столбец CSV 'album'")
+
+	_, err = NewReader(strings.NewReader("group,text\n"), FormatCSV)
+	assert.EqualError(t, err, "в заголовке CSV должны быть столбцы group и song")
+
+	_, err = NewReader(strings.NewReader("group,song,Group\n"), FormatCSV)
+	assert.EqualError(t, err, "столбец CSV 'Group' указан дважды")
+
+	reader, err := NewReader(strings.NewReader("group,song\nMuse,\"Uprising\nMuse,Starlight\n"), FormatCSV)
+	assert.NoError(t, err)
+	_, err = reader.Read()
+	assert.ErrorContains(t, err, "ошибка чтения CSV")
+}
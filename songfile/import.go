@@ -0,0 +1,167 @@
+package songfile
+
+import (
+	"errors"
+	"io"
+
+	"music-info/database"
+	"music-info/models"
+)
+
+// DefaultBatchSize количество песен, сохраняемых одной транзакцией, по умолчанию.
+const DefaultBatchSize = 500
+
+// MaxBatchSize максимальное количество песен в одной транзакции.
+const MaxBatchSize = 5000
+
+// RowStatus результат импорта записи.
+type RowStatus string
+
+const (
+	// RowCreated песня создана, при пробном импорте — будет создана.
+	RowCreated RowStatus = "created"
+	// RowSkipped песня с такими группой и названием уже существует или встречается выше в файле.
+	RowSkipped RowStatus = "skipped"
+	// RowFailed запись не прочитана, не прошла проверку или не сохранена.
+	RowFailed RowStatus = "failed"
+)
+
+// RowResult результат импорта одной записи файла.
+// @Description Номер строки файла, результат, идентификатор созданной или существующей песни и описание ошибки.
+type RowResult struct {
+	Line   int       `json:"line" example:"2"`
+	Status RowStatus `json:"status" example:"created" enums:"created,skipped,failed"`
+	// ID идентификатор созданной песни или, для пропущенной, существующей песни.
+	ID    uint   `json:"id,omitempty" example:"1"`
+	Group string `json:"group" example:"Muse"`
+	Song  string `json:"song" example:"Supermassive Black Hole"`
+	Error string `json:"error,omitempty" example:"поле 'Text' обязательно для заполнения"`
+}
+
+// Report результат импорта файла.
+// @Description Количество записей по результатам и результат каждой записи в порядке файла.
+type Report struct {
+	DryRun  bool        `json:"dryRun"`
+	Total   int         `json:"total" example:"3"`
+	Created int         `json:"created" example:"1"`
+	Skipped int         `json:"skipped" example:"1"`
+	Failed  int         `json:"failed" example:"1"`
+	Rows    []RowResult `json:"rows"`
+}
+
+// Options параметры импорта.
+type Options struct {
+	// BatchSize количество песен в одной транзакции, DefaultBatchSize если не задано.
+	BatchSize int
+	// DryRun только проверить записи, не сохраняя песни.
+	DryRun bool
+	// Author автор, записываемый в историю правок созданных песен.
+	Author string
+}
+
+// Import читает записи файла, проверяет каждую методом MusicInfo.Validate и сохраняет прошедшие
+// проверку пакетами по opts.BatchSize песен. Ошибка отдельной записи попадает в отчет и не прерывает импорт.
+// Если файл поврежден так, что читать его дальше нельзя, отчет завершается записью с ошибкой чтения.
+// Ошибка возвращается, только если не удалось сохранить пакет: песни предыдущих пакетов при этом уже сохранены.
+func Import(repo database.SongRepository, reader Reader, opts Options) (Report, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	report := Report{DryRun: opts.DryRun, Rows: []RowResult{}}
+
+	// batch песни пакета и индексы их записей в отчете.
+	var batch []models.MusicInfo
+	var rows []int
+	// checked ключи песен, которые будут созданы при пробном импорте. Пакеты пробного импорта
+	// не сохраняются, поэтому совпадения с песнями предыдущих пакетов проверяются здесь.
+	checked := make(map[[2]string]bool)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := repo.ImportSongs(batch, opts.DryRun)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			row := &report.Rows[rows[i]]
+			key := songKey(&batch[i])
+			var duplicate *database.DuplicateSongError
+			switch {
+			case result == nil && checked[key]:
+				row.Status, row.Error = RowSkipped, (&database.DuplicateSongError{Group: batch[i].Group, Song: batch[i].Song}).Error()
+			case result == nil:
+				row.Status, row.ID = RowCreated, batch[i].ID
+				if opts.DryRun {
+					checked[key] = true
+				}
+			case errors.As(result, &duplicate):
+				row.Status, row.ID, row.Error = RowSkipped, duplicate.ID, result.Error()
+			default:
+				row.Status, row.Error = RowFailed, result.Error()
+			}
+		}
+		batch, rows = batch[:0], rows[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Rows = append(report.Rows, RowResult{Status: RowFailed, Error: err.Error()})
+			break
+		}
+
+		row := RowResult{Line: record.Line, Group: record.Song.Group, Song: record.Song.Song}
+		if record.Err == nil {
+			record.Err = record.Song.Validate()
+		}
+		if record.Err != nil {
+			row.Status, row.Error = RowFailed, record.Err.Error()
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+
+		report.Rows = append(report.Rows, row)
+		record.Song.ChangedBy = opts.Author
+		batch = append(batch, record.Song)
+		rows = append(rows, len(report.Rows)-1)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return report.count(), err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return report.count(), err
+	}
+
+	return report.count(), nil
+}
+
+// songKey возвращает пару группы и названия песни в том виде, в котором проверяется уникальность.
+func songKey(songInfo *models.MusicInfo) [2]string {
+	return [2]string{models.NormalizeKey(songInfo.Group), models.NormalizeKey(songInfo.Song)}
+}
+
+// count подсчитывает записи по результатам. Записи несохраненного пакета не имеют результата и не учитываются.
+func (r Report) count() Report {
+	r.Total, r.Created, r.Skipped, r.Failed = 0, 0, 0, 0
+	for _, row := range r.Rows {
+		switch row.Status {
+		case RowCreated:
+			r.Created++
+		case RowSkipped:
+			r.Skipped++
+		case RowFailed:
+			r.Failed++
+		default:
+			continue
+		}
+		r.Total++
+	}
+	return r
+}
@@ -0,0 +1,73 @@
+package songfile
+
+import (
+	"strings"
+	"testing"
+
+	"music-info/database"
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	existing := models.MusicInfo{Group: "Muse", Song: "Uprising", Text: "Paranoia"}
+	assert.NoError(t, repo.Create(&existing))
+	content := "group,song,text\n" +
+		"Muse,Starlight,Far away\n" +
+		"muse ,uprising,Paranoia\n" +
+		"Muse,Hysteria,\n" +
+		"Muse,Knights of Cydonia,Come ride with me\n" +
+		"MUSE,starlight,Far away\n"
+
+	// Проверяем метод
+	reader, err := NewReader(strings.NewReader(content), FormatCSV)
+	assert.NoError(t, err)
+	report, err := Import(repo, reader, Options{BatchSize: 2, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, RowResult{Line: 3, Status: RowSkipped, ID: existing.ID, Group: "muse ", Song: "uprising",
+		Error: report.Rows[1].Error}, report.Rows[1])
+	assert.Equal(t, RowResult{Line: 4, Status: RowFailed, Group: "Muse", Song: "Hysteria",
+		Error: "поле 'Text' обязательно для заполнения"}, report.Rows[2])
+	count, err := repo.Count(database.SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	reader, err = NewReader(strings.NewReader(content), FormatCSV)
+	assert.NoError(t, err)
+	report, err = Import(repo, reader, Options{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, RowCreated, report.Rows[0].Status)
+	assert.NotZero(t, report.Rows[0].ID)
+	assert.Equal(t, RowSkipped, report.Rows[4].Status)
+	assert.Equal(t, report.Rows[0].ID, report.Rows[4].ID)
+	count, err = repo.Count(database.SongFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestImportReadError(t *testing.T) {
+
+	// Создаем тестовые данные
+	repo := database.NewMemoryRepository()
+	content := "group,song,text\nMuse,Starlight,Far away\nMuse,\"Uprising,Paranoia\n"
+
+	// Проверяем метод
+	reader, err := NewReader(strings.NewReader(content), FormatCSV)
+	assert.NoError(t, err)
+	report, err := Import(repo, reader, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, len(report.Rows))
+	assert.Contains(t, report.Rows[1].Error, "ошибка чтения CSV")
+}
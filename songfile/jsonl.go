@@ -0,0 +1,60 @@
+package songfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"music-info/models"
+)
+
+// maxLineLength максимальная длина строки JSON Lines.
+const maxLineLength = 1 << 20
+
+// jsonlReader читает песни из JSON Lines. Пустые строки пропускаются, поля, которые заполняет хранилище,
+// такие как id и даты создания, не используются.
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// newJSONLReader создает читателя JSON Lines.
+func newJSONLReader(r io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(skipBOM(r))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	return &jsonlReader{scanner: scanner}
+}
+
+// Read возвращает следующую песню. Строка с неверным JSON возвращается как запись с ошибкой,
+// строка длиннее maxLineLength прерывает чтение.
+func (j *jsonlReader) Read() (Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := bytes.TrimSpace(j.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		record := Record{Line: j.line}
+		var songInfo models.MusicInfo
+		if err := json.Unmarshal(data, &songInfo); err != nil {
+			record.Err = fmt.Errorf("неверный формат JSON: %v", err)
+			return record, nil
+		}
+		record.Song = models.MusicInfo{
+			Group:       songInfo.Group,
+			Song:        songInfo.Song,
+			ReleaseDate: songInfo.ReleaseDate,
+			Text:        songInfo.Text,
+			Link:        songInfo.Link,
+		}
+		return record, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("ошибка чтения JSON Lines в строке %d: %v", j.line+1, err)
+	}
+	return Record{}, io.EOF
+}
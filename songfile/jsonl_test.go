@@ -0,0 +1,68 @@
+package songfile
+
+import (
+	"strings"
+	"testing"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLReader(t *testing.T) {
+
+	// Создаем тестовые данные
+	content := "\xEF\xBB\xBF{\"id\":7,\"group\":\"Muse\",\"song\":\"Uprising\",\"releaseDate\":\"2009\",\"text\":\"Paranoia\",\"artistId\":3}\n" +
+		"\n" +
+		"{\"group\":\"Muse\",\"song\":\"Starlight\"\n" +
+		"  {\"group\":\"Muse\",\"song\":\"Hysteria\",\"link\":\"https://example.com/hysteria\"}  \r\n"
+
+	// Проверяем метод
+	reader, err := NewReader(strings.NewReader(content), FormatJSONL)
+	assert.NoError(t, err)
+	records := readAll(t, reader)
+	if !assert.Equal(t, 3, len(records)) {
+		return
+	}
+
+	assert.Equal(t, 1, records[0].Line)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Uprising",
+		ReleaseDate: models.MustParseReleaseDate("2009"),
+		Text:        "Paranoia",
+	}, records[0].Song)
+
+	assert.Equal(t, 3, records[1].Line)
+	assert.ErrorContains(t, records[1].Err, "неверный формат JSON")
+
+	assert.Equal(t, 4, records[2].Line)
+	assert.Equal(t, "https://example.com/hysteria", records[2].Song.Link)
+}
+
+func TestParseFormat(t *testing.T) {
+
+	// Проверяем метод
+	format, err := ParseFormat(" CSV ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("ndjson")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSONL, format)
+
+	_, err = ParseFormat("xlsx")
+	assert.Error(t, err)
+
+	format, ok := FormatByContentType("application/x-ndjson; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, FormatJSONL, format)
+
+	format, ok = FormatByExtension("/tmp/catalog.csv")
+	assert.True(t, ok)
+	assert.Equal(t, FormatCSV, format)
+
+	_, ok = FormatByExtension("catalog.txt")
+	assert.False(t, ok)
+}
@@ -0,0 +1,96 @@
+// Package songfile читает и записывает песни в файлах CSV и JSON Lines и импортирует их в хранилище пакетами.
+package songfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"music-info/models"
+)
+
+// Format формат файла песен.
+type Format string
+
+const (
+	// FormatCSV CSV с заголовком из названий полей песни.
+	FormatCSV Format = "csv"
+	// FormatJSONL JSON Lines: объект песни в формате API на каждой строке.
+	FormatJSONL Format = "jsonl"
+	// FormatExcel CSV для табличных редакторов: с меткой порядка байтов, переводами строк CRLF
+	// и апострофом перед значениями, похожими на формулы. Читается как CSV.
+	FormatExcel Format = "excel"
+)
+
+// Columns поля песни в файле в порядке столбцов CSV. Названия совпадают с полями JSON.
+var Columns = []string{"group", "song", "releaseDate", "text", "link"}
+
+// Record песня, прочитанная из файла.
+type Record struct {
+	// Line номер строки файла, с которой начинается запись.
+	Line int
+	Song models.MusicInfo
+	// Err ошибка разбора записи. Остальные записи файла при этом читаются дальше.
+	Err error
+}
+
+// Reader читает записи файла песен по одной.
+type Reader interface {
+	// Read возвращает следующую запись или io.EOF в конце файла. Ошибка возвращается, если файл
+	// поврежден так, что следующие записи прочитать нельзя; при этом в ней указывается номер строки.
+	Read() (Record, error)
+}
+
+// ParseFormat разбирает название формата: csv, excel или jsonl (также ndjson).
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "csv":
+		return FormatCSV, nil
+	case "excel":
+		return FormatExcel, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("неизвестный формат файла песен '%s': ожидается csv, excel или jsonl", value)
+}
+
+// FormatByContentType определяет формат по типу содержимого. Возвращает false для неизвестного типа.
+func FormatByContentType(contentType string) (Format, bool) {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return FormatJSONL, true
+	}
+	return "", false
+}
+
+// FormatByExtension определяет формат по расширению имени файла. Возвращает false для неизвестного расширения.
+func FormatByExtension(name string) (Format, bool) {
+	format, err := ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+	return format, err == nil
+}
+
+// NewReader создает читателя файла в указанном формате. Для CSV сразу читается и проверяется заголовок.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	if format == FormatJSONL {
+		return newJSONLReader(r), nil
+	}
+	return newCSVReader(r)
+}
+
+// utf8BOM метка порядка байтов UTF-8, которую добавляют табличные редакторы.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// skipBOM пропускает метку порядка байтов в начале содержимого.
+func skipBOM(r io.Reader) io.Reader {
+	reader := bufio.NewReader(r)
+	if head, _ := reader.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		reader.Discard(len(utf8BOM))
+	}
+	return reader
+}
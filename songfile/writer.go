@@ -0,0 +1,149 @@
+package songfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-info/models"
+)
+
+// ExportColumns столбцы CSV при выгрузке. Столбцы, которые заполняет хранилище, при импорте пропускаются,
+// поэтому выгруженный файл можно загрузить обратно.
+var ExportColumns = []string{"id", "group", "song", "releaseDate", "text", "link", "artistId", "createdAt", "updatedAt"}
+
+// Writer записывает песни в файл.
+type Writer interface {
+	// Write записывает песню.
+	Write(songInfo *models.MusicInfo) error
+	// Flush передает записанные данные в нижележащий поток.
+	Flush() error
+}
+
+// ContentType возвращает тип содержимого файла в формате.
+func (f Format) ContentType() string {
+	if f == FormatJSONL {
+		return "application/x-ndjson; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension возвращает расширение имени файла в формате без точки.
+func (f Format) Extension() string {
+	if f == FormatJSONL {
+		return "jsonl"
+	}
+	return "csv"
+}
+
+// NewWriter создает запись файла в указанном формате. Для CSV сразу записывается заголовок.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatExcel:
+		return newCSVWriter(w, true)
+	}
+	return newCSVWriter(w, false)
+}
+
+// csvWriter записывает песни в CSV по RFC 4180: значения с запятыми, кавычками и переводами строк,
+// в том числе многострочный текст песни, заключаются в кавычки.
+type csvWriter struct {
+	writer *csv.Writer
+	// excel включает совместимость с табличными редакторами: метку порядка байтов, переводы строк CRLF
+	// и защиту от выполнения значений, похожих на формулы.
+	excel  bool
+	record []string
+}
+
+// newCSVWriter создает запись CSV и записывает заголовок.
+func newCSVWriter(w io.Writer, excel bool) (*csvWriter, error) {
+	if excel {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = excel
+	if err := writer.Write(ExportColumns); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer, excel: excel, record: make([]string, len(ExportColumns))}, nil
+}
+
+// Write записывает песню строкой CSV. Дата выпуска записывается в формате вывода API, время — в RFC 3339.
+func (c *csvWriter) Write(songInfo *models.MusicInfo) error {
+	var artistID string
+	if songInfo.ArtistID != nil {
+		artistID = strconv.FormatUint(uint64(*songInfo.ArtistID), 10)
+	}
+	var releaseDate string
+	if !songInfo.ReleaseDate.IsZero() {
+		releaseDate = songInfo.ReleaseDate.String()
+	}
+
+	values := []string{
+		strconv.FormatUint(uint64(songInfo.ID), 10),
+		songInfo.Group,
+		songInfo.Song,
+		releaseDate,
+		songInfo.Text,
+		songInfo.Link,
+		artistID,
+		songInfo.CreatedAt.UTC().Format(time.RFC3339),
+		songInfo.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	for i, value := range values {
+		if c.excel {
+			value = escapeFormula(strings.ReplaceAll(value, "\r\n", "\n"))
+		}
+		c.record[i] = value
+	}
+
+	return c.writer.Write(c.record)
+}
+
+// Flush передает записанные строки в нижележащий поток.
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// escapeFormula добавляет апостроф перед значением, которое табличный редактор выполнил бы как формулу.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// jsonlWriter записывает песни в JSON Lines в формате API.
+type jsonlWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// newJSONLWriter создает запись JSON Lines.
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return &jsonlWriter{buf: buf, encoder: encoder}
+}
+
+// Write записывает песню одной строкой JSON.
+func (j *jsonlWriter) Write(songInfo *models.MusicInfo) error {
+	return j.encoder.Encode(songInfo)
+}
+
+// Flush передает записанные строки в нижележащий поток.
+func (j *jsonlWriter) Flush() error {
+	return j.buf.Flush()
+}
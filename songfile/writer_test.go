@@ -0,0 +1,98 @@
+package songfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"music-info/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportSong возвращает песню для проверки выгрузки.
+func exportSong() models.MusicInfo {
+	artistID := uint(3)
+	songInfo := models.MusicInfo{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: models.MustParseReleaseDate("16.07.2006"),
+		Text:        "Ooh baby, \"don't\" you know\r\n\r\n=You set my soul alight",
+		Link:        "-https://example.com/sbh",
+		ArtistID:    &artistID,
+	}
+	songInfo.ID = 7
+	songInfo.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	songInfo.UpdatedAt = songInfo.CreatedAt
+	return songInfo
+}
+
+func TestCSVWriter(t *testing.T) {
+
+	// Создаем тестовые данные
+	songInfo := exportSong()
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatCSV)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(&songInfo))
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "id,group,song,releaseDate,text,link,artistId,createdAt,updatedAt\n"+
+		"7,Muse,Supermassive Black Hole,16.07.2006,\"Ooh baby, \"\"don't\"\" you know\r\n\r\n=You set my soul alight\","+
+		"-https://example.com/sbh,3,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n", buf.String())
+
+	// Выгрузка читается обратно, переводы строк CRLF в тексте читаются как LF
+	reader, err := NewReader(&buf, FormatCSV)
+	assert.NoError(t, err)
+	records := readAll(t, reader)
+	if assert.Equal(t, 1, len(records)) {
+		assert.NoError(t, records[0].Err)
+		assert.Equal(t, strings.ReplaceAll(songInfo.Text, "\r\n", "\n"), records[0].Song.Text)
+		assert.True(t, songInfo.ReleaseDate.Equal(records[0].Song.ReleaseDate))
+	}
+}
+
+func TestExcelWriter(t *testing.T) {
+
+	// Создаем тестовые данные
+	songInfo := exportSong()
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatExcel)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(&songInfo))
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "\xEF\xBB\xBFid,group,song,releaseDate,text,link,artistId,createdAt,updatedAt\r\n"+
+		"7,Muse,Supermassive Black Hole,16.07.2006,\"Ooh baby, \"\"don't\"\" you know\r\n\r\n=You set my soul alight\","+
+		"'-https://example.com/sbh,3,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\r\n", buf.String())
+
+	assert.Equal(t, "'=SUM(A1)", escapeFormula("=SUM(A1)"))
+	assert.Equal(t, "'@cmd", escapeFormula("@cmd"))
+	assert.Equal(t, "Muse", escapeFormula("Muse"))
+	assert.Equal(t, "", escapeFormula(""))
+}
+
+func TestJSONLWriter(t *testing.T) {
+
+	// Создаем тестовые данные
+	songInfo := exportSong()
+
+	// Проверяем метод
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatJSONL)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(&songInfo))
+	assert.NoError(t, writer.Write(&songInfo))
+	assert.NoError(t, writer.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 2, len(lines))
+	var decoded models.MusicInfo
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &decoded))
+	assert.Equal(t, songInfo.Text, decoded.Text)
+	assert.Equal(t, uint(7), decoded.ID)
+}
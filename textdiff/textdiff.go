@@ -0,0 +1,183 @@
+// Package textdiff сравнивает тексты построчно алгоритмом Майерса.
+package textdiff
+
+import "strings"
+
+// Op операция над строкой при переходе от старого текста к новому.
+type Op int
+
+const (
+	// Equal строка есть в обоих текстах.
+	Equal Op = iota
+	// Delete строка есть только в старом тексте.
+	Delete
+	// Insert строка есть только в новом тексте.
+	Insert
+)
+
+// String возвращает название операции: equal, delete или insert.
+func (o Op) String() string {
+	switch o {
+	case Delete:
+		return "delete"
+	case Insert:
+		return "insert"
+	default:
+		return "equal"
+	}
+}
+
+// Edit строка сравнения. Номера строк начинаются с 1; у добавленной строки OldLine равен 0, у удаленной — NewLine.
+type Edit struct {
+	Op      Op
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// maxCost наибольшее число добавленных и удаленных строк, для которого ищется кратчайшее сравнение.
+// Память поиска растет квадратично от этого числа, поэтому при большем числе различий
+// отличающаяся часть текстов считается замененной целиком.
+const maxCost = 2000
+
+// Split разбивает текст на строки. Переводы строк CRLF считаются одним переводом, завершающий перевод строки
+// не образует пустую строку.
+func Split(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Lines возвращает кратчайшее построчное сравнение старого текста a с новым текстом b
+// в порядке строк: удаленные строки идут перед добавленными на их месте.
+func Lines(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Op, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, Equal)
+	}
+	ops = append(ops, script(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, Equal)
+	}
+
+	edits := make([]Edit, 0, len(ops))
+	oldLine, newLine := 0, 0
+	for _, op := range ops {
+		switch op {
+		case Equal:
+			oldLine++
+			newLine++
+			edits = append(edits, Edit{Op: Equal, OldLine: oldLine, NewLine: newLine, Text: a[oldLine-1]})
+		case Delete:
+			oldLine++
+			edits = append(edits, Edit{Op: Delete, OldLine: oldLine, Text: a[oldLine-1]})
+		case Insert:
+			newLine++
+			edits = append(edits, Edit{Op: Insert, NewLine: newLine, Text: b[newLine-1]})
+		}
+	}
+
+	return edits
+}
+
+// script возвращает операции кратчайшего перехода от a к b. Для каждого шага d сохраняются
+// достигнутые на предыдущем шаге позиции диагоналей -d..d, по которым затем восстанавливается путь.
+func script(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replace(n, m)
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		if d > maxCost {
+			return replace(n, m)
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+
+	return replace(n, m)
+}
+
+// backtrack восстанавливает операции по сохраненным позициям диагоналей, проходя путь от конца к началу.
+func backtrack(trace [][]int, n, m int) []Op {
+	var ops []Op
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, Equal)
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, Insert)
+		} else {
+			ops = append(ops, Delete)
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, Equal)
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// replace возвращает операции замены n строк на m строк целиком.
+func replace(n, m int) []Op {
+	ops := make([]Op, 0, n+m)
+	for i := 0; i < n; i++ {
+		ops = append(ops, Delete)
+	}
+	for i := 0; i < m; i++ {
+		ops = append(ops, Insert)
+	}
+	return ops
+}
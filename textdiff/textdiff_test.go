@@ -0,0 +1,107 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apply восстанавливает оба текста по сравнению.
+func apply(edits []Edit) ([]string, []string) {
+	var a, b []string
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			a = append(a, edit.Text)
+		}
+		if edit.Op != Delete {
+			b = append(b, edit.Text)
+		}
+	}
+	return a, b
+}
+
+// cost возвращает количество добавленных и удаленных строк.
+func cost(edits []Edit) int {
+	n := 0
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSplit(t *testing.T) {
+
+	// Проверяем метод
+	assert.Nil(t, Split(""))
+	assert.Equal(t, []string{"a", "", "b"}, Split("a\r\n\nb\n"))
+	assert.Equal(t, []string{""}, Split("\n"))
+}
+
+func TestLines(t *testing.T) {
+
+	// Создаем тестовые данные
+	a := Split("Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight")
+	b := Split("Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nYou set my soul alight\nGlaciers melting")
+
+	// Проверяем метод
+	edits := Lines(a, b)
+	assert.Equal(t, []Edit{
+		{Op: Equal, OldLine: 1, NewLine: 1, Text: "Ooh baby, don't you know I suffer?"},
+		{Op: Equal, OldLine: 2, NewLine: 2, Text: "Ooh baby, can you hear me moan?"},
+		{Op: Equal, OldLine: 3, NewLine: 3, Text: ""},
+		{Op: Delete, OldLine: 4, Text: "Ooh"},
+		{Op: Equal, OldLine: 5, NewLine: 4, Text: "You set my soul alight"},
+		{Op: Insert, NewLine: 5, Text: "Glaciers melting"},
+	}, edits)
+
+	assert.Empty(t, Lines(nil, nil))
+	assert.Equal(t, []Edit{{Op: Insert, NewLine: 1, Text: "a"}}, Lines(nil, []string{"a"}))
+	assert.Equal(t, []Edit{{Op: Delete, OldLine: 1, Text: "a"}, {Op: Insert, NewLine: 1, Text: "b"}}, Lines([]string{"a"}, []string{"b"}))
+}
+
+func TestLinesShortest(t *testing.T) {
+
+	// Создаем тестовые данные
+	cases := [][2]string{
+		{"abcabba", "cbabac"},
+		{"abcdef", "fedcba"},
+		{"aaaa", "aa"},
+		{"xaxbxc", "abc"},
+		{"", "abc"},
+	}
+	expected := []int{5, 10, 2, 3, 3}
+
+	// Проверяем метод
+	for i, c := range cases {
+		a, b := strings.Split(c[0], ""), strings.Split(c[1], "")
+		edits := Lines(a, b)
+		gotA, gotB := apply(edits)
+		assert.Equal(t, strings.Join(a, ""), strings.Join(gotA, ""), c)
+		assert.Equal(t, strings.Join(b, ""), strings.Join(gotB, ""), c)
+		assert.Equal(t, expected[i], cost(edits), c)
+	}
+}
+
+func TestLinesMaxCost(t *testing.T) {
+
+	// Создаем тестовые данные
+	var a, b []string
+	for i := 0; i < maxCost; i++ {
+		a = append(a, fmt.Sprintf("a%d", i))
+		b = append(b, fmt.Sprintf("b%d", i))
+	}
+	a = append([]string{"same"}, a...)
+	b = append([]string{"same"}, b...)
+
+	// Проверяем метод
+	edits := Lines(a, b)
+	gotA, gotB := apply(edits)
+	assert.Equal(t, a, gotA)
+	assert.Equal(t, b, gotB)
+	assert.Equal(t, Equal, edits[0].Op)
+	assert.Equal(t, 2*maxCost, cost(edits))
+}